@@ -0,0 +1,43 @@
+import { encodeAbiParameters } from "viem";
+import ArbitrumProof from "../src/abis/ArbitrumProof";
+import OPStackProof from "../src/abis/OPStackProof";
+import HashiProof from "../src/abis/HashiProof";
+
+// Encode the proofs in contracts/test/data the way `encodeProof` of the rewards monitor does and store the encodings
+// as fixtures for the go-filler proof round-trip tests
+const fixtures = [
+  { name: "ArbitrumSepoliaProof", abi: ArbitrumProof },
+  { name: "OPSepoliaProof", abi: OPStackProof },
+  { name: "HashiProverProof", abi: HashiProof },
+] as const;
+
+const outDir = "../services/go-filler/internal/prover/proofs/testdata";
+
+// The proofs are stored with their bigints as strings, see replaceBigInts
+function reviveBigInts(key: string, v: any): any {
+  if (key === "beaconOracleTimestamp") {
+    return BigInt(v);
+  }
+  if (key === "u64Vals") {
+    return v.map((x: string) => BigInt(x));
+  }
+  return v;
+}
+
+async function main() {
+  for (const { name, abi } of fixtures) {
+    const json = await Bun.file(`../contracts/test/data/${name}.json`).text();
+    const proof = JSON.parse(json, reviveBigInts);
+
+    await Bun.write(`${outDir}/${name}.json`, json);
+    await Bun.write(
+      `${outDir}/${name}.hex`,
+      encodeAbiParameters(abi as any, [proof]) + "\n"
+    );
+  }
+}
+
+main().catch((error) => {
+  console.error(error);
+  process.exitCode = 1;
+});
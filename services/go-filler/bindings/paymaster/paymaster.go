@@ -0,0 +1,1051 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package paymaster
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// PackedUserOperation is an auto generated low-level Go binding around an user-defined struct.
+type PackedUserOperation struct {
+	Sender             common.Address
+	Nonce              *big.Int
+	InitCode           []byte
+	CallData           []byte
+	AccountGasLimits   [32]byte
+	PreVerificationGas *big.Int
+	GasFees            [32]byte
+	PaymasterAndData   []byte
+	Signature          []byte
+}
+
+// PaymasterMetaData contains all meta data concerning the Paymaster contract.
+var PaymasterMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"constructor\",\"inputs\":[{\"name\":\"entryPoint\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"inbox\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"payable\"},{\"type\":\"receive\",\"stateMutability\":\"payable\"},{\"type\":\"function\",\"name\":\"ENTRY_POINT\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractIEntryPoint\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"INBOX\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractIInbox\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"entryPointDeposit\",\"inputs\":[{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"payable\"},{\"type\":\"function\",\"name\":\"entryPointWithdrawTo\",\"inputs\":[{\"name\":\"withdrawAddress\",\"type\":\"address\",\"internalType\":\"addresspayable\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"fulfillerClaimAddress\",\"inputs\":[{\"name\":\"fulfiller\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"claimAddress\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"fulfillerWithdraw\",\"inputs\":[{\"name\":\"fulfiller\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"token\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"getGasBalance\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMagicSpendBalance\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"token\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"magicSpendDeposit\",\"inputs\":[{\"name\":\"token\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"payable\"},{\"type\":\"function\",\"name\":\"postOp\",\"inputs\":[{\"name\":\"mode\",\"type\":\"uint8\",\"internalType\":\"enumIPaymaster.PostOpMode\"},{\"name\":\"context\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"setClaimAddress\",\"inputs\":[{\"name\":\"fulfillerClaimAddr\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"totalTrackedGasBalance\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"validatePaymasterUserOp\",\"inputs\":[{\"name\":\"userOp\",\"type\":\"tuple\",\"internalType\":\"structPackedUserOperation\",\"components\":[{\"name\":\"sender\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"nonce\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"initCode\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"callData\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"accountGasLimits\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"preVerificationGas\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"gasFees\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"paymasterAndData\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"signature\",\"type\":\"bytes\",\"internalType\":\"bytes\"}]},{\"name\":\"userOpHash\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"maxCost\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"context\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"validationData\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"withdrawGasExcess\",\"inputs\":[{\"name\":\"token\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"withdrawTo\",\"inputs\":[{\"name\":\"token\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"withdrawAddress\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"event\",\"name\":\"ClaimAddressSet\",\"inputs\":[{\"name\":\"fulfiller\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"claimAddress\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"GasWithdrawal\",\"inputs\":[{\"name\":\"caller\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"withdrawAddress\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"MagicSpendWithdrawal\",\"inputs\":[{\"name\":\"caller\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"withdrawAddress\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"InsufficientGasBalance\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"balance\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"InsufficientMagicSpendBalance\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"balance\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"InvalidCaller\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidValue\",\"inputs\":[{\"name\":\"expected\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"actual\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"NotEntryPoint\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"ZeroAddress\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"ZeroAmount\",\"inputs\":[]}]",
+}
+
+// PaymasterABI is the input ABI used to generate the binding from.
+// Deprecated: Use PaymasterMetaData.ABI instead.
+var PaymasterABI = PaymasterMetaData.ABI
+
+// Paymaster is an auto generated Go binding around an Ethereum contract.
+type Paymaster struct {
+	PaymasterCaller     // Read-only binding to the contract
+	PaymasterTransactor // Write-only binding to the contract
+	PaymasterFilterer   // Log filterer for contract events
+}
+
+// PaymasterCaller is an auto generated read-only Go binding around an Ethereum contract.
+type PaymasterCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PaymasterTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type PaymasterTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PaymasterFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type PaymasterFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PaymasterSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type PaymasterSession struct {
+	Contract     *Paymaster        // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// PaymasterCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type PaymasterCallerSession struct {
+	Contract *PaymasterCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts    // Call options to use throughout this session
+}
+
+// PaymasterTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type PaymasterTransactorSession struct {
+	Contract     *PaymasterTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
+}
+
+// PaymasterRaw is an auto generated low-level Go binding around an Ethereum contract.
+type PaymasterRaw struct {
+	Contract *Paymaster // Generic contract binding to access the raw methods on
+}
+
+// PaymasterCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type PaymasterCallerRaw struct {
+	Contract *PaymasterCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// PaymasterTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type PaymasterTransactorRaw struct {
+	Contract *PaymasterTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewPaymaster creates a new instance of Paymaster, bound to a specific deployed contract.
+func NewPaymaster(address common.Address, backend bind.ContractBackend) (*Paymaster, error) {
+	contract, err := bindPaymaster(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Paymaster{PaymasterCaller: PaymasterCaller{contract: contract}, PaymasterTransactor: PaymasterTransactor{contract: contract}, PaymasterFilterer: PaymasterFilterer{contract: contract}}, nil
+}
+
+// NewPaymasterCaller creates a new read-only instance of Paymaster, bound to a specific deployed contract.
+func NewPaymasterCaller(address common.Address, caller bind.ContractCaller) (*PaymasterCaller, error) {
+	contract, err := bindPaymaster(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymasterCaller{contract: contract}, nil
+}
+
+// NewPaymasterTransactor creates a new write-only instance of Paymaster, bound to a specific deployed contract.
+func NewPaymasterTransactor(address common.Address, transactor bind.ContractTransactor) (*PaymasterTransactor, error) {
+	contract, err := bindPaymaster(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymasterTransactor{contract: contract}, nil
+}
+
+// NewPaymasterFilterer creates a new log filterer instance of Paymaster, bound to a specific deployed contract.
+func NewPaymasterFilterer(address common.Address, filterer bind.ContractFilterer) (*PaymasterFilterer, error) {
+	contract, err := bindPaymaster(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymasterFilterer{contract: contract}, nil
+}
+
+// bindPaymaster binds a generic wrapper to an already deployed contract.
+func bindPaymaster(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := PaymasterMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Paymaster *PaymasterRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Paymaster.Contract.PaymasterCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Paymaster *PaymasterRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Paymaster.Contract.PaymasterTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Paymaster *PaymasterRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Paymaster.Contract.PaymasterTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Paymaster *PaymasterCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Paymaster.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Paymaster *PaymasterTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Paymaster.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Paymaster *PaymasterTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Paymaster.Contract.contract.Transact(opts, method, params...)
+}
+
+// ENTRYPOINT is a free data retrieval call binding the contract method 0x94430fa5.
+//
+// Solidity: function ENTRY_POINT() view returns(address)
+func (_Paymaster *PaymasterCaller) ENTRYPOINT(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Paymaster.contract.Call(opts, &out, "ENTRY_POINT")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// ENTRYPOINT is a free data retrieval call binding the contract method 0x94430fa5.
+//
+// Solidity: function ENTRY_POINT() view returns(address)
+func (_Paymaster *PaymasterSession) ENTRYPOINT() (common.Address, error) {
+	return _Paymaster.Contract.ENTRYPOINT(&_Paymaster.CallOpts)
+}
+
+// ENTRYPOINT is a free data retrieval call binding the contract method 0x94430fa5.
+//
+// Solidity: function ENTRY_POINT() view returns(address)
+func (_Paymaster *PaymasterCallerSession) ENTRYPOINT() (common.Address, error) {
+	return _Paymaster.Contract.ENTRYPOINT(&_Paymaster.CallOpts)
+}
+
+// INBOX is a free data retrieval call binding the contract method 0xb7010697.
+//
+// Solidity: function INBOX() view returns(address)
+func (_Paymaster *PaymasterCaller) INBOX(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Paymaster.contract.Call(opts, &out, "INBOX")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// INBOX is a free data retrieval call binding the contract method 0xb7010697.
+//
+// Solidity: function INBOX() view returns(address)
+func (_Paymaster *PaymasterSession) INBOX() (common.Address, error) {
+	return _Paymaster.Contract.INBOX(&_Paymaster.CallOpts)
+}
+
+// INBOX is a free data retrieval call binding the contract method 0xb7010697.
+//
+// Solidity: function INBOX() view returns(address)
+func (_Paymaster *PaymasterCallerSession) INBOX() (common.Address, error) {
+	return _Paymaster.Contract.INBOX(&_Paymaster.CallOpts)
+}
+
+// FulfillerClaimAddress is a free data retrieval call binding the contract method 0xe8b09e53.
+//
+// Solidity: function fulfillerClaimAddress(address fulfiller) view returns(address claimAddress)
+func (_Paymaster *PaymasterCaller) FulfillerClaimAddress(opts *bind.CallOpts, fulfiller common.Address) (common.Address, error) {
+	var out []interface{}
+	err := _Paymaster.contract.Call(opts, &out, "fulfillerClaimAddress", fulfiller)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// FulfillerClaimAddress is a free data retrieval call binding the contract method 0xe8b09e53.
+//
+// Solidity: function fulfillerClaimAddress(address fulfiller) view returns(address claimAddress)
+func (_Paymaster *PaymasterSession) FulfillerClaimAddress(fulfiller common.Address) (common.Address, error) {
+	return _Paymaster.Contract.FulfillerClaimAddress(&_Paymaster.CallOpts, fulfiller)
+}
+
+// FulfillerClaimAddress is a free data retrieval call binding the contract method 0xe8b09e53.
+//
+// Solidity: function fulfillerClaimAddress(address fulfiller) view returns(address claimAddress)
+func (_Paymaster *PaymasterCallerSession) FulfillerClaimAddress(fulfiller common.Address) (common.Address, error) {
+	return _Paymaster.Contract.FulfillerClaimAddress(&_Paymaster.CallOpts, fulfiller)
+}
+
+// GetGasBalance is a free data retrieval call binding the contract method 0xdb51cd66.
+//
+// Solidity: function getGasBalance(address account) view returns(uint256)
+func (_Paymaster *PaymasterCaller) GetGasBalance(opts *bind.CallOpts, account common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Paymaster.contract.Call(opts, &out, "getGasBalance", account)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetGasBalance is a free data retrieval call binding the contract method 0xdb51cd66.
+//
+// Solidity: function getGasBalance(address account) view returns(uint256)
+func (_Paymaster *PaymasterSession) GetGasBalance(account common.Address) (*big.Int, error) {
+	return _Paymaster.Contract.GetGasBalance(&_Paymaster.CallOpts, account)
+}
+
+// GetGasBalance is a free data retrieval call binding the contract method 0xdb51cd66.
+//
+// Solidity: function getGasBalance(address account) view returns(uint256)
+func (_Paymaster *PaymasterCallerSession) GetGasBalance(account common.Address) (*big.Int, error) {
+	return _Paymaster.Contract.GetGasBalance(&_Paymaster.CallOpts, account)
+}
+
+// GetMagicSpendBalance is a free data retrieval call binding the contract method 0x1a846e78.
+//
+// Solidity: function getMagicSpendBalance(address account, address token) view returns(uint256)
+func (_Paymaster *PaymasterCaller) GetMagicSpendBalance(opts *bind.CallOpts, account common.Address, token common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Paymaster.contract.Call(opts, &out, "getMagicSpendBalance", account, token)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetMagicSpendBalance is a free data retrieval call binding the contract method 0x1a846e78.
+//
+// Solidity: function getMagicSpendBalance(address account, address token) view returns(uint256)
+func (_Paymaster *PaymasterSession) GetMagicSpendBalance(account common.Address, token common.Address) (*big.Int, error) {
+	return _Paymaster.Contract.GetMagicSpendBalance(&_Paymaster.CallOpts, account, token)
+}
+
+// GetMagicSpendBalance is a free data retrieval call binding the contract method 0x1a846e78.
+//
+// Solidity: function getMagicSpendBalance(address account, address token) view returns(uint256)
+func (_Paymaster *PaymasterCallerSession) GetMagicSpendBalance(account common.Address, token common.Address) (*big.Int, error) {
+	return _Paymaster.Contract.GetMagicSpendBalance(&_Paymaster.CallOpts, account, token)
+}
+
+// TotalTrackedGasBalance is a free data retrieval call binding the contract method 0xcb646ee6.
+//
+// Solidity: function totalTrackedGasBalance() view returns(uint256)
+func (_Paymaster *PaymasterCaller) TotalTrackedGasBalance(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Paymaster.contract.Call(opts, &out, "totalTrackedGasBalance")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalTrackedGasBalance is a free data retrieval call binding the contract method 0xcb646ee6.
+//
+// Solidity: function totalTrackedGasBalance() view returns(uint256)
+func (_Paymaster *PaymasterSession) TotalTrackedGasBalance() (*big.Int, error) {
+	return _Paymaster.Contract.TotalTrackedGasBalance(&_Paymaster.CallOpts)
+}
+
+// TotalTrackedGasBalance is a free data retrieval call binding the contract method 0xcb646ee6.
+//
+// Solidity: function totalTrackedGasBalance() view returns(uint256)
+func (_Paymaster *PaymasterCallerSession) TotalTrackedGasBalance() (*big.Int, error) {
+	return _Paymaster.Contract.TotalTrackedGasBalance(&_Paymaster.CallOpts)
+}
+
+// EntryPointDeposit is a paid mutator transaction binding the contract method 0x7c8d4949.
+//
+// Solidity: function entryPointDeposit(uint256 amount) payable returns()
+func (_Paymaster *PaymasterTransactor) EntryPointDeposit(opts *bind.TransactOpts, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "entryPointDeposit", amount)
+}
+
+// EntryPointDeposit is a paid mutator transaction binding the contract method 0x7c8d4949.
+//
+// Solidity: function entryPointDeposit(uint256 amount) payable returns()
+func (_Paymaster *PaymasterSession) EntryPointDeposit(amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.EntryPointDeposit(&_Paymaster.TransactOpts, amount)
+}
+
+// EntryPointDeposit is a paid mutator transaction binding the contract method 0x7c8d4949.
+//
+// Solidity: function entryPointDeposit(uint256 amount) payable returns()
+func (_Paymaster *PaymasterTransactorSession) EntryPointDeposit(amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.EntryPointDeposit(&_Paymaster.TransactOpts, amount)
+}
+
+// EntryPointWithdrawTo is a paid mutator transaction binding the contract method 0x6e36f368.
+//
+// Solidity: function entryPointWithdrawTo(address withdrawAddress, uint256 amount) returns()
+func (_Paymaster *PaymasterTransactor) EntryPointWithdrawTo(opts *bind.TransactOpts, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "entryPointWithdrawTo", withdrawAddress, amount)
+}
+
+// EntryPointWithdrawTo is a paid mutator transaction binding the contract method 0x6e36f368.
+//
+// Solidity: function entryPointWithdrawTo(address withdrawAddress, uint256 amount) returns()
+func (_Paymaster *PaymasterSession) EntryPointWithdrawTo(withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.EntryPointWithdrawTo(&_Paymaster.TransactOpts, withdrawAddress, amount)
+}
+
+// EntryPointWithdrawTo is a paid mutator transaction binding the contract method 0x6e36f368.
+//
+// Solidity: function entryPointWithdrawTo(address withdrawAddress, uint256 amount) returns()
+func (_Paymaster *PaymasterTransactorSession) EntryPointWithdrawTo(withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.EntryPointWithdrawTo(&_Paymaster.TransactOpts, withdrawAddress, amount)
+}
+
+// FulfillerWithdraw is a paid mutator transaction binding the contract method 0x6a7c383f.
+//
+// Solidity: function fulfillerWithdraw(address fulfiller, address token, uint256 amount) returns()
+func (_Paymaster *PaymasterTransactor) FulfillerWithdraw(opts *bind.TransactOpts, fulfiller common.Address, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "fulfillerWithdraw", fulfiller, token, amount)
+}
+
+// FulfillerWithdraw is a paid mutator transaction binding the contract method 0x6a7c383f.
+//
+// Solidity: function fulfillerWithdraw(address fulfiller, address token, uint256 amount) returns()
+func (_Paymaster *PaymasterSession) FulfillerWithdraw(fulfiller common.Address, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.FulfillerWithdraw(&_Paymaster.TransactOpts, fulfiller, token, amount)
+}
+
+// FulfillerWithdraw is a paid mutator transaction binding the contract method 0x6a7c383f.
+//
+// Solidity: function fulfillerWithdraw(address fulfiller, address token, uint256 amount) returns()
+func (_Paymaster *PaymasterTransactorSession) FulfillerWithdraw(fulfiller common.Address, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.FulfillerWithdraw(&_Paymaster.TransactOpts, fulfiller, token, amount)
+}
+
+// MagicSpendDeposit is a paid mutator transaction binding the contract method 0xeb4ebc60.
+//
+// Solidity: function magicSpendDeposit(address token, uint256 amount) payable returns()
+func (_Paymaster *PaymasterTransactor) MagicSpendDeposit(opts *bind.TransactOpts, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "magicSpendDeposit", token, amount)
+}
+
+// MagicSpendDeposit is a paid mutator transaction binding the contract method 0xeb4ebc60.
+//
+// Solidity: function magicSpendDeposit(address token, uint256 amount) payable returns()
+func (_Paymaster *PaymasterSession) MagicSpendDeposit(token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.MagicSpendDeposit(&_Paymaster.TransactOpts, token, amount)
+}
+
+// MagicSpendDeposit is a paid mutator transaction binding the contract method 0xeb4ebc60.
+//
+// Solidity: function magicSpendDeposit(address token, uint256 amount) payable returns()
+func (_Paymaster *PaymasterTransactorSession) MagicSpendDeposit(token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.MagicSpendDeposit(&_Paymaster.TransactOpts, token, amount)
+}
+
+// PostOp is a paid mutator transaction binding the contract method 0x7c627b21.
+//
+// Solidity: function postOp(uint8 mode, bytes context, uint256 , uint256 ) returns()
+func (_Paymaster *PaymasterTransactor) PostOp(opts *bind.TransactOpts, mode uint8, context []byte, arg2 *big.Int, arg3 *big.Int) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "postOp", mode, context, arg2, arg3)
+}
+
+// PostOp is a paid mutator transaction binding the contract method 0x7c627b21.
+//
+// Solidity: function postOp(uint8 mode, bytes context, uint256 , uint256 ) returns()
+func (_Paymaster *PaymasterSession) PostOp(mode uint8, context []byte, arg2 *big.Int, arg3 *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.PostOp(&_Paymaster.TransactOpts, mode, context, arg2, arg3)
+}
+
+// PostOp is a paid mutator transaction binding the contract method 0x7c627b21.
+//
+// Solidity: function postOp(uint8 mode, bytes context, uint256 , uint256 ) returns()
+func (_Paymaster *PaymasterTransactorSession) PostOp(mode uint8, context []byte, arg2 *big.Int, arg3 *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.PostOp(&_Paymaster.TransactOpts, mode, context, arg2, arg3)
+}
+
+// SetClaimAddress is a paid mutator transaction binding the contract method 0xbb379087.
+//
+// Solidity: function setClaimAddress(address fulfillerClaimAddr) returns()
+func (_Paymaster *PaymasterTransactor) SetClaimAddress(opts *bind.TransactOpts, fulfillerClaimAddr common.Address) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "setClaimAddress", fulfillerClaimAddr)
+}
+
+// SetClaimAddress is a paid mutator transaction binding the contract method 0xbb379087.
+//
+// Solidity: function setClaimAddress(address fulfillerClaimAddr) returns()
+func (_Paymaster *PaymasterSession) SetClaimAddress(fulfillerClaimAddr common.Address) (*types.Transaction, error) {
+	return _Paymaster.Contract.SetClaimAddress(&_Paymaster.TransactOpts, fulfillerClaimAddr)
+}
+
+// SetClaimAddress is a paid mutator transaction binding the contract method 0xbb379087.
+//
+// Solidity: function setClaimAddress(address fulfillerClaimAddr) returns()
+func (_Paymaster *PaymasterTransactorSession) SetClaimAddress(fulfillerClaimAddr common.Address) (*types.Transaction, error) {
+	return _Paymaster.Contract.SetClaimAddress(&_Paymaster.TransactOpts, fulfillerClaimAddr)
+}
+
+// ValidatePaymasterUserOp is a paid mutator transaction binding the contract method 0x52b7512c.
+//
+// Solidity: function validatePaymasterUserOp((address,uint256,bytes,bytes,bytes32,uint256,bytes32,bytes,bytes) userOp, bytes32 userOpHash, uint256 maxCost) returns(bytes context, uint256 validationData)
+func (_Paymaster *PaymasterTransactor) ValidatePaymasterUserOp(opts *bind.TransactOpts, userOp PackedUserOperation, userOpHash [32]byte, maxCost *big.Int) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "validatePaymasterUserOp", userOp, userOpHash, maxCost)
+}
+
+// ValidatePaymasterUserOp is a paid mutator transaction binding the contract method 0x52b7512c.
+//
+// Solidity: function validatePaymasterUserOp((address,uint256,bytes,bytes,bytes32,uint256,bytes32,bytes,bytes) userOp, bytes32 userOpHash, uint256 maxCost) returns(bytes context, uint256 validationData)
+func (_Paymaster *PaymasterSession) ValidatePaymasterUserOp(userOp PackedUserOperation, userOpHash [32]byte, maxCost *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.ValidatePaymasterUserOp(&_Paymaster.TransactOpts, userOp, userOpHash, maxCost)
+}
+
+// ValidatePaymasterUserOp is a paid mutator transaction binding the contract method 0x52b7512c.
+//
+// Solidity: function validatePaymasterUserOp((address,uint256,bytes,bytes,bytes32,uint256,bytes32,bytes,bytes) userOp, bytes32 userOpHash, uint256 maxCost) returns(bytes context, uint256 validationData)
+func (_Paymaster *PaymasterTransactorSession) ValidatePaymasterUserOp(userOp PackedUserOperation, userOpHash [32]byte, maxCost *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.ValidatePaymasterUserOp(&_Paymaster.TransactOpts, userOp, userOpHash, maxCost)
+}
+
+// WithdrawGasExcess is a paid mutator transaction binding the contract method 0xcdf8f31a.
+//
+// Solidity: function withdrawGasExcess(bytes32 token) returns()
+func (_Paymaster *PaymasterTransactor) WithdrawGasExcess(opts *bind.TransactOpts, token [32]byte) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "withdrawGasExcess", token)
+}
+
+// WithdrawGasExcess is a paid mutator transaction binding the contract method 0xcdf8f31a.
+//
+// Solidity: function withdrawGasExcess(bytes32 token) returns()
+func (_Paymaster *PaymasterSession) WithdrawGasExcess(token [32]byte) (*types.Transaction, error) {
+	return _Paymaster.Contract.WithdrawGasExcess(&_Paymaster.TransactOpts, token)
+}
+
+// WithdrawGasExcess is a paid mutator transaction binding the contract method 0xcdf8f31a.
+//
+// Solidity: function withdrawGasExcess(bytes32 token) returns()
+func (_Paymaster *PaymasterTransactorSession) WithdrawGasExcess(token [32]byte) (*types.Transaction, error) {
+	return _Paymaster.Contract.WithdrawGasExcess(&_Paymaster.TransactOpts, token)
+}
+
+// WithdrawTo is a paid mutator transaction binding the contract method 0xc3b35a7e.
+//
+// Solidity: function withdrawTo(address token, address withdrawAddress, uint256 amount) returns()
+func (_Paymaster *PaymasterTransactor) WithdrawTo(opts *bind.TransactOpts, token common.Address, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.contract.Transact(opts, "withdrawTo", token, withdrawAddress, amount)
+}
+
+// WithdrawTo is a paid mutator transaction binding the contract method 0xc3b35a7e.
+//
+// Solidity: function withdrawTo(address token, address withdrawAddress, uint256 amount) returns()
+func (_Paymaster *PaymasterSession) WithdrawTo(token common.Address, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.WithdrawTo(&_Paymaster.TransactOpts, token, withdrawAddress, amount)
+}
+
+// WithdrawTo is a paid mutator transaction binding the contract method 0xc3b35a7e.
+//
+// Solidity: function withdrawTo(address token, address withdrawAddress, uint256 amount) returns()
+func (_Paymaster *PaymasterTransactorSession) WithdrawTo(token common.Address, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Paymaster.Contract.WithdrawTo(&_Paymaster.TransactOpts, token, withdrawAddress, amount)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Paymaster *PaymasterTransactor) Receive(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Paymaster.contract.RawTransact(opts, nil) // calldata is disallowed for receive function
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Paymaster *PaymasterSession) Receive() (*types.Transaction, error) {
+	return _Paymaster.Contract.Receive(&_Paymaster.TransactOpts)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Paymaster *PaymasterTransactorSession) Receive() (*types.Transaction, error) {
+	return _Paymaster.Contract.Receive(&_Paymaster.TransactOpts)
+}
+
+// PaymasterClaimAddressSetIterator is returned from FilterClaimAddressSet and is used to iterate over the raw logs and unpacked data for ClaimAddressSet events raised by the Paymaster contract.
+type PaymasterClaimAddressSetIterator struct {
+	Event *PaymasterClaimAddressSet // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PaymasterClaimAddressSetIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PaymasterClaimAddressSet)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PaymasterClaimAddressSet)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PaymasterClaimAddressSetIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PaymasterClaimAddressSetIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PaymasterClaimAddressSet represents a ClaimAddressSet event raised by the Paymaster contract.
+type PaymasterClaimAddressSet struct {
+	Fulfiller    common.Address
+	ClaimAddress common.Address
+	Raw          types.Log // Blockchain specific contextual infos
+}
+
+// FilterClaimAddressSet is a free log retrieval operation binding the contract event 0x081105fa020679a8cf8b1b76c359f7cb5edb62adfe98c76d45ea8e823789f410.
+//
+// Solidity: event ClaimAddressSet(address indexed fulfiller, address indexed claimAddress)
+func (_Paymaster *PaymasterFilterer) FilterClaimAddressSet(opts *bind.FilterOpts, fulfiller []common.Address, claimAddress []common.Address) (*PaymasterClaimAddressSetIterator, error) {
+
+	var fulfillerRule []interface{}
+	for _, fulfillerItem := range fulfiller {
+		fulfillerRule = append(fulfillerRule, fulfillerItem)
+	}
+	var claimAddressRule []interface{}
+	for _, claimAddressItem := range claimAddress {
+		claimAddressRule = append(claimAddressRule, claimAddressItem)
+	}
+
+	logs, sub, err := _Paymaster.contract.FilterLogs(opts, "ClaimAddressSet", fulfillerRule, claimAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymasterClaimAddressSetIterator{contract: _Paymaster.contract, event: "ClaimAddressSet", logs: logs, sub: sub}, nil
+}
+
+// WatchClaimAddressSet is a free log subscription operation binding the contract event 0x081105fa020679a8cf8b1b76c359f7cb5edb62adfe98c76d45ea8e823789f410.
+//
+// Solidity: event ClaimAddressSet(address indexed fulfiller, address indexed claimAddress)
+func (_Paymaster *PaymasterFilterer) WatchClaimAddressSet(opts *bind.WatchOpts, sink chan<- *PaymasterClaimAddressSet, fulfiller []common.Address, claimAddress []common.Address) (event.Subscription, error) {
+
+	var fulfillerRule []interface{}
+	for _, fulfillerItem := range fulfiller {
+		fulfillerRule = append(fulfillerRule, fulfillerItem)
+	}
+	var claimAddressRule []interface{}
+	for _, claimAddressItem := range claimAddress {
+		claimAddressRule = append(claimAddressRule, claimAddressItem)
+	}
+
+	logs, sub, err := _Paymaster.contract.WatchLogs(opts, "ClaimAddressSet", fulfillerRule, claimAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PaymasterClaimAddressSet)
+				if err := _Paymaster.contract.UnpackLog(event, "ClaimAddressSet", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseClaimAddressSet is a log parse operation binding the contract event 0x081105fa020679a8cf8b1b76c359f7cb5edb62adfe98c76d45ea8e823789f410.
+//
+// Solidity: event ClaimAddressSet(address indexed fulfiller, address indexed claimAddress)
+func (_Paymaster *PaymasterFilterer) ParseClaimAddressSet(log types.Log) (*PaymasterClaimAddressSet, error) {
+	event := new(PaymasterClaimAddressSet)
+	if err := _Paymaster.contract.UnpackLog(event, "ClaimAddressSet", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// PaymasterGasWithdrawalIterator is returned from FilterGasWithdrawal and is used to iterate over the raw logs and unpacked data for GasWithdrawal events raised by the Paymaster contract.
+type PaymasterGasWithdrawalIterator struct {
+	Event *PaymasterGasWithdrawal // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PaymasterGasWithdrawalIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PaymasterGasWithdrawal)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PaymasterGasWithdrawal)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PaymasterGasWithdrawalIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PaymasterGasWithdrawalIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PaymasterGasWithdrawal represents a GasWithdrawal event raised by the Paymaster contract.
+type PaymasterGasWithdrawal struct {
+	Caller          common.Address
+	WithdrawAddress common.Address
+	Amount          *big.Int
+	Raw             types.Log // Blockchain specific contextual infos
+}
+
+// FilterGasWithdrawal is a free log retrieval operation binding the contract event 0x799c4070425e5801f4dd16708fc14085c7c0d9cbf60b84bb7e63cf62ff668655.
+//
+// Solidity: event GasWithdrawal(address indexed caller, address indexed withdrawAddress, uint256 amount)
+func (_Paymaster *PaymasterFilterer) FilterGasWithdrawal(opts *bind.FilterOpts, caller []common.Address, withdrawAddress []common.Address) (*PaymasterGasWithdrawalIterator, error) {
+
+	var callerRule []interface{}
+	for _, callerItem := range caller {
+		callerRule = append(callerRule, callerItem)
+	}
+	var withdrawAddressRule []interface{}
+	for _, withdrawAddressItem := range withdrawAddress {
+		withdrawAddressRule = append(withdrawAddressRule, withdrawAddressItem)
+	}
+
+	logs, sub, err := _Paymaster.contract.FilterLogs(opts, "GasWithdrawal", callerRule, withdrawAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymasterGasWithdrawalIterator{contract: _Paymaster.contract, event: "GasWithdrawal", logs: logs, sub: sub}, nil
+}
+
+// WatchGasWithdrawal is a free log subscription operation binding the contract event 0x799c4070425e5801f4dd16708fc14085c7c0d9cbf60b84bb7e63cf62ff668655.
+//
+// Solidity: event GasWithdrawal(address indexed caller, address indexed withdrawAddress, uint256 amount)
+func (_Paymaster *PaymasterFilterer) WatchGasWithdrawal(opts *bind.WatchOpts, sink chan<- *PaymasterGasWithdrawal, caller []common.Address, withdrawAddress []common.Address) (event.Subscription, error) {
+
+	var callerRule []interface{}
+	for _, callerItem := range caller {
+		callerRule = append(callerRule, callerItem)
+	}
+	var withdrawAddressRule []interface{}
+	for _, withdrawAddressItem := range withdrawAddress {
+		withdrawAddressRule = append(withdrawAddressRule, withdrawAddressItem)
+	}
+
+	logs, sub, err := _Paymaster.contract.WatchLogs(opts, "GasWithdrawal", callerRule, withdrawAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PaymasterGasWithdrawal)
+				if err := _Paymaster.contract.UnpackLog(event, "GasWithdrawal", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseGasWithdrawal is a log parse operation binding the contract event 0x799c4070425e5801f4dd16708fc14085c7c0d9cbf60b84bb7e63cf62ff668655.
+//
+// Solidity: event GasWithdrawal(address indexed caller, address indexed withdrawAddress, uint256 amount)
+func (_Paymaster *PaymasterFilterer) ParseGasWithdrawal(log types.Log) (*PaymasterGasWithdrawal, error) {
+	event := new(PaymasterGasWithdrawal)
+	if err := _Paymaster.contract.UnpackLog(event, "GasWithdrawal", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// PaymasterMagicSpendWithdrawalIterator is returned from FilterMagicSpendWithdrawal and is used to iterate over the raw logs and unpacked data for MagicSpendWithdrawal events raised by the Paymaster contract.
+type PaymasterMagicSpendWithdrawalIterator struct {
+	Event *PaymasterMagicSpendWithdrawal // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PaymasterMagicSpendWithdrawalIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PaymasterMagicSpendWithdrawal)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PaymasterMagicSpendWithdrawal)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PaymasterMagicSpendWithdrawalIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PaymasterMagicSpendWithdrawalIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PaymasterMagicSpendWithdrawal represents a MagicSpendWithdrawal event raised by the Paymaster contract.
+type PaymasterMagicSpendWithdrawal struct {
+	Caller          common.Address
+	WithdrawAddress common.Address
+	Amount          *big.Int
+	Raw             types.Log // Blockchain specific contextual infos
+}
+
+// FilterMagicSpendWithdrawal is a free log retrieval operation binding the contract event 0x2a26f34d9a8eec01caae740f85c9562df1f155161d77dfdcdd5e07237052cfa8.
+//
+// Solidity: event MagicSpendWithdrawal(address indexed caller, address indexed withdrawAddress, uint256 amount)
+func (_Paymaster *PaymasterFilterer) FilterMagicSpendWithdrawal(opts *bind.FilterOpts, caller []common.Address, withdrawAddress []common.Address) (*PaymasterMagicSpendWithdrawalIterator, error) {
+
+	var callerRule []interface{}
+	for _, callerItem := range caller {
+		callerRule = append(callerRule, callerItem)
+	}
+	var withdrawAddressRule []interface{}
+	for _, withdrawAddressItem := range withdrawAddress {
+		withdrawAddressRule = append(withdrawAddressRule, withdrawAddressItem)
+	}
+
+	logs, sub, err := _Paymaster.contract.FilterLogs(opts, "MagicSpendWithdrawal", callerRule, withdrawAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymasterMagicSpendWithdrawalIterator{contract: _Paymaster.contract, event: "MagicSpendWithdrawal", logs: logs, sub: sub}, nil
+}
+
+// WatchMagicSpendWithdrawal is a free log subscription operation binding the contract event 0x2a26f34d9a8eec01caae740f85c9562df1f155161d77dfdcdd5e07237052cfa8.
+//
+// Solidity: event MagicSpendWithdrawal(address indexed caller, address indexed withdrawAddress, uint256 amount)
+func (_Paymaster *PaymasterFilterer) WatchMagicSpendWithdrawal(opts *bind.WatchOpts, sink chan<- *PaymasterMagicSpendWithdrawal, caller []common.Address, withdrawAddress []common.Address) (event.Subscription, error) {
+
+	var callerRule []interface{}
+	for _, callerItem := range caller {
+		callerRule = append(callerRule, callerItem)
+	}
+	var withdrawAddressRule []interface{}
+	for _, withdrawAddressItem := range withdrawAddress {
+		withdrawAddressRule = append(withdrawAddressRule, withdrawAddressItem)
+	}
+
+	logs, sub, err := _Paymaster.contract.WatchLogs(opts, "MagicSpendWithdrawal", callerRule, withdrawAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PaymasterMagicSpendWithdrawal)
+				if err := _Paymaster.contract.UnpackLog(event, "MagicSpendWithdrawal", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseMagicSpendWithdrawal is a log parse operation binding the contract event 0x2a26f34d9a8eec01caae740f85c9562df1f155161d77dfdcdd5e07237052cfa8.
+//
+// Solidity: event MagicSpendWithdrawal(address indexed caller, address indexed withdrawAddress, uint256 amount)
+func (_Paymaster *PaymasterFilterer) ParseMagicSpendWithdrawal(log types.Log) (*PaymasterMagicSpendWithdrawal, error) {
+	event := new(PaymasterMagicSpendWithdrawal)
+	if err := _Paymaster.contract.UnpackLog(event, "MagicSpendWithdrawal", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/listener"
+	"github.com/spf13/cobra"
+)
+
+func newBackfillCmd(flags *rootFlags) *cobra.Command {
+	var (
+		chainID uint64
+		from    uint64
+		to      uint64
+		shadow  = &shadowFlags{}
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Replay historical MessagePosted events of a source chain",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log, cfg, err := flags.setup()
+			if err != nil {
+				return fmt.Errorf("creating config: %w", err)
+			}
+			shadow.apply(cmd, cfg)
+
+			ctx := cmd.Context()
+
+			clientMgr, err := client.NewManager(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("initializing client manager: %w", err)
+			}
+
+			outboxListener, err := listener.NewOutboxListener(ctx, clientMgr, cfg, log)
+			if err != nil {
+				return fmt.Errorf("initializing outbox listener: %w", err)
+			}
+			defer outboxListener.Close()
+
+			return outboxListener.Backfill(ctx, chainID, from, to)
+		},
+	}
+
+	addChainFlag(cmd, &chainID, "source-chain", "source chain ID to replay")
+	cmd.Flags().Uint64Var(&from, "from", 0, "first block to replay")
+	cmd.Flags().Uint64Var(&to, "to", 0, "last block to replay (inclusive)")
+	addShadowFlags(cmd, shadow)
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
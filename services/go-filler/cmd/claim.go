@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/listener"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proof_verifier"
+	"github.com/base-org/RRC-7755-poc/internal/prover/registry"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newClaimCmd(flags *rootFlags) *cobra.Command {
+	var (
+		messageID string
+		chainID   uint64
+		fromBlock uint64
+		toBlock   uint64
+		proofHex  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "claim",
+		Short: "Submit the reward claim of a single fulfilled message",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log, cfg, err := flags.setup()
+			if err != nil {
+				return fmt.Errorf("creating config: %w", err)
+			}
+
+			ctx := cmd.Context()
+
+			clientMgr, err := client.NewManager(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("initializing client manager: %w", err)
+			}
+
+			sourceChain, err := clientMgr.GetChainClient(chainID)
+			if err != nil {
+				return err
+			}
+
+			filterOpts := &bind.FilterOpts{Start: fromBlock, Context: ctx}
+			if toBlock != 0 {
+				filterOpts.End = &toBlock
+			}
+
+			events, err := listener.FilterMessagePosted(ctx, sourceChain, filterOpts, [][32]byte{common.HexToHash(messageID)})
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				return fmt.Errorf("message %s not found on chain %d", messageID, chainID)
+			}
+			event := events[0]
+
+			outboxListener, err := listener.NewOutboxListener(ctx, clientMgr, cfg, log)
+			if err != nil {
+				return fmt.Errorf("initializing outbox listener: %w", err)
+			}
+
+			parsed, err := outboxListener.ValidateMessagePosted(ctx, sourceChain, event)
+			if err != nil {
+				return fmt.Errorf("validating message: %w", err)
+			}
+
+			var proof []byte
+			if proofHex != "" {
+				proof, err = hexutil.Decode(proofHex)
+				if err != nil {
+					return fmt.Errorf("decoding proof: %w", err)
+				}
+			} else {
+				proof, err = generateProof(ctx, log, cfg, clientMgr, parsed.DestinationChain, event.MessageId)
+				if err != nil {
+					return err
+				}
+			}
+
+			privateKey, err := cfg.Wallets.GetPrivateKey()
+			if err != nil {
+				return err
+			}
+
+			opts, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(chainID))
+			if err != nil {
+				return fmt.Errorf("creating transactor: %w", err)
+			}
+			opts.Context = ctx
+
+			claim, err := newClaim(cfg, chainID, event, parsed, opts.From, proof)
+			if err != nil {
+				return err
+			}
+			// A proof the outbox rejects would only burn the gas of the claim transaction
+			fulfillment, err := proof_verifier.NewVerifier(sourceChain.Client).VerifyClaim(ctx, *claim)
+			if err != nil {
+				return fmt.Errorf("verifying proof: %w", err)
+			}
+			log.Info("Proof verified",
+				zap.String("message_id", messageID),
+				zap.String("prover_type", claim.ProverType),
+				zap.String("fulfiller", fulfillment.Fulfiller.Hex()),
+				zap.Stringer("fulfilled_at", fulfillment.Timestamp),
+			)
+
+			outbox, err := rrc_7755_outbox.NewRRC7755OutboxTransactor(event.Raw.Address, sourceChain.Client)
+			if err != nil {
+				return fmt.Errorf("creating outbox contract on chain %d: %w", chainID, err)
+			}
+
+			var tx *types.Transaction
+			if parsed.ParsedUserOp == nil {
+				tx, err = outbox.ClaimReward(
+					opts,
+					event.DestinationChain,
+					event.Receiver,
+					event.Payload,
+					event.Attributes,
+					proof,
+					cfg.Wallets.GetRecipientAddress(),
+				)
+			} else {
+				tx, err = outbox.ClaimReward0(
+					opts,
+					event.DestinationChain,
+					event.Receiver,
+					rrc_7755_outbox.PackedUserOperation(*parsed.ParsedUserOp),
+					proof,
+					cfg.Wallets.GetRecipientAddress(),
+				)
+			}
+			if err != nil {
+				return fmt.Errorf("sending claim transaction: %w", err)
+			}
+
+			log.Info("Claim transaction sent",
+				zap.String("message_id", messageID),
+				zap.String("hash", tx.Hash().Hex()),
+			)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&messageID, "message-id", "", "ID of the fulfilled message")
+	addChainFlag(cmd, &chainID, "source-chain", "source chain ID the message was posted on")
+	cmd.Flags().Uint64Var(&fromBlock, "from-block", 0, "first block to search for the MessagePosted event")
+	cmd.Flags().Uint64Var(&toBlock, "to-block", 0, "last block to search for the MessagePosted event (default latest)")
+	cmd.Flags().StringVar(&proofHex, "proof", "", "ABI encoded proof passed to claimReward (hex), generated by the prover of the destination chain when omitted")
+	_ = cmd.MarkFlagRequired("message-id")
+
+	return cmd
+}
+
+// generateProof generates the proof of the message with the prover of its destination chain. A message that is not
+// fulfilled in the proven state is rejected by the proof verification.
+func generateProof(
+	ctx context.Context,
+	log *zap.Logger,
+	cfg *config.Config,
+	clientMgr *client.Manager,
+	destChainID uint64,
+	messageID common.Hash,
+) ([]byte, error) {
+	provers, err := registry.NewRegistry(log, cfg, clientMgr)
+	if err != nil {
+		return nil, fmt.Errorf("initializing provers: %w", err)
+	}
+	prover, err := provers.Prover(destChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := prover.GenerateProof(ctx, messageID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("generating proof of %s: %w", messageID.Hex(), err)
+	}
+	encoded, err := proof.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("encoding proof: %w", err)
+	}
+
+	log.Info("Proof generated", zap.String("message_id", messageID.Hex()), zap.Uint64("destination_chain", destChainID))
+	return encoded, nil
+}
+
+// newClaim describes the claim of the request for the proof verifier, the prover type being the one the outbox the
+// request was posted to is deployed for
+func newClaim(
+	cfg *config.Config,
+	chainID uint64,
+	event *rrc_7755_outbox.RRC7755OutboxMessagePosted,
+	parsed *listener.ParsedMessage,
+	caller common.Address,
+	proof []byte,
+) (*proof_verifier.Claim, error) {
+	chainCfg, err := config.GetChainConfigByID(cfg, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := &proof_verifier.Claim{
+		MessageID:        event.MessageId,
+		DestinationChain: new(big.Int).SetBytes(event.DestinationChain[:]),
+		Inbox:            common.BytesToAddress(event.Receiver[:]),
+		Attributes:       event.Attributes,
+		Caller:           caller,
+		Proof:            proof,
+	}
+	for proverType, outbox := range chainCfg.OutboxAddresses {
+		if outbox == event.Raw.Address {
+			claim.ProverType = proverType
+		}
+	}
+	if claim.ProverType == "" {
+		return nil, fmt.Errorf("outbox %s is not configured on chain %d", event.Raw.Address.Hex(), chainID)
+	}
+
+	if parsed.ParsedUserOp != nil {
+		claim.Attributes, err = parsed.ParsedUserOp.GetPaymasterData()
+		if err != nil {
+			return nil, fmt.Errorf("getting paymaster data: %w", err)
+		}
+		claim.Inbox, err = proof_verifier.UserOpInbox(claim.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("getting user op inbox: %w", err)
+		}
+	}
+
+	return claim, nil
+}
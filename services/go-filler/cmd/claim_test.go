@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/listener"
+)
+
+const (
+	claimSourceChainID = 84532
+	claimDestChainID   = 421614
+)
+
+var (
+	claimOutbox     = common.HexToAddress("0xde9eb27d46ea852838657d2eca50071927e481a0")
+	claimInbox      = common.HexToAddress("0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb")
+	claimEntrypoint = common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032")
+	claimCaller     = common.HexToAddress("0x23214A0864FC0014CAb6030267738F01AFfdd547")
+)
+
+func claimConfig() *config.Config {
+	return &config.Config{Chain: map[string]config.ChainConfig{
+		"base-sepolia": {
+			ChainID:         claimSourceChainID,
+			OutboxAddresses: map[string]common.Address{config.ProverArbitrum: claimOutbox},
+		},
+	}}
+}
+
+// claimAttribute encodes an attribute as its selector followed by a 32 bytes word
+func claimAttribute(selector []byte, word []byte) []byte {
+	return append(selector, common.LeftPadBytes(word, 32)...)
+}
+
+func claimEvent(receiver common.Address, payload []byte, attributes [][]byte) *rrc_7755_outbox.RRC7755OutboxMessagePosted {
+	return &rrc_7755_outbox.RRC7755OutboxMessagePosted{
+		MessageId:        common.HexToHash("0x01"),
+		SourceChain:      common.BigToHash(big.NewInt(claimSourceChainID)),
+		DestinationChain: common.BigToHash(big.NewInt(claimDestChainID)),
+		Receiver:         common.BytesToHash(receiver.Bytes()),
+		Payload:          payload,
+		Attributes:       attributes,
+		Raw:              types.Log{Address: claimOutbox},
+	}
+}
+
+func TestNewClaim(t *testing.T) {
+	l2Oracle := claimAttribute([]byte{0x7f, 0xf7, 0x24, 0x5a}, common.HexToAddress("0x42").Bytes())
+	event := claimEvent(claimInbox, []byte{}, [][]byte{l2Oracle})
+	parsed, err := listener.ParseMessagePosted(event)
+	require.NoError(t, err)
+
+	claim, err := newClaim(claimConfig(), claimSourceChainID, event, parsed, claimCaller, []byte{0x01})
+	require.NoError(t, err)
+	require.Equal(t, config.ProverArbitrum, claim.ProverType)
+	require.Equal(t, claimInbox, claim.Inbox)
+	require.Equal(t, event.Attributes, claim.Attributes)
+	require.Equal(t, big.NewInt(claimDestChainID), claim.DestinationChain)
+
+	event.Raw.Address = common.HexToAddress("0x01")
+	_, err = newClaim(claimConfig(), claimSourceChainID, event, parsed, claimCaller, nil)
+	require.ErrorContains(t, err, "is not configured on chain 84532")
+}
+
+func TestNewClaimUserOp(t *testing.T) {
+	attributes := [][]byte{
+		claimAttribute([]byte{0x7f, 0xf7, 0x24, 0x5a}, common.HexToAddress("0x42").Bytes()),
+		claimAttribute([]byte{0xbd, 0x36, 0x23, 0x74}, claimInbox.Bytes()),
+	}
+	bytesArray, err := gethabi.NewType("bytes[]", "", nil)
+	require.NoError(t, err)
+	paymasterData, err := gethabi.Arguments{{Type: bytesArray}}.Pack(attributes)
+	require.NoError(t, err)
+
+	op := abi.PackedUserOperation{
+		Nonce:              big.NewInt(0),
+		PreVerificationGas: big.NewInt(0),
+		PaymasterAndData:   append(make([]byte, 52), paymasterData...),
+	}
+	payload, err := abi.PackedUserOperationArgs.Pack(op)
+	require.NoError(t, err)
+
+	// The receiver of a UserOp request is the EntryPoint, the fulfillment is stored by the inbox of its attributes
+	event := claimEvent(claimEntrypoint, payload, nil)
+	parsed, err := listener.ParseMessagePosted(event)
+	require.NoError(t, err)
+
+	claim, err := newClaim(claimConfig(), claimSourceChainID, event, parsed, claimCaller, []byte{0x01})
+	require.NoError(t, err)
+	require.Equal(t, claimInbox, claim.Inbox)
+	require.Equal(t, attributes, claim.Attributes)
+
+	// Without an inbox attribute the fulfillment can't be located
+	op.PaymasterAndData, err = gethabi.Arguments{{Type: bytesArray}}.Pack(attributes[:1])
+	require.NoError(t, err)
+	op.PaymasterAndData = append(make([]byte, 52), op.PaymasterAndData...)
+	payload, err = abi.PackedUserOperationArgs.Pack(op)
+	require.NoError(t, err)
+	event = claimEvent(claimEntrypoint, payload, nil)
+	parsed, err = listener.ParseMessagePosted(event)
+	require.NoError(t, err)
+
+	_, err = newClaim(claimConfig(), claimSourceChainID, event, parsed, claimCaller, nil)
+	require.ErrorContains(t, err, "getting user op inbox")
+}
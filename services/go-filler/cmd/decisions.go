@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/base-org/RRC-7755-poc/internal/audit"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+func newDecisionsCmd(flags *rootFlags) *cobra.Command {
+	var (
+		logPath   string
+		messageID string
+		outcome   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "decisions",
+		Short: "Replay the decision log, printing a line per decision or the full records of a message",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if logPath == "" {
+				_, cfg, err := flags.setup()
+				if err != nil {
+					return fmt.Errorf("creating config: %w", err)
+				}
+				logPath = cfg.DecisionLog.Path
+			}
+			if logPath == "" {
+				return errors.New("no decision log, set --log or decision-log.path")
+			}
+
+			if messageID != "" {
+				id := common.HexToHash(messageID)
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return audit.Replay(logPath, func(r *audit.DecisionRecord) error {
+					if r.MessageID != id {
+						return nil
+					}
+					return encoder.Encode(r)
+				})
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tMESSAGE ID\tSOURCE\tDESTINATION\tOUTCOME\tFAILED CHECK\tPROFIT (WEI)\tREASON")
+			err := audit.Replay(logPath, func(r *audit.DecisionRecord) error {
+				if outcome != "" && r.Outcome != outcome {
+					return nil
+				}
+
+				destination, failed, profit := "-", "-", "-"
+				if r.Inputs != nil {
+					destination = fmt.Sprint(r.Inputs.DestinationChain)
+				}
+				if check, ok := r.Failed(); ok {
+					failed = check.Name
+				}
+				if r.Reward != nil {
+					profit = r.Reward.Profit.String()
+				}
+
+				_, err := fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+					r.Timestamp.Format("2006-01-02T15:04:05Z"), r.MessageID.Hex(), r.SourceChain, destination, r.Outcome,
+					failed, profit, r.Reason)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&logPath, "log", "", "decision log to replay, defaults to decision-log.path of the config")
+	cmd.Flags().StringVar(&messageID, "message-id", "", "print the full records of this message")
+	cmd.Flags().StringVar(&outcome, "outcome", "", "only print decisions with this outcome (fulfilled, batched, shadow, failed, skipped)")
+
+	return cmd
+}
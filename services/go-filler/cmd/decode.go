@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/listener"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+func newDecodeCmd(flags *rootFlags) *cobra.Command {
+	var (
+		chainID uint64
+		txHash  string
+		rawLog  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "decode",
+		Short: "Parse a MessagePosted log or transaction into a ParsedMessage",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (txHash == "") == (rawLog == "") {
+				return errors.New("exactly one of --tx or --log must be set")
+			}
+
+			_, cfg, err := flags.setup()
+			if err != nil {
+				return fmt.Errorf("creating config: %w", err)
+			}
+
+			chainCfg, err := config.GetChainConfigByID(cfg, chainID)
+			if err != nil {
+				return err
+			}
+
+			// A log is parsed as is, only a transaction is read from the source chain
+			var logs []types.Log
+			if rawLog != "" {
+				var l types.Log
+				if err := json.Unmarshal([]byte(rawLog), &l); err != nil {
+					return fmt.Errorf("decoding log JSON: %w", err)
+				}
+				logs = append(logs, l)
+			} else {
+				logs, err = receiptLogs(cmd.Context(), chainCfg, common.HexToHash(txHash))
+				if err != nil {
+					return err
+				}
+			}
+
+			parsed, err := decodeMessages(chainCfg, logs)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			for _, message := range parsed {
+				if err := encoder.Encode(message); err != nil {
+					return fmt.Errorf("encoding parsed message: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	addChainFlag(cmd, &chainID, "source-chain", "source chain ID the message was posted on")
+	cmd.Flags().StringVar(&txHash, "tx", "", "hash of the transaction that emitted MessagePosted")
+	cmd.Flags().StringVar(&rawLog, "log", "", "MessagePosted log as returned by eth_getLogs (JSON)")
+
+	return cmd
+}
+
+// receiptLogs returns the logs of the transaction, dialing the source chain only
+func receiptLogs(ctx context.Context, chainCfg config.ChainConfig, txHash common.Hash) ([]types.Log, error) {
+	ethClient, err := client.NewEthClient(ctx, chainCfg)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to chain %d: %w", chainCfg.ChainID, err)
+	}
+	defer ethClient.Close()
+
+	receipt, err := ethClient.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting transaction receipt: %w", err)
+	}
+
+	logs := make([]types.Log, 0, len(receipt.Logs))
+	for _, l := range receipt.Logs {
+		logs = append(logs, *l)
+	}
+	return logs, nil
+}
+
+// decodeMessages parses the MessagePosted logs emitted by an outbox of the chain. The messages are only decoded, their
+// destination chain does not have to be configured.
+func decodeMessages(chainCfg config.ChainConfig, logs []types.Log) ([]*listener.ParsedMessage, error) {
+	filterer, err := rrc_7755_outbox.NewRRC7755OutboxFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating outbox filterer: %w", err)
+	}
+
+	var parsed []*listener.ParsedMessage
+	for _, l := range logs {
+		if !isOutbox(chainCfg, l.Address) {
+			continue
+		}
+
+		event, err := filterer.ParseMessagePosted(l)
+		if err != nil {
+			continue
+		}
+
+		message, err := listener.ParseMessagePosted(event)
+		if err != nil {
+			return nil, fmt.Errorf("parsing message %x: %w", event.MessageId, err)
+		}
+		parsed = append(parsed, message)
+	}
+
+	if len(parsed) == 0 {
+		return nil, errors.New("no MessagePosted log found")
+	}
+	return parsed, nil
+}
+
+func isOutbox(chainCfg config.ChainConfig, address common.Address) bool {
+	for _, outbox := range chainCfg.OutboxAddresses {
+		if outbox == address {
+			return true
+		}
+	}
+	return false
+}
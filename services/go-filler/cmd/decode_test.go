@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+// messagePostedLog encodes a MessagePosted log of the outbox for an EOA request to claimDestChainID
+func messagePostedLog(t *testing.T, outbox common.Address, messageID common.Hash) types.Log {
+	outboxAbi, err := rrc_7755_outbox.RRC7755OutboxMetaData.GetAbi()
+	require.NoError(t, err)
+	event := outboxAbi.Events["MessagePosted"]
+
+	values := map[string]any{
+		"messageId":        messageID,
+		"sourceChain":      common.BigToHash(big.NewInt(claimSourceChainID)),
+		"sender":           common.BytesToHash(claimCaller.Bytes()),
+		"destinationChain": common.BigToHash(big.NewInt(claimDestChainID)),
+		"receiver":         common.BytesToHash(claimInbox.Bytes()),
+		"payload":          []byte{},
+		"attributes":       [][]byte{claimAttribute([]byte{0x7f, 0xf7, 0x24, 0x5a}, common.HexToAddress("0x42").Bytes())},
+	}
+
+	topics := []common.Hash{event.ID}
+	var data []any
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			topics = append(topics, values[input.Name].(common.Hash))
+		} else if hash, ok := values[input.Name].(common.Hash); ok {
+			data = append(data, [32]byte(hash))
+		} else {
+			data = append(data, values[input.Name])
+		}
+	}
+	packed, err := event.Inputs.NonIndexed().Pack(data...)
+	require.NoError(t, err)
+
+	return types.Log{Address: outbox, Topics: topics, Data: packed}
+}
+
+func TestDecodeMessages(t *testing.T) {
+	chainCfg := config.ChainConfig{
+		ChainID:         claimSourceChainID,
+		OutboxAddresses: map[string]common.Address{config.ProverArbitrum: claimOutbox},
+	}
+	messageID := common.HexToHash("0x01")
+
+	// The destination chain of the message is not configured, it is only decoded
+	parsed, err := decodeMessages(chainCfg, []types.Log{
+		messagePostedLog(t, claimOutbox, messageID),
+		messagePostedLog(t, common.HexToAddress("0x01"), common.HexToHash("0x02")),
+	})
+	require.NoError(t, err)
+	require.Len(t, parsed, 1)
+	require.Equal(t, uint64(claimDestChainID), parsed[0].DestinationChain)
+
+	_, err = decodeMessages(chainCfg, []types.Log{messagePostedLog(t, common.HexToAddress("0x01"), messageID)})
+	require.ErrorContains(t, err, "no MessagePosted log found")
+}
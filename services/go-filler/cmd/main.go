@@ -1,60 +1,124 @@
 package main
 
 import (
-	"context"
-	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
 
-	"github.com/base-org/RRC-7755-poc/internal/client"
 	"github.com/base-org/RRC-7755-poc/internal/config"
-	"github.com/base-org/RRC-7755-poc/internal/listener"
 	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 )
 
+var (
+	Version = "dev"
+	Build   = "unknown"
+)
+
+type rootFlags struct {
+	configPath string
+	configDir  string
+	profile    string
+	envPath    string
+}
+
 func main() {
-	// Load .env file from project root
-	if err := godotenv.Load("../../.env"); err != nil {
-		log.Printf("Warning: .env file not found: %v", err)
+	flags := &rootFlags{}
+
+	rootCmd := &cobra.Command{
+		Use:           "filler",
+		Short:         "RRC-7755 filler service",
+		Version:       Version + " (" + Build + ")",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	defaultProfile := os.Getenv(config.ProfileEnvVar)
+	if defaultProfile == "" {
+		defaultProfile = config.ProfileLocal
 	}
 
-	log, err := zap.NewProduction()
-	if err != nil {
-		log.Fatal("failed to create logger", zap.Error(err))
+	rootCmd.PersistentFlags().StringVar(&flags.configPath, "config", "", "path to the YAML config file, overrides --profile")
+	rootCmd.PersistentFlags().StringVar(&flags.configDir, "config-dir", "./config", "directory holding the profile config files")
+	rootCmd.PersistentFlags().StringVar(&flags.profile, "profile", defaultProfile, "config profile to load (local, sepolia, mainnet), defaults to $"+config.ProfileEnvVar)
+	rootCmd.PersistentFlags().StringVar(&flags.envPath, "env", "../../.env", "path to the .env file")
+
+	rootCmd.AddCommand(
+		newRunCmd(flags),
+		newBackfillCmd(flags),
+		newDecodeCmd(flags),
+		newProveCmd(flags),
+		newClaimCmd(flags),
+		newReportCmd(flags),
+		newDecisionsCmd(flags),
+	)
+
+	if err := rootCmd.Execute(); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	log.Info("Starting up")
-	ctx, cancel := context.WithCancel(context.Background())
+// setup loads the .env file and the config shared by every subcommand
+func (f *rootFlags) setup() (*zap.Logger, *config.Config, error) {
+	if err := godotenv.Load(f.envPath); err != nil {
+		log.Printf("Warning: .env file not found: %v", err)
+	}
 
-	cfg, err := config.Unmarshal(log)
+	logger, err := zap.NewProduction()
 	if err != nil {
-		log.Fatal("creating config", zap.Error(err))
+		return nil, nil, err
 	}
 
-	clientMgr, err := client.NewManager(ctx, cfg)
+	configPath, err := f.configFile()
 	if err != nil {
-		log.Fatal("initializing client manager", zap.Error(err))
+		return nil, nil, err
 	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		fmt.Println("Received shutdown signal. Shutting down gracefully...")
-		cancel()
-	}()
-
-	outboxListener, err := listener.NewOutboxListener(ctx, clientMgr, cfg, log)
+	cfg, err := config.Unmarshal(logger, configPath)
 	if err != nil {
-		log.Fatal("initializing outbox listener", zap.Error(err))
+		return nil, nil, err
 	}
-	err = outboxListener.Run(ctx)
-	if err != nil {
-		log.Fatal("starting listener", zap.Error(err))
+
+	return logger, cfg, nil
+}
+
+// configFile returns the --config path, or the file of the selected profile
+func (f *rootFlags) configFile() (string, error) {
+	if f.configPath != "" {
+		return f.configPath, nil
 	}
-	log.Info("outbox listener created successfully")
+	return config.ProfilePath(f.configDir, f.profile)
+}
 
-}
\ No newline at end of file
+// addChainFlag adds the required --chain flag, also accepted as --<alias> (source-chain or dest-chain) to name the
+// chain the command reads from
+func addChainFlag(cmd *cobra.Command, chainID *uint64, alias string, usage string) {
+	cmd.Flags().Uint64Var(chainID, "chain", 0, usage)
+	cmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == alias {
+			name = "chain"
+		}
+		return pflag.NormalizedName(name)
+	})
+	_ = cmd.MarkFlagRequired("chain")
+}
+
+type shadowFlags struct {
+	enabled    bool
+	outputPath string
+}
+
+func addShadowFlags(cmd *cobra.Command, flags *shadowFlags) {
+	cmd.Flags().BoolVar(&flags.enabled, "shadow", false, "evaluate requests without broadcasting transactions")
+	cmd.Flags().StringVar(&flags.outputPath, "shadow-output", "", "JSONL file shadow decisions are appended to")
+}
+
+// apply overrides the shadow config with the flags explicitly set on the command line
+func (f *shadowFlags) apply(cmd *cobra.Command, cfg *config.Config) {
+	if cmd.Flags().Changed("shadow") {
+		cfg.Shadow.Enabled = f.enabled
+	}
+	if cmd.Flags().Changed("shadow-output") {
+		cfg.Shadow.OutputPath = f.outputPath
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		newCmd  func(flags *rootFlags) *cobra.Command
+		args    []string
+		wantErr string
+	}{
+		{name: "backfill", newCmd: newBackfillCmd, args: []string{"--chain", "84532", "--from", "1", "--to", "2"}},
+		{name: "backfill alias", newCmd: newBackfillCmd, args: []string{"--source-chain", "84532", "--from", "1", "--to", "2"}},
+		{name: "decode", newCmd: newDecodeCmd, args: []string{"--chain", "84532"}},
+		{name: "claim alias", newCmd: newClaimCmd, args: []string{"--source-chain", "84532", "--message-id", "0x01"}},
+		{name: "prove", newCmd: newProveCmd, args: []string{"--chain", "84532", "--message-id", "0x01"}},
+		{name: "prove alias", newCmd: newProveCmd, args: []string{"--dest-chain", "84532", "--message-id", "0x01"}},
+		{name: "prove source alias", newCmd: newProveCmd, args: []string{"--source-chain", "84532"}, wantErr: "unknown flag"},
+		{name: "missing", newCmd: newDecodeCmd, wantErr: `required flag(s) "chain" not set`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.newCmd(&rootFlags{})
+
+			err := cmd.ParseFlags(tt.args)
+			if err == nil {
+				err = cmd.ValidateRequiredFlags()
+			}
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			chainID, err := cmd.Flags().GetUint64("chain")
+			require.NoError(t, err)
+			require.Equal(t, uint64(84532), chainID)
+		})
+	}
+}
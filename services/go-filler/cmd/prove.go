@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proof_orchestrator"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/base-org/RRC-7755-poc/internal/prover/registry"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+// provedMessage is the output of prove for a message
+type provedMessage struct {
+	AnchorBlock uint64                `json:"anchorBlock,omitempty"`
+	Proof       *proofs.ArbitrumProof `json:"proof,omitempty"`
+	Encoded     hexutil.Bytes         `json:"encoded,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+func newProveCmd(flags *rootFlags) *cobra.Command {
+	var (
+		messageIDs []string
+		chainID    uint64
+		cacheDir   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prove",
+		Short: "Print the RRC7755ArbitrumProver proofs of fulfilled messages",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log, cfg, err := flags.setup()
+			if err != nil {
+				return fmt.Errorf("creating config: %w", err)
+			}
+
+			ctx := cmd.Context()
+
+			dstConfig, err := config.GetChainConfigByID(cfg, chainID)
+			if err != nil {
+				return err
+			}
+
+			clientMgr, err := client.NewManager(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("initializing client manager: %w", err)
+			}
+
+			provers, err := registry.NewRegistry(log, cfg, clientMgr)
+			if err != nil {
+				return fmt.Errorf("initializing provers: %w", err)
+			}
+			prover, err := provers.Prover(chainID)
+			if err != nil {
+				return err
+			}
+			// Only the Arbitrum prover shares the state proofs of an anchor block between requests
+			sharedProver, ok := prover.(proof_orchestrator.Prover)
+			if !ok {
+				return fmt.Errorf("the %s prover of chain %d cannot prove several messages at once", dstConfig.ProverType, chainID)
+			}
+
+			if !cmd.Flags().Changed("cache-dir") {
+				cacheDir = cfg.Proof.CacheDir
+			}
+			orchestrator, err := proof_orchestrator.NewOrchestrator(
+				log,
+				map[uint64]proof_orchestrator.Destination{
+					chainID: {Prover: sharedProver, Inbox: dstConfig.InboxAddress},
+				},
+				cacheDir,
+			)
+			if err != nil {
+				return err
+			}
+
+			requests := make([]proof_orchestrator.Request, len(messageIDs))
+			for i, messageID := range messageIDs {
+				requests[i] = proof_orchestrator.Request{
+					MessageID:        common.HexToHash(messageID),
+					DestinationChain: chainID,
+				}
+			}
+
+			output := make(map[string]provedMessage, len(requests))
+			var errs []error
+			for _, result := range orchestrator.Prove(ctx, requests) {
+				if result.Err != nil {
+					output[result.MessageID.Hex()] = provedMessage{Error: result.Err.Error()}
+					errs = append(errs, fmt.Errorf("generating proof of %s: %w", result.MessageID.Hex(), result.Err))
+					continue
+				}
+				encoded, err := result.Proof.Encode()
+				if err != nil {
+					return err
+				}
+				output[result.MessageID.Hex()] = provedMessage{
+					AnchorBlock: result.AnchorBlock,
+					Proof:       result.Proof,
+					Encoded:     encoded,
+				}
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(output); err != nil {
+				return err
+			}
+			return errors.Join(errs...)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&messageIDs, "message-id", nil, "IDs of the fulfilled messages, repeatable")
+	addChainFlag(cmd, &chainID, "dest-chain", "destination chain ID the message was fulfilled on")
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "directory proofs are cached in, defaults to proof.cache-dir")
+	_ = cmd.MarkFlagRequired("message-id")
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/base-org/RRC-7755-poc/internal/reconcile"
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd(flags *rootFlags) *cobra.Command {
+	var storePath string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Print the win rate, revenue and competitors of every source chain from the reconcile store",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if storePath == "" {
+				_, cfg, err := flags.setup()
+				if err != nil {
+					return fmt.Errorf("creating config: %w", err)
+				}
+				storePath = cfg.Reconcile.StorePath
+			}
+			if storePath == "" {
+				return errors.New("no reconcile store, set --store or reconcile.store-path")
+			}
+
+			store, err := reconcile.ReadStore(storePath)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHAIN\tCLAIMED\tWON\tLOST\tSKIPPED\tPENDING\tWIN RATE\tREVENUE (ASSET:WEI)\tCOMPETITORS")
+			for _, r := range store.Reports() {
+				fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%.1f%%\t%s\t%d\n",
+					r.SourceChain, r.Claimed, r.Won, r.Lost, r.Skipped, r.Pending, r.WinRate()*100, r.Revenue, len(r.Competitors))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&storePath, "store", "", "reconcile store to read, defaults to reconcile.store-path of the config")
+
+	return cmd
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/listener"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newRunCmd(flags *rootFlags) *cobra.Command {
+	shadow := &shadowFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Listen to the configured outboxes and fulfill incoming requests",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log, cfg, err := flags.setup()
+			if err != nil {
+				return fmt.Errorf("creating config: %w", err)
+			}
+			shadow.apply(cmd, cfg)
+
+			log.Info("Starting up")
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+
+			clientMgr, err := client.NewManager(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("initializing client manager: %w", err)
+			}
+
+			sigChan := make(chan os.Signal, 1)
+			signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+			go func() {
+				<-sigChan
+				fmt.Println("Received shutdown signal. Shutting down gracefully...")
+				cancel()
+			}()
+
+			outboxListener, err := listener.NewOutboxListener(ctx, clientMgr, cfg, log)
+			if err != nil {
+				return fmt.Errorf("initializing outbox listener: %w", err)
+			}
+			defer outboxListener.Close()
+			log.Info("outbox listener created successfully")
+
+			// setup already loaded the config file, so resolving its path again cannot fail
+			configPath, _ := flags.configFile()
+			watcher := config.NewWatcher(log, configPath, func(newCfg *config.Config) error {
+				shadow.apply(cmd, newCfg)
+				return outboxListener.ApplyConfig(ctx, newCfg)
+			})
+			go func() {
+				if err := watcher.Run(ctx); err != nil {
+					log.Error("Watching config file, hot reload disabled", zap.Error(err))
+				}
+			}()
+
+			if err := outboxListener.Run(ctx); err != nil {
+				return fmt.Errorf("starting listener: %w", err)
+			}
+
+			log.Info("outbox listener stopped", zap.String("service", outboxListener.ServiceName()))
+			return nil
+		},
+	}
+
+	addShadowFlags(cmd, shadow)
+
+	return cmd
+}
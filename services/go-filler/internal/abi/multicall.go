@@ -0,0 +1,43 @@
+package abi
+
+import (
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Multicall3Address is the address Multicall3 is deployed at on every chain
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+//go:embed json/multicall3.json
+var multicall3ABIJson string
+
+var multicall3ABI abi.ABI
+
+type Call3Value struct {
+	Target       common.Address `abi:"target"`
+	AllowFailure bool           `abi:"allowFailure"`
+	Value        *big.Int       `abi:"value"`
+	CallData     []byte         `abi:"callData"`
+}
+
+func init() {
+	var err error
+	multicall3ABI, err = abi.JSON(strings.NewReader(multicall3ABIJson))
+	if err != nil {
+		panic(fmt.Errorf("initializing Multicall3 ABI: %w", err))
+	}
+}
+
+// PackAggregate3Value packs a Multicall3 aggregate3Value call, which must be sent with the sum of the call values
+func PackAggregate3Value(calls []Call3Value) ([]byte, error) {
+	data, err := multicall3ABI.Pack("aggregate3Value", calls)
+	if err != nil {
+		return nil, fmt.Errorf("packing aggregate3Value: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,29 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackAggregate3Value(t *testing.T) {
+	calls := []Call3Value{
+		{Target: common.HexToAddress("0x1234"), AllowFailure: true, Value: big.NewInt(3), CallData: []byte{0x01}},
+		{Target: common.HexToAddress("0x5678"), Value: big.NewInt(7), CallData: []byte{0x02, 0x03}},
+	}
+
+	data, err := PackAggregate3Value(calls)
+	require.NoError(t, err)
+
+	method := multicall3ABI.Methods["aggregate3Value"]
+	require.Equal(t, method.ID, data[:4])
+
+	unpacked, err := method.Inputs.Unpack(data[4:])
+	require.NoError(t, err)
+
+	var decoded []Call3Value
+	require.NoError(t, method.Inputs.Copy(&decoded, unpacked))
+	require.Equal(t, calls, decoded)
+}
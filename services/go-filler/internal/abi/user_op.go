@@ -70,11 +70,11 @@ func UnmarshalPackedUserOperation(data []byte) (*PackedUserOperation, error) {
 
 func (o *PackedUserOperation) GetPaymasterData() ([][]byte, error) {
 	paymasterData := o.PaymasterAndData
-	if len(paymasterData) < 52 {
+	if len(paymasterData) < paymasterDataOffset {
 		return nil, fmt.Errorf("paymaster data is too short")
 	}
 
-	unpacked, err := paymasterDataArgs.Unpack(paymasterData[52:])
+	unpacked, err := paymasterDataArgs.Unpack(paymasterData[paymasterDataOffset:])
 	if err != nil {
 		return nil, fmt.Errorf("unpacking paymaster data: %w", err)
 	}
@@ -86,3 +86,105 @@ func (o *PackedUserOperation) GetPaymasterData() ([][]byte, error) {
 
 	return decoded, nil
 }
+
+const (
+	addressSize = 20
+	uint128Size = 16
+
+	// paymasterAndData starts with the paymaster address and its verification and postOp gas limits
+	paymasterDataOffset = addressSize + 2*uint128Size // 52
+)
+
+// unpackUint128Pair splits a packed bytes32 into its high and low uint128 halves
+func unpackUint128Pair(packed [32]byte) (*big.Int, *big.Int) {
+	return new(big.Int).SetBytes(packed[:uint128Size]), new(big.Int).SetBytes(packed[uint128Size:])
+}
+
+// VerificationGasLimit is the high half of AccountGasLimits
+func (o *PackedUserOperation) VerificationGasLimit() *big.Int {
+	high, _ := unpackUint128Pair(o.AccountGasLimits)
+	return high
+}
+
+// CallGasLimit is the low half of AccountGasLimits
+func (o *PackedUserOperation) CallGasLimit() *big.Int {
+	_, low := unpackUint128Pair(o.AccountGasLimits)
+	return low
+}
+
+// MaxPriorityFeePerGas is the high half of GasFees
+func (o *PackedUserOperation) MaxPriorityFeePerGas() *big.Int {
+	high, _ := unpackUint128Pair(o.GasFees)
+	return high
+}
+
+// MaxFeePerGas is the low half of GasFees
+func (o *PackedUserOperation) MaxFeePerGas() *big.Int {
+	_, low := unpackUint128Pair(o.GasFees)
+	return low
+}
+
+// Paymaster returns the paymaster address, the zero address if the op has no paymaster
+func (o *PackedUserOperation) Paymaster() common.Address {
+	if len(o.PaymasterAndData) < addressSize {
+		return common.Address{}
+	}
+	return common.BytesToAddress(o.PaymasterAndData[:addressSize])
+}
+
+// PaymasterVerificationGasLimit is the gas limit of the paymaster validatePaymasterUserOp call
+func (o *PackedUserOperation) PaymasterVerificationGasLimit() *big.Int {
+	if len(o.PaymasterAndData) < paymasterDataOffset {
+		return new(big.Int)
+	}
+	return new(big.Int).SetBytes(o.PaymasterAndData[addressSize : addressSize+uint128Size])
+}
+
+// PaymasterPostOpGasLimit is the gas limit of the paymaster postOp call
+func (o *PackedUserOperation) PaymasterPostOpGasLimit() *big.Int {
+	if len(o.PaymasterAndData) < paymasterDataOffset {
+		return new(big.Int)
+	}
+	return new(big.Int).SetBytes(o.PaymasterAndData[addressSize+uint128Size : paymasterDataOffset])
+}
+
+// Factory returns the account factory of InitCode, the zero address if the account is already deployed
+func (o *PackedUserOperation) Factory() common.Address {
+	if len(o.InitCode) < addressSize {
+		return common.Address{}
+	}
+	return common.BytesToAddress(o.InitCode[:addressSize])
+}
+
+// RequiredGas is the maximum gas the EntryPoint charges for the op, as computed for its prefund
+func (o *PackedUserOperation) RequiredGas() *big.Int {
+	required := new(big.Int).Add(o.VerificationGasLimit(), o.CallGasLimit())
+	required.Add(required, o.PaymasterVerificationGasLimit())
+	required.Add(required, o.PaymasterPostOpGasLimit())
+	return required.Add(required, o.PreVerificationGas)
+}
+
+// Validate checks the packed fields are well formed, before anything is simulated
+func (o *PackedUserOperation) Validate() error {
+	if len(o.InitCode) > 0 && len(o.InitCode) < addressSize {
+		return fmt.Errorf("initCode is too short to hold a factory address: %d bytes", len(o.InitCode))
+	}
+
+	if len(o.PaymasterAndData) < paymasterDataOffset {
+		return errors.New("paymaster data is too short")
+	}
+
+	if o.VerificationGasLimit().Sign() == 0 {
+		return errors.New("verification gas limit is zero")
+	}
+
+	if o.MaxPriorityFeePerGas().Cmp(o.MaxFeePerGas()) > 0 {
+		return fmt.Errorf(
+			"max priority fee per gas %d is above max fee per gas %d",
+			o.MaxPriorityFeePerGas(),
+			o.MaxFeePerGas(),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func packUint128Pair(high, low int64) [32]byte {
+	var packed [32]byte
+	big.NewInt(high).FillBytes(packed[:uint128Size])
+	big.NewInt(low).FillBytes(packed[uint128Size:])
+	return packed
+}
+
+func newTestUserOp() *PackedUserOperation {
+	paymaster := common.HexToAddress("0x1234")
+	paymasterAndData := append(paymaster.Bytes(), make([]byte, 2*uint128Size)...)
+	big.NewInt(30000).FillBytes(paymasterAndData[addressSize : addressSize+uint128Size])
+	big.NewInt(20000).FillBytes(paymasterAndData[addressSize+uint128Size : paymasterDataOffset])
+
+	return &PackedUserOperation{
+		Sender:             common.HexToAddress("0x5678"),
+		Nonce:              big.NewInt(0),
+		AccountGasLimits:   packUint128Pair(100000, 200000),
+		PreVerificationGas: big.NewInt(50000),
+		GasFees:            packUint128Pair(1000000, 2000000000),
+		PaymasterAndData:   paymasterAndData,
+	}
+}
+
+func TestPackedUserOperationGasFields(t *testing.T) {
+	op := newTestUserOp()
+
+	require.Equal(t, big.NewInt(100000), op.VerificationGasLimit())
+	require.Equal(t, big.NewInt(200000), op.CallGasLimit())
+	require.Equal(t, big.NewInt(1000000), op.MaxPriorityFeePerGas())
+	require.Equal(t, big.NewInt(2000000000), op.MaxFeePerGas())
+	require.Equal(t, common.HexToAddress("0x1234"), op.Paymaster())
+	require.Equal(t, big.NewInt(30000), op.PaymasterVerificationGasLimit())
+	require.Equal(t, big.NewInt(20000), op.PaymasterPostOpGasLimit())
+	require.Equal(t, big.NewInt(400000), op.RequiredGas())
+	require.Equal(t, common.Address{}, op.Factory())
+}
+
+func TestPackedUserOperationValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(op *PackedUserOperation)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(op *PackedUserOperation) {},
+		},
+		{
+			name: "valid with init code",
+			modify: func(op *PackedUserOperation) {
+				op.InitCode = append(common.HexToAddress("0x9abc").Bytes(), 0x01, 0x02)
+			},
+		},
+		{
+			name: "truncated init code",
+			modify: func(op *PackedUserOperation) {
+				op.InitCode = []byte{0x01, 0x02}
+			},
+			wantErr: "initCode is too short",
+		},
+		{
+			name: "missing paymaster gas limits",
+			modify: func(op *PackedUserOperation) {
+				op.PaymasterAndData = op.PaymasterAndData[:addressSize]
+			},
+			wantErr: "paymaster data is too short",
+		},
+		{
+			name: "zero verification gas",
+			modify: func(op *PackedUserOperation) {
+				op.AccountGasLimits = packUint128Pair(0, 200000)
+			},
+			wantErr: "verification gas limit is zero",
+		},
+		{
+			name: "priority fee above max fee",
+			modify: func(op *PackedUserOperation) {
+				op.GasFees = packUint128Pair(3, 2)
+			},
+			wantErr: "max priority fee per gas 3 is above max fee per gas 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := newTestUserOp()
+			tt.modify(op)
+
+			err := op.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
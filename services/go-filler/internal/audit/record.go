@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Outcomes of a decision
+const (
+	// OutcomeFulfilled is a request whose fulfillment transaction was sent
+	OutcomeFulfilled = "fulfilled"
+	// OutcomeBatched is a request added to a batch, sent later on
+	OutcomeBatched = "batched"
+	// OutcomeShadow is a request that would have been fulfilled in shadow mode
+	OutcomeShadow = "shadow"
+	// OutcomeFailed is a request whose fulfillment transaction could not be sent
+	OutcomeFailed = "failed"
+	// OutcomeSkipped is a request rejected by a check
+	OutcomeSkipped = "skipped"
+)
+
+// Checks run on a request, in order
+const (
+	CheckValidate      = "validate"
+	CheckScreening     = "screening"
+	CheckExpiry        = "expiry"
+	CheckPolicy        = "policy"
+	CheckMagicSpend    = "magic-spend"
+	CheckUserOp        = "user-op"
+	CheckRisk          = "risk"
+	CheckReward        = "reward"
+	CheckMessageStatus = "message-status"
+)
+
+// DecisionRecord is what the filler decided for a request and why
+type DecisionRecord struct {
+	Timestamp    time.Time      `json:"timestamp"`
+	MessageID    common.Hash    `json:"message_id"`
+	SourceChain  uint64         `json:"source_chain"`
+	SourceBlock  uint64         `json:"source_block"`
+	SourceTxHash common.Hash    `json:"source_tx_hash"`
+	Outbox       common.Address `json:"outbox"`
+
+	// Inputs is nil when the request could not be parsed
+	Inputs *Inputs        `json:"inputs,omitempty"`
+	Checks []CheckResult  `json:"checks"`
+	Policy *PolicyOutcome `json:"policy,omitempty"`
+	Call   *Call          `json:"call,omitempty"`
+	Gas    *GasQuote      `json:"gas,omitempty"`
+	Reward *RewardMath    `json:"reward,omitempty"`
+
+	Outcome string `json:"outcome"`
+	// Reason is why the request was skipped or failed
+	Reason string       `json:"reason,omitempty"`
+	TxHash *common.Hash `json:"tx_hash,omitempty"`
+}
+
+// Inputs are the parsed request, with the UserOp attributes for UserOp requests
+type Inputs struct {
+	DestinationChain uint64          `json:"destination_chain"`
+	Sender           common.Address  `json:"sender"`
+	Receiver         common.Address  `json:"receiver"`
+	Payload          hexutil.Bytes   `json:"payload"`
+	RawAttributes    []hexutil.Bytes `json:"raw_attributes"`
+	IsUserOp         bool            `json:"is_user_op"`
+	UserOpSender     *common.Address `json:"user_op_sender,omitempty"`
+	Requester        common.Hash     `json:"requester"`
+	RewardAsset      common.Address  `json:"reward_asset"`
+	RewardAmount     *big.Int        `json:"reward_amount"`
+	Expiry           *big.Int        `json:"expiry"`
+	FinalityDelay    *big.Int        `json:"finality_delay"`
+	MagicSpendToken  common.Address  `json:"magic_spend_token"`
+	MagicSpendAmount *big.Int        `json:"magic_spend_amount"`
+}
+
+// CheckResult is the result of a check, Error is empty when it passed
+type CheckResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// PolicyOutcome lists the policy rules that fired on the request
+type PolicyOutcome struct {
+	Allowed bool     `json:"allowed"`
+	Rules   []string `json:"rules,omitempty"`
+}
+
+// Call is the fulfillment transaction, without its gas
+type Call struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *big.Int       `json:"value"`
+	Data  hexutil.Bytes  `json:"data"`
+}
+
+// GasQuote is the gas limit and price of the fulfillment. QuotedGasPrice is the price of the gas strategy, GasPrice
+// differs from it when competitors were outbid.
+type GasQuote struct {
+	GasLimit       *big.Int `json:"gas_limit"`
+	QuotedGasPrice *big.Int `json:"quoted_gas_price"`
+	GasPrice       *big.Int `json:"gas_price"`
+	Strategy       string   `json:"strategy"`
+}
+
+// RewardMath is the expected profit of the request at the gas price: Profit = Reward - Value - MagicSpend - GasCost
+type RewardMath struct {
+	Reward     *big.Int `json:"reward"`
+	Value      *big.Int `json:"value"`
+	MagicSpend *big.Int `json:"magic_spend"`
+	GasCost    *big.Int `json:"gas_cost"`
+	Profit     *big.Int `json:"profit"`
+}
+
+// Check records the result of a check and returns its error
+func (r *DecisionRecord) Check(name string, err error) error {
+	result := CheckResult{Name: name, Passed: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	r.Checks = append(r.Checks, result)
+	return err
+}
+
+// Failed returns the first check that did not pass, if any
+func (r *DecisionRecord) Failed() (CheckResult, bool) {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return check, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// NewRewardMath computes the expected profit of fulfilling a request for reward
+func NewRewardMath(reward, value, magicSpend, gasLimit, gasPrice *big.Int) *RewardMath {
+	gasCost := new(big.Int).Mul(gasLimit, gasPrice)
+
+	profit := new(big.Int).Sub(reward, value)
+	profit.Sub(profit, magicSpend)
+	profit.Sub(profit, gasCost)
+
+	return &RewardMath{Reward: reward, Value: value, MagicSpend: magicSpend, GasCost: gasCost, Profit: profit}
+}
@@ -0,0 +1,157 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	bytesPerMB = 1 << 20
+	// maxRecordSize bounds the records Replay reads, which carry the whole request payload
+	maxRecordSize = 16 * bytesPerMB
+)
+
+// Sink appends DecisionRecords to a JSONL file. Once the file would grow above the max size it is rotated: path.1
+// becomes path.2 and so on, path becomes path.1 and a new path is started. Only maxFiles rotated files are kept.
+type Sink struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenSink appends to the file at path, a maxSizeMB of 0 never rotates it
+func OpenSink(path string, maxSizeMB int, maxFiles int) (*Sink, error) {
+	s := &Sink{path: path, maxSize: int64(maxSizeMB) * bytesPerMB, maxFiles: maxFiles}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Sink) Write(r *DecisionRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding decision record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing decision record: %w", err)
+	}
+	return nil
+}
+
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *Sink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening decision log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("opening decision log: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *Sink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("closing decision log: %w", err)
+	}
+
+	if err := os.Remove(rotatedPath(s.path, s.maxFiles)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing oldest decision log: %w", err)
+	}
+	for i := s.maxFiles - 1; i >= 1; i-- {
+		err := os.Rename(rotatedPath(s.path, i), rotatedPath(s.path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotating decision log: %w", err)
+		}
+	}
+	if s.maxFiles > 0 {
+		if err := os.Rename(s.path, rotatedPath(s.path, 1)); err != nil {
+			return fmt.Errorf("rotating decision log: %w", err)
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return fmt.Errorf("rotating decision log: %w", err)
+	}
+
+	return s.open()
+}
+
+// Replay reads the decision log at path, rotated files included, and calls fn with every record from the oldest to the
+// newest. Replay stops at the first error of fn.
+func Replay(path string, fn func(*DecisionRecord) error) error {
+	var paths []string
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotatedPath(path, i)); err != nil {
+			break
+		}
+		paths = append([]string{rotatedPath(path, i)}, paths...)
+	}
+	paths = append(paths, path)
+
+	for _, p := range paths {
+		if err := replayFile(p, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replayFile(path string, fn func(*DecisionRecord) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening decision log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		var r DecisionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return fmt.Errorf("decoding %s line %d: %w", path, line, err)
+		}
+		if err := fn(&r); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+func rotatedPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func replayIDs(t *testing.T, path string) []common.Hash {
+	t.Helper()
+
+	var ids []common.Hash
+	require.NoError(t, Replay(path, func(r *DecisionRecord) error {
+		ids = append(ids, r.MessageID)
+		return nil
+	}))
+	return ids
+}
+
+func TestSinkRotatesAndReplaysInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	sink, err := OpenSink(path, 1, 2)
+	require.NoError(t, err)
+
+	// Each record carries a payload taking a bit less than a third of the max size once hex encoded, so every fourth
+	// record starts a new file
+	payload := make([]byte, bytesPerMB/7)
+	var written []common.Hash
+	for i := 1; i <= 10; i++ {
+		id := common.BigToHash(big.NewInt(int64(i)))
+		require.NoError(t, sink.Write(&DecisionRecord{MessageID: id, Inputs: &Inputs{Payload: payload}, Outcome: OutcomeSkipped}))
+		written = append(written, id)
+	}
+	require.NoError(t, sink.Close())
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(bytesPerMB))
+	}
+	_, err = os.Stat(path + ".3")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// The records of the dropped file are lost, the others are replayed oldest first
+	require.Equal(t, written[3:], replayIDs(t, path))
+
+	// Reopening appends to the current file
+	sink, err = OpenSink(path, 1, 2)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(&DecisionRecord{MessageID: common.HexToHash("0xff")}))
+	require.NoError(t, sink.Close())
+	require.Equal(t, common.HexToHash("0xff"), replayIDs(t, path)[7])
+}
+
+func TestReplayStopsOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	sink, err := OpenSink(path, 0, 0)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sink.Write(&DecisionRecord{}))
+	}
+	require.NoError(t, sink.Close())
+
+	stop := errors.New("stop")
+	calls := 0
+	err = Replay(path, func(*DecisionRecord) error {
+		calls++
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, calls)
+}
+
+func TestDecisionRecordCheck(t *testing.T) {
+	var rec DecisionRecord
+	require.NoError(t, rec.Check(CheckExpiry, nil))
+	require.EqualError(t, rec.Check(CheckReward, errors.New("reward amount is not enough")), "reward amount is not enough")
+
+	failed, ok := rec.Failed()
+	require.True(t, ok)
+	require.Equal(t, CheckResult{Name: CheckReward, Error: "reward amount is not enough"}, failed)
+
+	math := NewRewardMath(big.NewInt(1000), big.NewInt(300), big.NewInt(100), big.NewInt(20), big.NewInt(10))
+	require.Equal(t, big.NewInt(200), math.GasCost)
+	require.Equal(t, big.NewInt(400), math.Profit)
+}
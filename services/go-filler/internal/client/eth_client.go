@@ -11,8 +11,10 @@ type EthClient interface {
 	ethereum.GasEstimator
 	ethereum.PendingStateReader
 	ethereum.GasPricer
+	ethereum.FeeHistoryReader
 	ethereum.TransactionSender
+	ethereum.TransactionReader
 	ethereum.ChainIDReader
 	ethereum.ChainStateReader
-	bind.ContractFilterer
+	bind.ContractBackend
 }
@@ -3,40 +3,148 @@ package client
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"sync"
 
 	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type ChainClient struct {
 	Client EthClient
 	Config config.ChainConfig
+
+	// conn counts the work in flight on Client, nil for clients not created by the manager
+	conn *connection
+}
+
+// Acquire holds the connection of the chain until release is called, so a reload replacing or removing the chain
+// closes it only once the work in flight on it is done
+func (c *ChainClient) Acquire() (release func()) {
+	if c.conn == nil {
+		return func() {}
+	}
+
+	c.conn.acquire()
+	var once sync.Once
+	return func() { once.Do(c.conn.release) }
+}
+
+// connection is a chain node connection shared by the ChainClient values of the chain across reloads
+type connection struct {
+	mu      sync.Mutex
+	client  EthClient
+	holders int
+	retired bool
+	closed  bool
+}
+
+func newConnection(client EthClient) *connection {
+	return &connection{client: client}
+}
+
+func (c *connection) acquire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.holders++
+}
+
+func (c *connection) release() {
+	c.mu.Lock()
+	c.holders--
+	closing := c.closeLocked()
+	c.mu.Unlock()
+
+	if closing {
+		closeClient(c.client)
+	}
+}
+
+// retire closes the connection once it is no longer held
+func (c *connection) retire() {
+	c.mu.Lock()
+	c.retired = true
+	closing := c.closeLocked()
+	c.mu.Unlock()
+
+	if closing {
+		closeClient(c.client)
+	}
+}
+
+// closeLocked reports whether the connection must be closed now, c.mu must be held
+func (c *connection) closeLocked() bool {
+	if !c.retired || c.holders > 0 || c.closed {
+		return false
+	}
+	c.closed = true
+	return true
+}
+
+// RPCClient returns the raw RPC client of the chain, for the calls EthClient does not expose
+func (c *ChainClient) RPCClient() (*rpc.Client, error) {
+	rpcClient, ok := c.Client.(interface{ Client() *rpc.Client })
+	if !ok {
+		return nil, fmt.Errorf("client of chain %d does not expose its RPC client", c.Config.ChainID)
+	}
+	return rpcClient.Client(), nil
 }
 
 type Manager struct {
 	Chains map[uint64]*ChainClient
+
+	mu sync.RWMutex
+	// dial connects to a chain node, it defaults to NewEthClient
+	dial func(ctx context.Context, chainConfig config.ChainConfig) (EthClient, error)
+}
+
+// ChainDiff lists the chain IDs affected by a config reload
+type ChainDiff struct {
+	Added       []uint64
+	Removed     []uint64
+	Reconnected []uint64
+	// Updated chains keep their connection, only their contract addresses changed
+	Updated []uint64
+}
+
+// Empty reports whether the reload did not affect any chain
+func (d ChainDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Reconnected) == 0 && len(d.Updated) == 0
 }
 
 func NewManager(ctx context.Context, cfg *config.Config) (*Manager, error) {
-	chains := make(map[uint64]*ChainClient, len(cfg.Chain))
+	m := &Manager{
+		Chains: make(map[uint64]*ChainClient, len(cfg.Chain)),
+	}
 
 	for name, chainCfg := range cfg.Chain {
-		client, err := NewEthClient(ctx, chainCfg)
+		client, err := m.dialChain(ctx, chainCfg)
 		if err != nil {
 			return nil, fmt.Errorf("creating client for chain %s: %w", name, err)
 		}
 
-		chains[chainCfg.ChainID] = &ChainClient{
+		m.Chains[chainCfg.ChainID] = &ChainClient{
 			Client: client,
 			Config: chainCfg,
+			conn:   newConnection(client),
 		}
 	}
 
-	return &Manager{
-		Chains: chains,
-	}, nil
+	return m, nil
+}
+
+func (m *Manager) dialChain(ctx context.Context, chainConfig config.ChainConfig) (EthClient, error) {
+	if m.dial != nil {
+		return m.dial(ctx, chainConfig)
+	}
+	return NewEthClient(ctx, chainConfig)
 }
 
 func (m *Manager) GetChainClient(chainID uint64) (*ChainClient, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	client, ok := m.Chains[chainID]
 	if !ok {
 		return nil, fmt.Errorf("no client found for chain ID %d", chainID)
@@ -44,6 +152,91 @@ func (m *Manager) GetChainClient(chainID uint64) (*ChainClient, error) {
 	return client, nil
 }
 
+// GetAllClients returns a snapshot of the chain clients, safe to range over while a reload is applied
 func (m *Manager) GetAllClients() map[uint64]*ChainClient {
-	return m.Chains
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chains := make(map[uint64]*ChainClient, len(m.Chains))
+	for id, chain := range m.Chains {
+		chains[id] = chain
+	}
+	return chains
+}
+
+// Apply reconciles the running clients with cfg. New chains are dialed and removed chains are closed. Chains whose
+// node settings changed are reconnected, other config changes reuse the existing connection. Nothing is changed if
+// any chain fails to connect. The connections replaced or removed are closed once the work holding them is done.
+func (m *Manager) Apply(ctx context.Context, cfg *config.Config) (ChainDiff, error) {
+	var diff ChainDiff
+
+	m.mu.RLock()
+	current := make(map[uint64]*ChainClient, len(m.Chains))
+	for id, chain := range m.Chains {
+		current[id] = chain
+	}
+	m.mu.RUnlock()
+
+	next := make(map[uint64]*ChainClient, len(cfg.Chain))
+	var dialed []EthClient
+	for name, chainCfg := range cfg.Chain {
+		existing, ok := current[chainCfg.ChainID]
+		switch {
+		case ok && reflect.DeepEqual(existing.Config, chainCfg):
+			next[chainCfg.ChainID] = existing
+			continue
+		case ok && existing.Config.NodeURL == chainCfg.NodeURL &&
+			existing.Config.NodeInsecureSkipVerify == chainCfg.NodeInsecureSkipVerify:
+			next[chainCfg.ChainID] = &ChainClient{Client: existing.Client, Config: chainCfg, conn: existing.conn}
+			diff.Updated = append(diff.Updated, chainCfg.ChainID)
+			continue
+		}
+
+		client, err := m.dialChain(ctx, chainCfg)
+		if err != nil {
+			for _, c := range dialed {
+				closeClient(c)
+			}
+			return ChainDiff{}, fmt.Errorf("creating client for chain %s: %w", name, err)
+		}
+		dialed = append(dialed, client)
+		next[chainCfg.ChainID] = &ChainClient{Client: client, Config: chainCfg, conn: newConnection(client)}
+
+		if ok {
+			diff.Reconnected = append(diff.Reconnected, chainCfg.ChainID)
+		} else {
+			diff.Added = append(diff.Added, chainCfg.ChainID)
+		}
+	}
+
+	for id := range current {
+		if _, ok := next[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+
+	m.mu.Lock()
+	m.Chains = next
+	m.mu.Unlock()
+
+	// Old connections are no longer reachable through the manager, they are closed once released by the work in flight
+	for _, id := range append(diff.Removed, diff.Reconnected...) {
+		if old := current[id]; old.conn != nil {
+			old.conn.retire()
+		} else {
+			closeClient(old.Client)
+		}
+	}
+
+	for _, ids := range [][]uint64{diff.Added, diff.Removed, diff.Reconnected, diff.Updated} {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+
+	return diff, nil
+}
+
+func closeClient(client EthClient) {
+	if closer, ok := client.(interface{ Close() }); ok {
+		closer.Close()
+	}
 }
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type closableClient struct {
+	*mocks.MockEthClient
+	closed bool
+}
+
+func (c *closableClient) Close() {
+	c.closed = true
+}
+
+func testChain(chainID uint64, nodeURL string, outbox string) config.ChainConfig {
+	return config.ChainConfig{
+		ChainID:         chainID,
+		NodeURL:         nodeURL,
+		OutboxAddresses: map[string]common.Address{config.ProverOPStack: common.HexToAddress(outbox)},
+	}
+}
+
+func TestManagerApply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	var dialed []uint64
+	m := &Manager{
+		Chains: make(map[uint64]*ChainClient),
+		dial: func(ctx context.Context, chainConfig config.ChainConfig) (EthClient, error) {
+			dialed = append(dialed, chainConfig.ChainID)
+			return &closableClient{MockEthClient: mocks.NewMockEthClient(ctrl)}, nil
+		},
+	}
+	for _, chainCfg := range []config.ChainConfig{
+		testChain(1, "wss://one.example.com", "0x01"),
+		testChain(2, "wss://two.example.com", "0x02"),
+		testChain(3, "wss://three.example.com", "0x03"),
+		testChain(5, "wss://five.example.com", "0x05"),
+	} {
+		ethClient := &closableClient{MockEthClient: mocks.NewMockEthClient(ctrl)}
+		m.Chains[chainCfg.ChainID] = &ChainClient{Client: ethClient, Config: chainCfg, conn: newConnection(ethClient)}
+	}
+	before := m.GetAllClients()
+
+	// Work still in flight on chain 3 when it is reconnected
+	release := before[3].Acquire()
+
+	diff, err := m.Apply(context.Background(), &config.Config{Chain: map[string]config.ChainConfig{
+		"unchanged":   testChain(1, "wss://one.example.com", "0x01"),
+		"new-outbox":  testChain(2, "wss://two.example.com", "0x22"),
+		"rotated-url": testChain(3, "wss://three-backup.example.com", "0x03"),
+		"added":       testChain(4, "wss://four.example.com", "0x04"),
+	}})
+	require.NoError(t, err)
+
+	require.Equal(t, []uint64{4}, diff.Added)
+	require.Equal(t, []uint64{5}, diff.Removed)
+	require.Equal(t, []uint64{3}, diff.Reconnected)
+	require.Equal(t, []uint64{2}, diff.Updated)
+	require.ElementsMatch(t, []uint64{3, 4}, dialed)
+
+	after := m.GetAllClients()
+	require.Len(t, after, 4)
+	require.Same(t, before[1], after[1])
+	require.Same(t, before[2].Client, after[2].Client)
+	require.Equal(t, common.HexToAddress("0x22"), after[2].Config.OutboxAddresses[config.ProverOPStack])
+	require.NotSame(t, before[3].Client, after[3].Client)
+
+	require.False(t, before[1].Client.(*closableClient).closed)
+	require.False(t, before[2].Client.(*closableClient).closed)
+	require.False(t, before[3].Client.(*closableClient).closed)
+	require.True(t, before[5].Client.(*closableClient).closed)
+
+	release()
+	require.True(t, before[3].Client.(*closableClient).closed)
+}
+
+func TestChainClientAcquire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	ethClient := &closableClient{MockEthClient: mocks.NewMockEthClient(ctrl)}
+	chain := &ChainClient{Client: ethClient, conn: newConnection(ethClient)}
+
+	first := chain.Acquire()
+	second := chain.Acquire()
+	chain.conn.retire()
+	require.False(t, ethClient.closed)
+
+	// Releasing twice doesn't release the connection for the other holder
+	first()
+	first()
+	require.False(t, ethClient.closed)
+
+	second()
+	require.True(t, ethClient.closed)
+
+	// A chain client not created by the manager has nothing to hold
+	(&ChainClient{Client: ethClient}).Acquire()()
+}
+
+func TestManagerApplyDialError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	existing := &ChainClient{
+		Client: &closableClient{MockEthClient: mocks.NewMockEthClient(ctrl)},
+		Config: testChain(1, "wss://one.example.com", "0x01"),
+	}
+	m := &Manager{
+		Chains: map[uint64]*ChainClient{1: existing},
+		dial: func(ctx context.Context, chainConfig config.ChainConfig) (EthClient, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	_, err := m.Apply(context.Background(), &config.Config{Chain: map[string]config.ChainConfig{
+		"rotated-url": testChain(1, "wss://one-backup.example.com", "0x01"),
+	}})
+	require.ErrorContains(t, err, "connection refused")
+
+	chain, err := m.GetChainClient(1)
+	require.NoError(t, err)
+	require.Same(t, existing, chain)
+	require.False(t, existing.Client.(*closableClient).closed)
+}
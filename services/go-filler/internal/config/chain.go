@@ -2,10 +2,39 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// Prover types that outbox-addresses entries are keyed by
+const (
+	ProverArbitrum = "arbitrum"
+	ProverOPStack  = "opstack"
+	ProverHashi    = "hashi"
+)
+
+// ProverTypes lists the prover types an outbox can be deployed for
+var ProverTypes = []string{ProverArbitrum, ProverOPStack, ProverHashi}
+
+// ProvableTypes lists the prover types the filler can generate proofs for, the ones a prover-type can be set to
+var ProvableTypes = []string{ProverArbitrum}
+
+// RequiresL1 reports whether proofs of the prover type go through the state of the L1 of the destination chain
+func RequiresL1(proverType string) bool {
+	return proverType == ProverArbitrum || proverType == ProverOPStack
+}
+
+// Gas strategies a destination chain prices its fulfillments with
+const (
+	GasStrategyNode       = "node"
+	GasStrategyFeeHistory = "fee-history"
+	GasStrategyMargin     = "margin"
+)
+
+// GasStrategies lists the gas strategies that can be selected
+var GasStrategies = []string{GasStrategyNode, GasStrategyFeeHistory, GasStrategyMargin}
+
 type ChainConfig struct {
 	ChainID uint64 `mapstructure:"chain-id"`
 
@@ -19,6 +48,29 @@ type ChainConfig struct {
 
 	InboxAddress      common.Address `mapstructure:"inbox-address"`
 	EntrypointAddress common.Address `mapstructure:"entrypoint-address"`
+
+	// ProverType is the prover that proves fulfillments on this chain, one of ProvableTypes. The Arbitrum and OP Stack
+	// provers prove them through the state of the L1 with chain ID L1ChainID.
+	ProverType string `mapstructure:"prover-type"`
+	L1ChainID  uint64 `mapstructure:"l1-chain-id"`
+	// Devnet mocks the beacon chain proofs of the state of this chain when it is the L1 of a destination chain
+	Devnet bool `mapstructure:"devnet"`
+
+	// MinClaimLatency is the minimum time between a fulfillment on this chain and the claim of its reward on the
+	// source chain, the time it takes for the fulfillment to be provable there
+	MinClaimLatency time.Duration `mapstructure:"min-claim-latency"`
+
+	GasStrategy GasStrategyConfig `mapstructure:"gas-strategy"`
+}
+
+// GasStrategyConfig selects how the gas price of fulfillments on the chain is set, the node suggestion when Type is
+// empty. fee-history adds the Percentile of the priority fees of the last BlockCount blocks to the next base fee, and
+// margin bids ProfitShare of the expected profit of the request on top of the node suggestion.
+type GasStrategyConfig struct {
+	Type        string  `mapstructure:"type"`
+	Percentile  float64 `mapstructure:"percentile"`
+	BlockCount  uint64  `mapstructure:"block-count"`
+	ProfitShare float64 `mapstructure:"profit-share"`
 }
 
 func GetChainConfigByID(cfg *Config, id uint64) (ChainConfig, error) {
@@ -29,3 +81,11 @@ func GetChainConfigByID(cfg *Config, id uint64) (ChainConfig, error) {
 	}
 	return ChainConfig{}, fmt.Errorf("chain with id %d not found", id)
 }
+
+// Name is the selected strategy, node when none is set
+func (c *GasStrategyConfig) Name() string {
+	if c.Type == "" {
+		return GasStrategyNode
+	}
+	return c.Type
+}
@@ -1,14 +1,39 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+// ProfileEnvVar selects the config profile when no profile flag is given
+const ProfileEnvVar = "FILLER_PROFILE"
+
+const (
+	ProfileLocal   = "local"
+	ProfileSepolia = "sepolia"
+	ProfileMainnet = "mainnet"
+)
+
+// Profiles lists the config profiles that can be loaded, each one has a file in cmd/config
+var Profiles = []string{ProfileLocal, ProfileSepolia, ProfileMainnet}
+
+const (
+	ScreeningFile = "file"
+	ScreeningHTTP = "http"
+)
+
 func stringToAddressHookFunc() mapstructure.DecodeHookFuncType {
 	return func(
 		f reflect.Type,
@@ -22,29 +47,69 @@ func stringToAddressHookFunc() mapstructure.DecodeHookFuncType {
 			return data, nil
 		}
 
-		// Convert it by parsing
-		return common.HexToAddress(data.(string)), nil
+		return parseAddress(data.(string))
 	}
 }
 
-type (
-	Config struct {
-		Endpoint       string            `mapstructure:"endpoint"`
-		Timeout        string            `mapstructure:"timeout"`
-		Database       database          `mapstructure:"database"`
-		TestBool       bool              `mapstructure:"test-bool"`
-		TestStringList []string          `mapstructure:"test-string-list"`
-		TestStringMap  map[string]string `mapstructure:"test-string-map"`
-		TestEnv        string            `mapstructure:"test-env"`
-		TestFile       string            `mapstructure:"test-file"`
+func stringToBigIntHookFunc() mapstructure.DecodeHookFuncType {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if t != reflect.TypeOf(&big.Int{}) {
+			return data, nil
+		}
 
-		Chain   map[string]ChainConfig `mapstructure:"chain"`
-		Wallets WalletConfig           `mapstructure:"wallets"`
+		switch f.Kind() {
+		case reflect.String:
+			if data.(string) == "" {
+				return (*big.Int)(nil), nil
+			}
+			amount, ok := new(big.Int).SetString(data.(string), 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid amount %q", data)
+			}
+			return amount, nil
+		case reflect.Int, reflect.Int64:
+			return big.NewInt(reflect.ValueOf(data).Int()), nil
+		case reflect.Uint, reflect.Uint64:
+			return new(big.Int).SetUint64(reflect.ValueOf(data).Uint()), nil
+		default:
+			return data, nil
+		}
 	}
+}
 
-	database struct {
-		URL      string `mapstructure:"url"`
-		Password string `mapstructure:"password"`
+// parseAddress converts a hex string to an address, rejecting malformed and zero addresses
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+
+	address := common.HexToAddress(s)
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("zero address %q", s)
+	}
+
+	return address, nil
+}
+
+type (
+	Config struct {
+		Chain        map[string]ChainConfig `mapstructure:"chain"`
+		Wallets      WalletConfig           `mapstructure:"wallets"`
+		Shadow       ShadowConfig           `mapstructure:"shadow"`
+		Paymaster    PaymasterConfig        `mapstructure:"paymaster"`
+		UserOpBatch  BatchConfig            `mapstructure:"user-op-batch"`
+		FulfillBatch FulfillBatchConfig     `mapstructure:"fulfill-batch"`
+		Reconcile    ReconcileConfig        `mapstructure:"reconcile"`
+		Market       MarketConfig           `mapstructure:"market"`
+		Risk         RiskConfig             `mapstructure:"risk"`
+		Policy       PolicyConfig           `mapstructure:"policy"`
+		Screening    ScreeningConfig        `mapstructure:"screening"`
+		DecisionLog  DecisionLogConfig      `mapstructure:"decision-log"`
+		Proof        ProofConfig            `mapstructure:"proof"`
 	}
 
 	WalletConfig struct {
@@ -52,6 +117,139 @@ type (
 		PrivateKey       string `mapstructure:"private-key"`
 		RecipientAddress string `mapstructure:"recipient-address"`
 	}
+
+	// ShadowConfig enables evaluating requests without broadcasting fulfillment transactions
+	ShadowConfig struct {
+		Enabled    bool   `mapstructure:"enabled"`
+		OutputPath string `mapstructure:"output-path"`
+	}
+
+	// PaymasterConfig sets the balances kept in the paymaster of every destination chain
+	PaymasterConfig struct {
+		Enabled      bool              `mapstructure:"enabled"`
+		PollInterval time.Duration     `mapstructure:"poll-interval"`
+		Gas          BalanceThresholds `mapstructure:"gas"`
+		MagicSpend   BalanceThresholds `mapstructure:"magic-spend"`
+	}
+
+	// BatchConfig groups requests of the same destination chain into a single transaction. A batch is sent once it
+	// holds MaxSize requests or MaxDelay after its first request.
+	BatchConfig struct {
+		Enabled  bool          `mapstructure:"enabled"`
+		MaxSize  int           `mapstructure:"max-size"`
+		MaxDelay time.Duration `mapstructure:"max-delay"`
+	}
+
+	// FulfillBatchConfig batches inbox fulfills through a contract exposing Multicall3 aggregate3Value, the canonical
+	// Multicall3 deployment when MulticallAddress is not set
+	FulfillBatchConfig struct {
+		BatchConfig      `mapstructure:",squash"`
+		MulticallAddress common.Address `mapstructure:"multicall-address"`
+	}
+
+	// ReconcileConfig tracks which fulfiller claims each request. Decisions and claims are persisted in StorePath, on
+	// first start the CrossChainCallCompleted history is read from LookbackBlocks before the head of each chain.
+	ReconcileConfig struct {
+		Enabled        bool          `mapstructure:"enabled"`
+		StorePath      string        `mapstructure:"store-path"`
+		LookbackBlocks uint64        `mapstructure:"lookback-blocks"`
+		ReportInterval time.Duration `mapstructure:"report-interval"`
+	}
+
+	// MarketConfig watches the CallFulfilled events of every destination inbox to follow the competing fulfillers. The
+	// last Window fulfillments of each chain are kept, and when OutbidPercentile is set the gas price of profitable
+	// requests is raised to that percentile of the competitor gas prices plus OutbidPremium (a fraction).
+	MarketConfig struct {
+		Enabled          bool    `mapstructure:"enabled"`
+		OutputPath       string  `mapstructure:"output-path"`
+		Window           int     `mapstructure:"window"`
+		OutbidPercentile float64 `mapstructure:"outbid-percentile"`
+		OutbidPremium    float64 `mapstructure:"outbid-premium"`
+	}
+
+	// RiskConfig caps the capital fronted for requests until their reward is claimed. Amounts are in wei and unset caps
+	// are not enforced. Outstanding exposure is persisted in StorePath, a requester can have at most RequesterLimit
+	// requests fulfilled per RequesterWindow.
+	RiskConfig struct {
+		Enabled          bool          `mapstructure:"enabled"`
+		StorePath        string        `mapstructure:"store-path"`
+		MaxRequestValue  *big.Int      `mapstructure:"max-request-value"`
+		MaxChainExposure *big.Int      `mapstructure:"max-chain-exposure"`
+		MaxTotalExposure *big.Int      `mapstructure:"max-total-exposure"`
+		RequesterLimit   int           `mapstructure:"requester-limit"`
+		RequesterWindow  time.Duration `mapstructure:"requester-window"`
+	}
+
+	// PolicyConfig restricts which requests are fulfilled, evaluated before they are priced. Requesters and targets are
+	// addresses or bytes32 values and selectors 4 byte hex values. A non empty allow list only lets its entries through.
+	PolicyConfig struct {
+		Enabled    bool       `mapstructure:"enabled"`
+		MaxCalls   int        `mapstructure:"max-calls"`
+		Requesters ListConfig `mapstructure:"requesters"`
+		Targets    ListConfig `mapstructure:"targets"`
+		Selectors  ListConfig `mapstructure:"selectors"`
+	}
+
+	ListConfig struct {
+		Allow []string `mapstructure:"allow"`
+		Deny  []string `mapstructure:"deny"`
+	}
+
+	// ScreeningConfig screens the addresses of every validated request with a local list file or a compliance
+	// service, selected by Type. Every decision is appended to AuditPath.
+	ScreeningConfig struct {
+		Enabled   bool                `mapstructure:"enabled"`
+		Type      string              `mapstructure:"type"`
+		AuditPath string              `mapstructure:"audit-path"`
+		File      FileScreeningConfig `mapstructure:"file"`
+		HTTP      HTTPScreeningConfig `mapstructure:"http"`
+	}
+
+	// FileScreeningConfig blocks the addresses listed in Path, one per line, reloaded when the file changes
+	FileScreeningConfig struct {
+		Path           string        `mapstructure:"path"`
+		ReloadInterval time.Duration `mapstructure:"reload-interval"`
+	}
+
+	// HTTPScreeningConfig asks the compliance service at URL, APIKey is sent as a bearer token when set
+	HTTPScreeningConfig struct {
+		URL     string        `mapstructure:"url"`
+		APIKey  string        `mapstructure:"api-key"`
+		Timeout time.Duration `mapstructure:"timeout"`
+	}
+
+	// DecisionLogConfig appends a record of every decision to Path, rotated once it reaches MaxSizeMB. Only the
+	// MaxFiles most recent rotated files are kept.
+	DecisionLogConfig struct {
+		Enabled   bool   `mapstructure:"enabled"`
+		Path      string `mapstructure:"path"`
+		MaxSizeMB int    `mapstructure:"max-size-mb"`
+		MaxFiles  int    `mapstructure:"max-files"`
+	}
+
+	// ProofConfig sets where generated proofs are cached, by destination chain and anchor block. Proofs are only
+	// cached in memory when CacheDir is empty. RPC sets how the RPC calls of the provers are retried.
+	ProofConfig struct {
+		CacheDir string      `mapstructure:"cache-dir"`
+		RPC      RetryConfig `mapstructure:"rpc"`
+	}
+
+	// RetryConfig makes up to Attempts calls, each bounded by Timeout when set, separated by a backoff doubling from
+	// InitialBackoff up to MaxBackoff
+	RetryConfig struct {
+		Attempts       int           `mapstructure:"attempts"`
+		Timeout        time.Duration `mapstructure:"timeout"`
+		InitialBackoff time.Duration `mapstructure:"initial-backoff"`
+		MaxBackoff     time.Duration `mapstructure:"max-backoff"`
+	}
+
+	// BalanceThresholds are amounts in wei. A balance below MinBalance is topped up to TargetBalance and a balance
+	// above MaxBalance, when set, is withdrawn down to TargetBalance.
+	BalanceThresholds struct {
+		MinBalance    *big.Int `mapstructure:"min-balance"`
+		TargetBalance *big.Int `mapstructure:"target-balance"`
+		MaxBalance    *big.Int `mapstructure:"max-balance"`
+	}
 )
 
 func (c *WalletConfig) GetFromAddress() common.Address {
@@ -62,11 +260,32 @@ func (c *WalletConfig) GetRecipientAddress() common.Address {
 	return common.HexToAddress(c.RecipientAddress)
 }
 
-func Unmarshal(log *zap.Logger) (*Config, error) {
+func (c *WalletConfig) GetPrivateKey() (*ecdsa.PrivateKey, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(c.PrivateKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	return privateKey, nil
+}
+
+// ProfilePath returns the path of the config file of profile inside dir
+func ProfilePath(dir string, profile string) (string, error) {
+	if !slices.Contains(Profiles, profile) {
+		return "", fmt.Errorf("unknown config profile %q, want one of %v", profile, Profiles)
+	}
+	return filepath.Join(dir, profile+".yaml"), nil
+}
+
+// Unmarshal reads and validates the YAML config file at path, resolving env:// and file:// secrets
+func Unmarshal(log *zap.Logger, path string) (*Config, error) {
+	return UnmarshalWithResolver(log, path, NewSecretResolver())
+}
+
+// UnmarshalWithResolver reads and validates the YAML config file at path, resolving secrets with resolver
+func UnmarshalWithResolver(log *zap.Logger, path string, resolver *SecretResolver) (*Config, error) {
 	v := viper.New()
 	v.AutomaticEnv()
-	v.AddConfigPath("./config")
-	v.SetConfigName("local")
+	v.SetConfigFile(path)
 	v.SetConfigType("yaml")
 
 	err := v.ReadInConfig()
@@ -75,11 +294,20 @@ func Unmarshal(log *zap.Logger) (*Config, error) {
 	}
 
 	var config Config
-	err = v.Unmarshal(&config, viper.DecodeHook(stringToAddressHookFunc()))
+	err = v.UnmarshalExact(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		resolver.decodeHook(),
+		stringToAddressHookFunc(),
+		stringToBigIntHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+	)))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("decoding config file %s: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
 	}
 
-	log.Info("Using config file", zap.Any("config", config))
+	log.Info("Using config file", zap.String("path", path), zap.Object("config", &config))
 	return &config, nil
 }
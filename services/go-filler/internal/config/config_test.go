@@ -0,0 +1,377 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func validDestinationChain(chainID uint64) ChainConfig {
+	return ChainConfig{
+		ChainID:            chainID,
+		NodeURL:            "wss://example.com",
+		OutboxAddresses:    map[string]common.Address{ProverOPStack: common.HexToAddress("0x3542dd26727844524ea7c136c5c38ff8088b30ba")},
+		L2Oracle:           common.HexToAddress("0x042B2E6C5E99d4c521bd49beeD5E99651D9B0Cf4"),
+		L2OracleStorageKey: "0x0000000000000000000000000000000000000000000000000000000000000076",
+		InboxAddress:       common.HexToAddress("0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb"),
+		EntrypointAddress:  common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032"),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "L1 chain without destination entries",
+			modify: func(cfg *Config) {
+				cfg.Chain["sepolia"] = ChainConfig{ChainID: 11155111, NodeURL: "wss://example.com"}
+			},
+		},
+		{
+			name: "duplicate chain id",
+			modify: func(cfg *Config) {
+				cfg.Chain["other"] = validDestinationChain(421614)
+			},
+			wantErr: "duplicate chain-id 421614",
+		},
+		{
+			name: "destination chain missing inbox",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.InboxAddress = common.Address{}
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: "missing inbox-address",
+		},
+		{
+			name: "destination chain missing entrypoint",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.EntrypointAddress = common.Address{}
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: "missing entrypoint-address",
+		},
+		{
+			name: "destination chain missing oracle",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.L2Oracle = common.Address{}
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: "missing l2-oracle",
+		},
+		{
+			name: "unknown prover type",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.OutboxAddresses["zksync"] = common.HexToAddress("0x657c8b8d05001e51b1cdcfc8709537a8963390a4")
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: `unknown prover type "zksync"`,
+		},
+		{
+			name: "malformed oracle storage key",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.L2OracleStorageKey = "0x76"
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: "invalid l2-oracle-storage-key",
+		},
+		{
+			name: "negative min claim latency",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.MinClaimLatency = -time.Hour
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: "negative min-claim-latency",
+		},
+		{
+			name: "paymaster target below min",
+			modify: func(cfg *Config) {
+				cfg.Paymaster = PaymasterConfig{
+					Enabled:    true,
+					Gas:        BalanceThresholds{MinBalance: big.NewInt(2), TargetBalance: big.NewInt(1)},
+					MagicSpend: BalanceThresholds{MinBalance: big.NewInt(1), TargetBalance: big.NewInt(2)},
+				}
+			},
+			wantErr: "paymaster: gas: target-balance is below min-balance",
+		},
+		{
+			name: "paymaster missing thresholds",
+			modify: func(cfg *Config) {
+				cfg.Paymaster = PaymasterConfig{
+					Enabled: true,
+					Gas:     BalanceThresholds{MinBalance: big.NewInt(1), TargetBalance: big.NewInt(2)},
+				}
+			},
+			wantErr: "paymaster: magic-spend: missing min-balance or target-balance",
+		},
+		{
+			name: "user op batch without max size",
+			modify: func(cfg *Config) {
+				cfg.UserOpBatch = BatchConfig{Enabled: true, MaxDelay: time.Second}
+			},
+			wantErr: "user-op-batch: max-size must be at least 1",
+		},
+		{
+			name: "fulfill batch without max delay",
+			modify: func(cfg *Config) {
+				cfg.FulfillBatch = FulfillBatchConfig{BatchConfig: BatchConfig{Enabled: true, MaxSize: 5}}
+			},
+			wantErr: "fulfill-batch: max-delay must be positive",
+		},
+		{
+			name: "reconcile without store",
+			modify: func(cfg *Config) {
+				cfg.Reconcile = ReconcileConfig{Enabled: true}
+			},
+			wantErr: "reconcile: missing store-path",
+		},
+		{
+			name: "market outbid percentile out of range",
+			modify: func(cfg *Config) {
+				cfg.Market = MarketConfig{Enabled: true, Window: 10, OutbidPercentile: 150}
+			},
+			wantErr: "market: outbid-percentile must be between 0 and 100",
+		},
+		{
+			name: "risk requester limit without window",
+			modify: func(cfg *Config) {
+				cfg.Risk = RiskConfig{Enabled: true, StorePath: "exposure.jsonl", RequesterLimit: 5}
+			},
+			wantErr: "risk: requester-window must be positive when requester-limit is set",
+		},
+		{
+			name: "policy selector of the wrong size",
+			modify: func(cfg *Config) {
+				cfg.Policy = PolicyConfig{Selectors: ListConfig{Deny: []string{"0xa9059cbb00"}}}
+			},
+			wantErr: `policy: selectors: invalid entry "0xa9059cbb00"`,
+		},
+		{
+			name: "screening http without url",
+			modify: func(cfg *Config) {
+				cfg.Screening = ScreeningConfig{Enabled: true, Type: ScreeningHTTP, AuditPath: "audit.jsonl"}
+			},
+			wantErr: `screening: invalid http url ""`,
+		},
+		{
+			name: "decision log without path",
+			modify: func(cfg *Config) {
+				cfg.DecisionLog = DecisionLogConfig{Enabled: true, MaxSizeMB: 100}
+			},
+			wantErr: "decision-log: missing path",
+		},
+		{
+			name: "negative proof rpc timeout",
+			modify: func(cfg *Config) {
+				cfg.Proof.RPC = RetryConfig{Attempts: 3, Timeout: -time.Second}
+			},
+			wantErr: "proof: rpc: negative timeout",
+		},
+		{
+			name: "arbitrum prover settling to the configured L1",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.ProverType = ProverArbitrum
+				chain.L1ChainID = 11155111
+				cfg.Chain["arbitrum-sepolia"] = chain
+				cfg.Chain["sepolia"] = ChainConfig{ChainID: 11155111, NodeURL: "wss://example.com"}
+			},
+		},
+		{
+			name: "arbitrum prover without L1",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.ProverType = ProverArbitrum
+				chain.L1ChainID = 11155111
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: "chain arbitrum-sepolia: l1-chain-id 11155111 is not a configured chain",
+		},
+		{
+			name: "unknown prover type",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.ProverType = "zksync"
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: `unknown prover-type "zksync"`,
+		},
+		{
+			name: "prover type without prover",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.ProverType = ProverHashi
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: `prover-type "hashi" has no prover implemented`,
+		},
+		{
+			name: "unknown gas strategy",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.GasStrategy = GasStrategyConfig{Type: "fastest"}
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: `gas-strategy: unknown type "fastest"`,
+		},
+		{
+			name: "margin gas strategy bidding the whole profit",
+			modify: func(cfg *Config) {
+				chain := cfg.Chain["arbitrum-sepolia"]
+				chain.GasStrategy = GasStrategyConfig{Type: GasStrategyMargin, ProfitShare: 1}
+				cfg.Chain["arbitrum-sepolia"] = chain
+			},
+			wantErr: "gas-strategy: profit-share must be at least 0 and below 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Chain: map[string]ChainConfig{
+					"arbitrum-sepolia": validDestinationChain(421614),
+				},
+				Wallets: WalletConfig{FromAddress: testFromAddress, PrivateKey: testPrivateKey},
+			}
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// testWallets is the wallets section every config file requires
+var testWallets = fmt.Sprintf(`wallets:
+  from-address: '%s'
+  private-key: '%s'
+`, testFromAddress, testPrivateKey)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestUnmarshal(t *testing.T) {
+	const chainTemplate = `chain:
+  arbitrum-sepolia:
+    chain-id: 421614
+    node-url: wss://example.com
+    l2-oracle: '%s'
+    l2-oracle-storage-key: '0x0000000000000000000000000000000000000000000000000000000000000076'
+    inbox-address: '0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb'
+    entrypoint-address: '0x0000000071727De22E5E9d8BAf0edAc6f37da032'
+` + "%s"
+
+	t.Run("valid file", func(t *testing.T) {
+		path := writeConfig(t, fmt.Sprintf(chainTemplate, "0x042B2E6C5E99d4c521bd49beeD5E99651D9B0Cf4", testWallets))
+
+		cfg, err := Unmarshal(zap.NewNop(), path)
+		require.NoError(t, err)
+		require.Equal(t, common.HexToAddress("0x042B2E6C5E99d4c521bd49beeD5E99651D9B0Cf4"), cfg.Chain["arbitrum-sepolia"].L2Oracle)
+	})
+
+	t.Run("fulfill batch", func(t *testing.T) {
+		path := writeConfig(t, fmt.Sprintf(chainTemplate, "0x042B2E6C5E99d4c521bd49beeD5E99651D9B0Cf4", testWallets)+`fulfill-batch:
+  enabled: true
+  max-size: 5
+  max-delay: 1s
+  multicall-address: '0xcA11bde05977b3631167028862bE2a173976CA11'
+`)
+
+		cfg, err := Unmarshal(zap.NewNop(), path)
+		require.NoError(t, err)
+		require.True(t, cfg.FulfillBatch.Enabled)
+		require.Equal(t, 5, cfg.FulfillBatch.MaxSize)
+		require.Equal(t, time.Second, cfg.FulfillBatch.MaxDelay)
+		require.Equal(t, common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11"), cfg.FulfillBatch.MulticallAddress)
+	})
+
+	t.Run("malformed address", func(t *testing.T) {
+		path := writeConfig(t, fmt.Sprintf(chainTemplate, "0x042B2E6C5E99", testWallets))
+
+		_, err := Unmarshal(zap.NewNop(), path)
+		require.ErrorContains(t, err, "invalid address")
+	})
+
+	t.Run("zero address", func(t *testing.T) {
+		path := writeConfig(t, fmt.Sprintf(chainTemplate, "0x0000000000000000000000000000000000000000", testWallets))
+
+		_, err := Unmarshal(zap.NewNop(), path)
+		require.ErrorContains(t, err, "zero address")
+	})
+
+	t.Run("missing wallets", func(t *testing.T) {
+		path := writeConfig(t, fmt.Sprintf(chainTemplate, "0x042B2E6C5E99d4c521bd49beeD5E99651D9B0Cf4", ""))
+
+		_, err := Unmarshal(zap.NewNop(), path)
+		require.ErrorContains(t, err, "from-address is required")
+		require.ErrorContains(t, err, "private-key is required")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := writeConfig(t, fmt.Sprintf(chainTemplate, "0x042B2E6C5E99d4c521bd49beeD5E99651D9B0Cf4", testWallets)+"test-bool: true\n")
+
+		_, err := Unmarshal(zap.NewNop(), path)
+		require.ErrorContains(t, err, "test-bool")
+	})
+}
+
+func TestProfilePath(t *testing.T) {
+	path, err := ProfilePath("./config", ProfileSepolia)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("config", "sepolia.yaml"), path)
+
+	_, err = ProfilePath("./config", "staging")
+	require.ErrorContains(t, err, `unknown config profile "staging"`)
+}
+
+func TestProfileFiles(t *testing.T) {
+	t.Setenv("WALLET_ADDRESS", testFromAddress)
+	t.Setenv("WALLET_PRIVATE_KEY", testPrivateKey)
+	t.Setenv("RECIPIENT_WALLET_ADDRESS", testFromAddress)
+	for _, name := range []string{
+		"BASE_ARBITRUM_OUTBOX_ADDRESS",
+		"BASE_OPSTACK_OUTBOX_ADDRESS",
+		"BASE_INBOX_ADDRESS",
+		"ARBITRUM_OPSTACK_OUTBOX_ADDRESS",
+		"ARBITRUM_INBOX_ADDRESS",
+	} {
+		t.Setenv(name, "0x3542dd26727844524ea7c136c5c38ff8088b30ba")
+	}
+
+	// Every profile ships a config file that loads
+	for _, profile := range Profiles {
+		t.Run(profile, func(t *testing.T) {
+			path, err := ProfilePath("../../cmd/config", profile)
+			require.NoError(t, err)
+
+			_, err = Unmarshal(zap.NewNop(), path)
+			require.NoError(t, err)
+		})
+	}
+}
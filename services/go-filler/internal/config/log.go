@@ -0,0 +1,84 @@
+package config
+
+import (
+	"net/url"
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// MarshalLogObject logs the config without secrets: private keys are omitted and node URLs,
+// which often embed API keys, are reduced to their scheme and host
+func (c *Config) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	names := make([]string, 0, len(c.Chain))
+	for name := range c.Chain {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	err := enc.AddObject("chain", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		for _, name := range names {
+			chain := c.Chain[name]
+			if err := enc.AddObject(name, &chain); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	enc.AddString("from_address", c.Wallets.FromAddress)
+	enc.AddString("recipient_address", c.Wallets.RecipientAddress)
+	enc.AddBool("shadow", c.Shadow.Enabled)
+	enc.AddBool("paymaster", c.Paymaster.Enabled)
+	enc.AddBool("user_op_batch", c.UserOpBatch.Enabled)
+	enc.AddBool("fulfill_batch", c.FulfillBatch.Enabled)
+	enc.AddBool("reconcile", c.Reconcile.Enabled)
+	enc.AddBool("market", c.Market.Enabled)
+	enc.AddBool("risk", c.Risk.Enabled)
+	enc.AddBool("policy", c.Policy.Enabled)
+	enc.AddBool("screening", c.Screening.Enabled)
+	enc.AddBool("decision_log", c.DecisionLog.Enabled)
+	enc.AddString("proof_cache_dir", c.Proof.CacheDir)
+	enc.AddInt("proof_rpc_attempts", c.Proof.RPC.Attempts)
+	enc.AddDuration("proof_rpc_timeout", c.Proof.RPC.Timeout)
+
+	return nil
+}
+
+func (c *ChainConfig) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddUint64("chain_id", c.ChainID)
+	enc.AddString("node_url", redactURL(c.NodeURL))
+	if c.Devnet {
+		enc.AddBool("devnet", true)
+	}
+
+	for proverType, address := range c.OutboxAddresses {
+		enc.AddString("outbox_"+proverType, address.Hex())
+	}
+
+	if c.IsDestination() {
+		enc.AddString("inbox_address", c.InboxAddress.Hex())
+		enc.AddString("entrypoint_address", c.EntrypointAddress.Hex())
+		enc.AddString("l2_oracle", c.L2Oracle.Hex())
+		enc.AddString("gas_strategy", c.GasStrategy.Name())
+		if c.ProverType != "" {
+			enc.AddString("prover_type", c.ProverType)
+		}
+		if c.L1ChainID != 0 {
+			enc.AddUint64("l1_chain_id", c.L1ChainID)
+		}
+	}
+
+	return nil
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "[redacted]"
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const schemeSeparator = "://"
+
+// SecretProvider resolves the reference part of a "<scheme>://<reference>" config value
+type SecretProvider interface {
+	Resolve(ref string) (string, error)
+}
+
+// SecretProviderFunc adapts a function to the SecretProvider interface
+type SecretProviderFunc func(ref string) (string, error)
+
+func (f SecretProviderFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// EnvSecretProvider resolves env://NAME references from the process environment
+type EnvSecretProvider struct{}
+
+func (EnvSecretProvider) Resolve(ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return value, nil
+}
+
+// FileSecretProvider resolves file://PATH references from mounted secret files
+type FileSecretProvider struct{}
+
+func (FileSecretProvider) Resolve(ref string) (string, error) {
+	content, err := os.ReadFile(ref)
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// SecretResolver resolves config values referencing a registered scheme. Values without a
+// registered scheme, such as wss:// node URLs, are left untouched.
+type SecretResolver struct {
+	providers map[string]SecretProvider
+}
+
+// NewSecretResolver creates a SecretResolver supporting the env:// and file:// schemes
+func NewSecretResolver() *SecretResolver {
+	return &SecretResolver{
+		providers: map[string]SecretProvider{
+			"env":  EnvSecretProvider{},
+			"file": FileSecretProvider{},
+		},
+	}
+}
+
+// Register adds or replaces the provider of a scheme
+func (r *SecretResolver) Register(scheme string, provider SecretProvider) {
+	r.providers[scheme] = provider
+}
+
+func (r *SecretResolver) Resolve(value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, schemeSeparator)
+	if !ok {
+		return value, nil
+	}
+
+	provider, ok := r.providers[scheme]
+	if !ok {
+		return value, nil
+	}
+
+	resolved, err := provider.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s secret %q: %w", scheme, ref, err)
+	}
+	return resolved, nil
+}
+
+// decodeHook resolves every string value before it is decoded into the config
+func (r *SecretResolver) decodeHook() mapstructure.DecodeHookFuncType {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+
+		return r.Resolve(data.(string))
+	}
+}
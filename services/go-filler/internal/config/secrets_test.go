@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Well known hardhat account #0, never holds real funds
+const (
+	testPrivateKey  = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	testFromAddress = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+)
+
+func TestSecretResolver(t *testing.T) {
+	resolver := NewSecretResolver()
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("FILLER_TEST_SECRET", "from-env")
+
+		value, err := resolver.Resolve("env://FILLER_TEST_SECRET")
+		require.NoError(t, err)
+		require.Equal(t, "from-env", value)
+	})
+
+	t.Run("missing env", func(t *testing.T) {
+		_, err := resolver.Resolve("env://FILLER_TEST_MISSING_SECRET")
+		require.ErrorContains(t, err, "FILLER_TEST_MISSING_SECRET is not set")
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "secret")
+		require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+		value, err := resolver.Resolve("file://" + path)
+		require.NoError(t, err)
+		require.Equal(t, "from-file", value)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := resolver.Resolve("file://" + filepath.Join(t.TempDir(), "missing"))
+		require.ErrorContains(t, err, "reading secret file")
+	})
+
+	t.Run("unregistered scheme", func(t *testing.T) {
+		value, err := resolver.Resolve("wss://example.com")
+		require.NoError(t, err)
+		require.Equal(t, "wss://example.com", value)
+	})
+
+	t.Run("plain value", func(t *testing.T) {
+		value, err := resolver.Resolve(testFromAddress)
+		require.NoError(t, err)
+		require.Equal(t, testFromAddress, value)
+	})
+
+	t.Run("custom provider", func(t *testing.T) {
+		custom := NewSecretResolver()
+		custom.Register("vault", SecretProviderFunc(func(ref string) (string, error) {
+			return strings.ToUpper(ref), nil
+		}))
+
+		value, err := custom.Resolve("vault://secret/filler")
+		require.NoError(t, err)
+		require.Equal(t, "SECRET/FILLER", value)
+	})
+}
+
+func TestUnmarshalResolvesSecrets(t *testing.T) {
+	const content = `chain:
+  sepolia:
+    chain-id: 11155111
+    node-url: env://FILLER_TEST_NODE_URL
+wallets:
+  from-address: env://FILLER_TEST_FROM_ADDRESS
+  private-key: file://%s
+  recipient-address: env://FILLER_TEST_FROM_ADDRESS
+`
+
+	keyPath := filepath.Join(t.TempDir(), "private-key")
+	require.NoError(t, os.WriteFile(keyPath, []byte(testPrivateKey), 0o600))
+	t.Setenv("FILLER_TEST_NODE_URL", "https://rpc.example.com/v2/api-key")
+	t.Setenv("FILLER_TEST_FROM_ADDRESS", testFromAddress)
+
+	path := writeConfig(t, strings.Replace(content, "%s", keyPath, 1))
+
+	var logs bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&logs), zapcore.InfoLevel)
+	cfg, err := Unmarshal(zap.New(core), path)
+	require.NoError(t, err)
+	require.Equal(t, "https://rpc.example.com/v2/api-key", cfg.Chain["sepolia"].NodeURL)
+	require.Equal(t, common.HexToAddress(testFromAddress), cfg.Wallets.GetFromAddress())
+
+	privateKey, err := cfg.Wallets.GetPrivateKey()
+	require.NoError(t, err)
+	require.NotNil(t, privateKey)
+
+	// Neither the private key nor the node URL credentials may reach the logs
+	require.Contains(t, logs.String(), "rpc.example.com")
+	require.NotContains(t, logs.String(), strings.TrimPrefix(testPrivateKey, "0x"))
+	require.NotContains(t, logs.String(), "api-key")
+}
+
+func TestValidateWallets(t *testing.T) {
+	tests := []struct {
+		name    string
+		wallets WalletConfig
+		wantErr string
+	}{
+		{
+			name:    "no wallets",
+			wallets: WalletConfig{},
+			wantErr: "from-address is required",
+		},
+		{
+			name:    "missing private key",
+			wallets: WalletConfig{FromAddress: testFromAddress},
+			wantErr: "private-key is required",
+		},
+		{
+			name:    "matching key",
+			wallets: WalletConfig{FromAddress: testFromAddress, PrivateKey: testPrivateKey},
+		},
+		{
+			name:    "unresolved address",
+			wallets: WalletConfig{FromAddress: "env://WALLET_ADDRESS", PrivateKey: testPrivateKey},
+			wantErr: "from-address",
+		},
+		{
+			name:    "invalid key",
+			wallets: WalletConfig{FromAddress: testFromAddress, PrivateKey: "0x1234"},
+			wantErr: "invalid private key",
+		},
+		{
+			name:    "mismatched key",
+			wallets: WalletConfig{FromAddress: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", PrivateKey: testPrivateKey},
+			wantErr: "does not match from-address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.wallets.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"slices"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Validate checks the config for inconsistencies that would only surface once requests are processed
+func (c *Config) Validate() error {
+	var errs []error
+
+	if len(c.Chain) == 0 {
+		errs = append(errs, errors.New("no chain configured"))
+	}
+
+	names := make([]string, 0, len(c.Chain))
+	for name := range c.Chain {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	chainNames := make(map[uint64]string, len(c.Chain))
+	for _, name := range names {
+		chain := c.Chain[name]
+
+		if other, ok := chainNames[chain.ChainID]; ok {
+			errs = append(errs, fmt.Errorf("chain %s: duplicate chain-id %d, already used by chain %s", name, chain.ChainID, other))
+		} else {
+			chainNames[chain.ChainID] = name
+		}
+
+		if err := chain.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("chain %s: %w", name, err))
+		}
+	}
+
+	for _, name := range names {
+		chain := c.Chain[name]
+		if !RequiresL1(chain.ProverType) {
+			continue
+		}
+		if _, ok := chainNames[chain.L1ChainID]; !ok {
+			errs = append(errs, fmt.Errorf("chain %s: l1-chain-id %d is not a configured chain", name, chain.L1ChainID))
+		}
+	}
+
+	if err := c.Wallets.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("wallets: %w", err))
+	}
+
+	if err := c.Paymaster.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("paymaster: %w", err))
+	}
+
+	if err := c.UserOpBatch.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("user-op-batch: %w", err))
+	}
+
+	if err := c.FulfillBatch.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("fulfill-batch: %w", err))
+	}
+
+	if err := c.Reconcile.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("reconcile: %w", err))
+	}
+
+	if err := c.Market.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("market: %w", err))
+	}
+
+	if err := c.Risk.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("risk: %w", err))
+	}
+
+	if err := c.Policy.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("policy: %w", err))
+	}
+
+	if err := c.Screening.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("screening: %w", err))
+	}
+
+	if err := c.DecisionLog.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("decision-log: %w", err))
+	}
+
+	if err := c.Proof.RPC.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("proof: rpc: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the wallet entries once their secrets have been resolved. The from-address and private-key the
+// fulfillments are signed with are required.
+func (c *WalletConfig) Validate() error {
+	var errs []error
+
+	if c.FromAddress == "" {
+		errs = append(errs, errors.New("from-address is required"))
+	} else if _, err := parseAddress(c.FromAddress); err != nil {
+		errs = append(errs, fmt.Errorf("from-address: %w", err))
+	}
+
+	if c.RecipientAddress != "" {
+		if _, err := parseAddress(c.RecipientAddress); err != nil {
+			errs = append(errs, fmt.Errorf("recipient-address: %w", err))
+		}
+	}
+
+	if c.PrivateKey == "" {
+		errs = append(errs, errors.New("private-key is required"))
+	} else {
+		privateKey, err := c.GetPrivateKey()
+		if err != nil {
+			// The parsing error is not wrapped, it could echo part of the key
+			errs = append(errs, errors.New("private-key: invalid private key"))
+		} else if c.FromAddress != "" && crypto.PubkeyToAddress(privateKey.PublicKey) != c.GetFromAddress() {
+			errs = append(errs, errors.New("private-key does not match from-address"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the balance thresholds when the paymaster balances are managed
+func (c *PaymasterConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.PollInterval < 0 {
+		errs = append(errs, errors.New("negative poll-interval"))
+	}
+	if err := c.Gas.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("gas: %w", err))
+	}
+	if err := c.MagicSpend.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("magic-spend: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the batch bounds when batching is enabled
+func (c *BatchConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.MaxSize < 1 {
+		errs = append(errs, errors.New("max-size must be at least 1"))
+	}
+	if c.MaxDelay <= 0 {
+		errs = append(errs, errors.New("max-delay must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the window and the outbid settings when the market watcher is enabled
+func (c *MarketConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.Window < 1 {
+		errs = append(errs, errors.New("window must be at least 1"))
+	}
+	if c.OutbidPercentile < 0 || c.OutbidPercentile > 100 {
+		errs = append(errs, errors.New("outbid-percentile must be between 0 and 100"))
+	}
+	if c.OutbidPremium < 0 {
+		errs = append(errs, errors.New("negative outbid-premium"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the exposure store and the caps when risk limits are enabled
+func (c *RiskConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.StorePath == "" {
+		errs = append(errs, errors.New("missing store-path"))
+	}
+	limits := []struct {
+		name  string
+		limit *big.Int
+	}{
+		{"max-request-value", c.MaxRequestValue},
+		{"max-chain-exposure", c.MaxChainExposure},
+		{"max-total-exposure", c.MaxTotalExposure},
+	}
+	for _, l := range limits {
+		if l.limit != nil && l.limit.Sign() < 0 {
+			errs = append(errs, fmt.Errorf("negative %s", l.name))
+		}
+	}
+	if c.RequesterLimit < 0 {
+		errs = append(errs, errors.New("negative requester-limit"))
+	}
+	if c.RequesterLimit > 0 && c.RequesterWindow <= 0 {
+		errs = append(errs, errors.New("requester-window must be positive when requester-limit is set"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the list entries are hex values of the expected sizes
+func (c *PolicyConfig) Validate() error {
+	var errs []error
+	if c.MaxCalls < 0 {
+		errs = append(errs, errors.New("negative max-calls"))
+	}
+	if err := c.Requesters.validate(common.AddressLength, common.HashLength); err != nil {
+		errs = append(errs, fmt.Errorf("requesters: %w", err))
+	}
+	if err := c.Targets.validate(common.AddressLength, common.HashLength); err != nil {
+		errs = append(errs, fmt.Errorf("targets: %w", err))
+	}
+	if err := c.Selectors.validate(4); err != nil {
+		errs = append(errs, fmt.Errorf("selectors: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the path and the rotation bounds when the decision log is enabled
+func (c *DecisionLogConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.Path == "" {
+		errs = append(errs, errors.New("missing path"))
+	}
+	if c.MaxSizeMB < 0 {
+		errs = append(errs, errors.New("negative max-size-mb"))
+	}
+	if c.MaxFiles < 0 {
+		errs = append(errs, errors.New("negative max-files"))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *RetryConfig) Validate() error {
+	var errs []error
+	if c.Attempts < 0 {
+		errs = append(errs, errors.New("negative attempts"))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, errors.New("negative timeout"))
+	}
+	if c.InitialBackoff < 0 || c.MaxBackoff < 0 {
+		errs = append(errs, errors.New("negative backoff"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the audit log and the settings of the selected screener when screening is enabled
+func (c *ScreeningConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.AuditPath == "" {
+		errs = append(errs, errors.New("missing audit-path"))
+	}
+	switch c.Type {
+	case ScreeningFile:
+		if c.File.Path == "" {
+			errs = append(errs, errors.New("missing file path"))
+		}
+		if c.File.ReloadInterval < 0 {
+			errs = append(errs, errors.New("negative file reload-interval"))
+		}
+	case ScreeningHTTP:
+		if u, err := url.Parse(c.HTTP.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid http url %q", c.HTTP.URL))
+		}
+		if c.HTTP.Timeout < 0 {
+			errs = append(errs, errors.New("negative http timeout"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown type %q, want %s or %s", c.Type, ScreeningFile, ScreeningHTTP))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *ListConfig) validate(sizes ...int) error {
+	var errs []error
+	for _, entry := range slices.Concat(c.Allow, c.Deny) {
+		value, err := hexutil.Decode(entry)
+		if err != nil || !slices.Contains(sizes, len(value)) {
+			errs = append(errs, fmt.Errorf("invalid entry %q", entry))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the store is set when reconciliation is enabled
+func (c *ReconcileConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if c.StorePath == "" {
+		errs = append(errs, errors.New("missing store-path"))
+	}
+	if c.ReportInterval < 0 {
+		errs = append(errs, errors.New("negative report-interval"))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *BalanceThresholds) Validate() error {
+	switch {
+	case c.MinBalance == nil || c.TargetBalance == nil:
+		return errors.New("missing min-balance or target-balance")
+	case c.MinBalance.Sign() < 0:
+		return errors.New("negative min-balance")
+	case c.TargetBalance.Cmp(c.MinBalance) < 0:
+		return errors.New("target-balance is below min-balance")
+	case c.MaxBalance != nil && c.MaxBalance.Cmp(c.TargetBalance) < 0:
+		return errors.New("max-balance is below target-balance")
+	}
+	return nil
+}
+
+// Validate checks a single chain entry. A chain configuring any of the inbox, entrypoint or
+// l2 oracle entries is treated as a destination chain and must configure all of them.
+func (c *ChainConfig) Validate() error {
+	var errs []error
+
+	if c.ChainID == 0 {
+		errs = append(errs, errors.New("missing chain-id"))
+	}
+
+	if c.NodeURL == "" {
+		errs = append(errs, errors.New("missing node-url"))
+	}
+
+	for proverType := range c.OutboxAddresses {
+		if !slices.Contains(ProverTypes, proverType) {
+			errs = append(errs, fmt.Errorf("outbox-addresses: unknown prover type %q, want one of %v", proverType, ProverTypes))
+		}
+	}
+
+	if c.IsDestination() {
+		if c.InboxAddress == (common.Address{}) {
+			errs = append(errs, errors.New("destination chain is missing inbox-address"))
+		}
+		if c.EntrypointAddress == (common.Address{}) {
+			errs = append(errs, errors.New("destination chain is missing entrypoint-address"))
+		}
+		if c.L2Oracle == (common.Address{}) {
+			errs = append(errs, errors.New("destination chain is missing l2-oracle"))
+		}
+		if c.L2OracleStorageKey == "" {
+			errs = append(errs, errors.New("destination chain is missing l2-oracle-storage-key"))
+		}
+	}
+
+	if c.L2OracleStorageKey != "" {
+		if key, err := hexutil.Decode(c.L2OracleStorageKey); err != nil || len(key) != common.HashLength {
+			errs = append(errs, fmt.Errorf("invalid l2-oracle-storage-key %q", c.L2OracleStorageKey))
+		}
+	}
+
+	if c.ProverType != "" {
+		switch {
+		case !slices.Contains(ProverTypes, c.ProverType):
+			errs = append(errs, fmt.Errorf("unknown prover-type %q, want one of %v", c.ProverType, ProvableTypes))
+		case !slices.Contains(ProvableTypes, c.ProverType):
+			errs = append(errs, fmt.Errorf("prover-type %q has no prover implemented, want one of %v", c.ProverType, ProvableTypes))
+		}
+		if !c.IsDestination() {
+			errs = append(errs, errors.New("prover-type is set on a chain that is not a destination"))
+		}
+	}
+
+	if c.MinClaimLatency < 0 {
+		errs = append(errs, errors.New("negative min-claim-latency"))
+	}
+
+	if err := c.GasStrategy.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("gas-strategy: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the settings of the selected gas strategy
+func (c *GasStrategyConfig) Validate() error {
+	switch c.Type {
+	case "", GasStrategyNode:
+	case GasStrategyFeeHistory:
+		if c.Percentile < 0 || c.Percentile > 100 {
+			return errors.New("percentile must be between 0 and 100")
+		}
+		if c.BlockCount == 0 {
+			return errors.New("block-count must be at least 1")
+		}
+	case GasStrategyMargin:
+		// A bid of the whole profit would leave the request unprofitable
+		if c.ProfitShare < 0 || c.ProfitShare >= 1 {
+			return errors.New("profit-share must be at least 0 and below 1")
+		}
+	default:
+		return fmt.Errorf("unknown type %q, want one of %v", c.Type, GasStrategies)
+	}
+	return nil
+}
+
+// IsDestination reports whether requests can be fulfilled on the chain
+func (c *ChainConfig) IsDestination() bool {
+	return c.InboxAddress != (common.Address{}) ||
+		c.EntrypointAddress != (common.Address{}) ||
+		c.L2Oracle != (common.Address{}) ||
+		c.L2OracleStorageKey != ""
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Watcher reloads a config file when it changes on disk or when the process receives SIGHUP
+type Watcher struct {
+	log      *zap.Logger
+	path     string
+	onReload func(*Config) error
+}
+
+// NewWatcher creates a Watcher calling onReload with every new config that passes validation
+func NewWatcher(log *zap.Logger, path string, onReload func(*Config) error) *Watcher {
+	return &Watcher{
+		log:      log,
+		path:     path,
+		onReload: onReload,
+	}
+}
+
+// Run watches the config file until ctx is done. Reloads are handled one at a time.
+func (w *Watcher) Run(ctx context.Context) error {
+	changes := make(chan struct{}, 1)
+
+	v := viper.New()
+	v.SetConfigFile(w.path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+	v.OnConfigChange(func(fsnotify.Event) {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	})
+	v.WatchConfig()
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-changes:
+			w.log.Info("Config file changed, reloading", zap.String("path", w.path))
+			_ = w.Reload()
+		case <-sighup:
+			w.log.Info("Received SIGHUP, reloading config", zap.String("path", w.path))
+			_ = w.Reload()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Reload reads and validates the config file and passes it to onReload. An invalid config is logged and the
+// running config is kept.
+func (w *Watcher) Reload() error {
+	cfg, err := Unmarshal(w.log, w.path)
+	if err != nil {
+		w.log.Error("Ignoring invalid config reload", zap.String("path", w.path), zap.Error(err))
+		return err
+	}
+
+	if err := w.onReload(cfg); err != nil {
+		w.log.Error("Applying reloaded config", zap.String("path", w.path), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWatcherReload(t *testing.T) {
+	const chainTemplate = `chain:
+  sepolia:
+    chain-id: %d
+    node-url: wss://example.com
+`
+	path := writeConfig(t, fmt.Sprintf(chainTemplate, 11155111)+testWallets)
+
+	var reloaded []*Config
+	w := NewWatcher(zap.NewNop(), path, func(cfg *Config) error {
+		reloaded = append(reloaded, cfg)
+		return nil
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(chainTemplate, 84532)+testWallets), 0o600))
+	require.NoError(t, w.Reload())
+	require.Len(t, reloaded, 1)
+	require.Equal(t, uint64(84532), reloaded[0].Chain["sepolia"].ChainID)
+
+	// An invalid file is rejected before reaching onReload
+	require.NoError(t, os.WriteFile(path, []byte("chain: {}\n"+testWallets), 0o600))
+	require.ErrorContains(t, w.Reload(), "no chain configured")
+	require.Len(t, reloaded, 1)
+}
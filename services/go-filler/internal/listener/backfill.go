@@ -0,0 +1,85 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"go.uber.org/zap"
+)
+
+// Backfill replays MessagePosted events emitted by the outboxes of a source chain
+// between the from and to blocks (inclusive) through the regular processing pipeline
+func (l *OutboxListener) Backfill(ctx context.Context, chainID uint64, from uint64, to uint64) error {
+	if from > to {
+		return fmt.Errorf("invalid block range, from: %d, to: %d", from, to)
+	}
+
+	chain, err := l.clientMgr.GetChainClient(chainID)
+	if err != nil {
+		return err
+	}
+
+	for start := from; start <= to; start += backfillBlockRange {
+		end := min(start+backfillBlockRange-1, to)
+
+		events, err := FilterMessagePosted(ctx, chain, &bind.FilterOpts{Start: start, End: &end, Context: ctx}, nil)
+		if err != nil {
+			return err
+		}
+
+		l.logger.Info(
+			"Backfilling message posted logs",
+			zap.Uint64("chain_id", chainID),
+			zap.Uint64("from_block", start),
+			zap.Uint64("to_block", end),
+			zap.Int("events", len(events)),
+		)
+
+		for _, event := range events {
+			err := l.processMessagePosted(ctx, chain, event)
+			if err != nil {
+				l.logger.Error("Processing backfilled message posted", zap.Error(err))
+			}
+			l.trackRequest(event, err)
+		}
+	}
+
+	return nil
+}
+
+// FilterMessagePosted returns the MessagePosted events emitted by every outbox configured on
+// the chain within opts, optionally restricted to the given message IDs
+func FilterMessagePosted(
+	ctx context.Context,
+	chain *client.ChainClient,
+	opts *bind.FilterOpts,
+	messageIDs [][32]byte,
+) ([]*rrc_7755_outbox.RRC7755OutboxMessagePosted, error) {
+	var events []*rrc_7755_outbox.RRC7755OutboxMessagePosted
+
+	for _, address := range chain.Config.OutboxAddresses {
+		outbox, err := rrc_7755_outbox.NewRRC7755OutboxFilterer(address, chain.Client)
+		if err != nil {
+			return nil, fmt.Errorf("creating outbox contract on chain %d: %w", chain.Config.ChainID, err)
+		}
+
+		it, err := outbox.FilterMessagePosted(opts, messageIDs)
+		if err != nil {
+			return nil, fmt.Errorf("filtering message posted logs on chain %d: %w", chain.Config.ChainID, err)
+		}
+
+		for it.Next() {
+			events = append(events, it.Event)
+		}
+		if err := it.Error(); err != nil {
+			it.Close()
+			return nil, fmt.Errorf("iterating message posted logs on chain %d: %w", chain.Config.ChainID, err)
+		}
+		it.Close()
+	}
+
+	return events, nil
+}
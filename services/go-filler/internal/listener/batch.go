@@ -0,0 +1,211 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/bindings/entrypoint"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+type pendingUserOp struct {
+	messageID [32]byte
+	op        *abi.PackedUserOperation
+}
+
+// UserOpOutcome is the result of a batched UserOp, attributed back to its request from the UserOperationEvent logs
+type UserOpOutcome struct {
+	MessageID [32]byte
+	TxHash    common.Hash
+	// Mined is set once the receipt of the batch was read, whether it succeeded or reverted
+	Mined bool
+	// Included is false when the op was dropped by the pre-send simulation or missing from the receipt
+	Included      bool
+	Success       bool
+	ActualGasCost *big.Int
+	ActualGasUsed *big.Int
+	Err           error
+}
+
+// userOpBatcher groups the UserOps of each destination chain into handleOps transactions
+type userOpBatcher struct {
+	listener *OutboxListener
+	queue    *batchQueue[pendingUserOp]
+}
+
+func newUserOpBatcher(l *OutboxListener, cfg config.BatchConfig) *userOpBatcher {
+	b := &userOpBatcher{listener: l}
+	b.queue = newBatchQueue(cfg, func(ctx context.Context, destChain *client.ChainClient, batch []pendingUserOp) {
+		b.sendBatch(ctx, destChain, batch)
+	})
+	return b
+}
+
+// Add queues op for its destination chain. The batch is sent right away once full, otherwise after the max delay.
+func (b *userOpBatcher) Add(destChain *client.ChainClient, op pendingUserOp) {
+	size := b.queue.Add(destChain, op)
+
+	b.listener.logger.Info("Queued user op",
+		zap.String("message_id", common.Hash(op.messageID).Hex()),
+		zap.Uint64("chain_id", destChain.Config.ChainID),
+		zap.Int("batch_size", size),
+	)
+}
+
+// Close sends the pending batches and waits for every batch to be sent
+func (b *userOpBatcher) Close(ctx context.Context) {
+	b.queue.Close(ctx)
+}
+
+// sendBatch drops the ops canceled while queued, simulates every other op on its own, sends the ones that pass in a
+// single handleOps and attributes the UserOperationEvent logs of the receipt back to their message IDs
+func (b *userOpBatcher) sendBatch(ctx context.Context, destChain *client.ChainClient, batch []pendingUserOp) []UserOpOutcome {
+	l := b.listener
+	entrypointAddress := destChain.Config.EntrypointAddress
+
+	outcomes := make([]UserOpOutcome, 0, len(batch))
+	ops := make([]abi.PackedUserOperation, 0, len(batch))
+	included := make(map[string]int, len(batch))
+
+	for _, item := range batch {
+		if l.isCanceled(item.messageID) {
+			outcomes = append(outcomes, UserOpOutcome{MessageID: item.messageID, Err: errMessageCanceled})
+			continue
+		}
+
+		call, err := l.createHandleOpsCallMsg(entrypointAddress, []abi.PackedUserOperation{*item.op})
+		if err == nil {
+			err = simulateHandleOps(ctx, destChain, call)
+		}
+		if err != nil {
+			outcomes = append(outcomes, UserOpOutcome{MessageID: item.messageID, Err: err})
+			continue
+		}
+
+		included[userOpKey(item.op.Sender, item.op.Nonce)] = len(outcomes)
+		outcomes = append(outcomes, UserOpOutcome{MessageID: item.messageID})
+		ops = append(ops, *item.op)
+	}
+
+	if len(ops) > 0 {
+		if err := b.sendOps(ctx, destChain, ops, outcomes, included); err != nil {
+			for _, i := range included {
+				outcomes[i].Err = err
+			}
+		}
+	}
+
+	for _, outcome := range outcomes {
+		fields := []zap.Field{
+			zap.String("message_id", common.Hash(outcome.MessageID).Hex()),
+			zap.Uint64("chain_id", destChain.Config.ChainID),
+			zap.String("tx_hash", outcome.TxHash.Hex()),
+			zap.Bool("included", outcome.Included),
+			zap.Bool("success", outcome.Success),
+		}
+		if outcome.ActualGasCost != nil {
+			fields = append(fields, zap.Stringer("actual_gas_cost", outcome.ActualGasCost))
+		}
+
+		switch {
+		case outcome.Err == nil && outcome.Success:
+			l.logger.Info("Batched user op fulfilled", fields...)
+		case outcome.TxHash != (common.Hash{}) && !outcome.Mined:
+			// The batch was broadcast but its receipt is unknown, it may still fulfill the request so the exposure is
+			// kept until the request is claimed
+			l.logger.Warn("Batched user op pending", append(fields, zap.Error(outcome.Err))...)
+		default:
+			l.logger.Error("Batched user op failed", append(fields, zap.Error(outcome.Err))...)
+			l.releaseExposure(outcome.MessageID)
+		}
+	}
+
+	return outcomes
+}
+
+func (b *userOpBatcher) sendOps(
+	ctx context.Context,
+	destChain *client.ChainClient,
+	ops []abi.PackedUserOperation,
+	outcomes []UserOpOutcome,
+	included map[string]int,
+) error {
+	l := b.listener
+
+	call, err := l.createHandleOpsCallMsg(destChain.Config.EntrypointAddress, ops)
+	if err != nil {
+		return err
+	}
+
+	gasLimitAndPrice, err := l.getGasLimitAndPrice(ctx, destChain, call, nil)
+	if err != nil {
+		return fmt.Errorf("getting gas limit and price: %w", err)
+	}
+
+	tx, err := l.SendTransaction(ctx, destChain, call, gasLimitAndPrice)
+	if err != nil {
+		return fmt.Errorf("sending handleOps batch: %w", err)
+	}
+	for _, i := range included {
+		outcomes[i].TxHash = tx.Hash()
+	}
+
+	receipt, err := bind.WaitMined(ctx, destChain.Client, tx)
+	if err != nil {
+		return fmt.Errorf("waiting for handleOps batch: %w", err)
+	}
+	for _, i := range included {
+		outcomes[i].Mined = true
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("handleOps batch %s reverted", tx.Hash().Hex())
+	}
+
+	filterer, err := entrypoint.NewEntrypointFilterer(destChain.Config.EntrypointAddress, destChain.Client)
+	if err != nil {
+		return fmt.Errorf("binding entrypoint: %w", err)
+	}
+
+	userOpEventID := entrypointUserOperationEventID()
+	for _, log := range receipt.Logs {
+		if log.Address != destChain.Config.EntrypointAddress || len(log.Topics) == 0 || log.Topics[0] != userOpEventID {
+			continue
+		}
+
+		event, err := filterer.ParseUserOperationEvent(*log)
+		if err != nil {
+			return fmt.Errorf("parsing UserOperationEvent: %w", err)
+		}
+
+		i, ok := included[userOpKey(event.Sender, event.Nonce)]
+		if !ok {
+			continue
+		}
+		outcomes[i].Included = true
+		outcomes[i].Success = event.Success
+		outcomes[i].ActualGasCost = event.ActualGasCost
+		outcomes[i].ActualGasUsed = event.ActualGasUsed
+	}
+
+	return nil
+}
+
+// userOpKey identifies an op within a batch, an account can't have two ops with the same nonce executed
+func userOpKey(sender common.Address, nonce *big.Int) string {
+	return sender.Hex() + "/" + nonce.String()
+}
+
+func entrypointUserOperationEventID() common.Hash {
+	entrypointAbi, err := entrypoint.EntrypointMetaData.GetAbi()
+	if err != nil {
+		return common.Hash{}
+	}
+	return entrypointAbi.Events["UserOperationEvent"].ID
+}
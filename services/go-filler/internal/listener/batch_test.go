@@ -0,0 +1,273 @@
+package listener
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/base-org/RRC-7755-poc/bindings/entrypoint"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/risk"
+)
+
+func TestUserOpBatcherAdd(t *testing.T) {
+	l := &OutboxListener{logger: zaptest.NewLogger(t)}
+	b := newUserOpBatcher(l, config.BatchConfig{Enabled: true, MaxSize: 2, MaxDelay: time.Hour})
+
+	var (
+		mu      sync.Mutex
+		batches [][]pendingUserOp
+	)
+	b.queue.flush = func(ctx context.Context, destChain *client.ChainClient, batch []pendingUserOp) {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, batch)
+	}
+
+	destChain := &client.ChainClient{Config: config.ChainConfig{ChainID: testDestChainID}}
+	otherChain := &client.ChainClient{Config: config.ChainConfig{ChainID: testDestChainID + 1}}
+
+	b.Add(destChain, pendingUserOp{messageID: [32]byte{1}})
+	b.Add(otherChain, pendingUserOp{messageID: [32]byte{2}})
+	b.Add(destChain, pendingUserOp{messageID: [32]byte{3}})
+
+	// The full batch is sent right away, the other chain waits for its delay or Close
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(batches) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, batches[0], 2)
+
+	b.Close(context.Background())
+	require.Len(t, batches, 2)
+	require.Equal(t, [32]byte{2}, batches[1][0].messageID)
+}
+
+func TestUserOpBatcherMaxDelay(t *testing.T) {
+	l := &OutboxListener{logger: zaptest.NewLogger(t)}
+	b := newUserOpBatcher(l, config.BatchConfig{Enabled: true, MaxSize: 10, MaxDelay: 10 * time.Millisecond})
+
+	sent := make(chan []pendingUserOp, 1)
+	b.queue.flush = func(ctx context.Context, destChain *client.ChainClient, batch []pendingUserOp) {
+		sent <- batch
+	}
+
+	destChain := &client.ChainClient{Config: config.ChainConfig{ChainID: testDestChainID}}
+	b.Add(destChain, pendingUserOp{messageID: [32]byte{1}})
+
+	select {
+	case batch := <-sent:
+		require.Len(t, batch, 1)
+	case <-time.After(time.Second):
+		t.Fatal("batch was not sent after its max delay")
+	}
+}
+
+func TestListenerCloseSendsPendingBatches(t *testing.T) {
+	l := &OutboxListener{logger: zaptest.NewLogger(t)}
+	l.batcher = newUserOpBatcher(l, config.BatchConfig{Enabled: true, MaxSize: 10, MaxDelay: time.Hour})
+
+	var sent []pendingUserOp
+	l.batcher.queue.flush = func(ctx context.Context, destChain *client.ChainClient, batch []pendingUserOp) {
+		// The batch is not sent under a canceled context, even though the requests that filled it are done
+		require.NoError(t, ctx.Err())
+		sent = append(sent, batch...)
+	}
+
+	// Backfill returns with the batch still pending, closing the listener sends it
+	destChain := &client.ChainClient{Config: config.ChainConfig{ChainID: testDestChainID}}
+	l.batcher.Add(destChain, pendingUserOp{messageID: [32]byte{1}})
+	require.NoError(t, l.Close())
+	require.Len(t, sent, 1)
+}
+
+func TestUserOpBatcherSendBatchAttributesOutcomes(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	entrypointAddress := common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032")
+	senderA := common.HexToAddress("0xaaaa")
+	senderB := common.HexToAddress("0xbbbb")
+	senderC := common.HexToAddress("0xcccc")
+
+	entrypointAbi, err := entrypoint.EntrypointMetaData.GetAbi()
+	require.NoError(t, err)
+	userOpEvent := entrypointAbi.Events["UserOperationEvent"]
+	eventLog := func(sender common.Address, success bool) *types.Log {
+		data, err := userOpEvent.Inputs.NonIndexed().Pack(big.NewInt(0), success, big.NewInt(1000), big.NewInt(500))
+		require.NoError(t, err)
+		return &types.Log{
+			Address: entrypointAddress,
+			Topics:  []common.Hash{userOpEvent.ID, {}, common.BytesToHash(sender.Bytes()), {}},
+			Data:    data,
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	ethClient := mocks.NewMockEthClient(ctrl)
+	ethClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
+		DoAndReturn(func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+			if bytes.Contains(msg.Data, senderB.Bytes()) {
+				return nil, revertError{}
+			}
+			return nil, nil
+		}).
+		Times(3)
+	ethClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil)
+	ethClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(1), nil)
+	ethClient.EXPECT().PendingNonceAt(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+	ethClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).Return(nil)
+	ethClient.EXPECT().
+		TransactionReceipt(gomock.Any(), gomock.Any()).
+		Return(&types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			Logs:   []*types.Log{eventLog(senderC, false), eventLog(senderA, true)},
+		}, nil)
+
+	l := &OutboxListener{
+		logger: zaptest.NewLogger(t),
+		config: &config.Config{Wallets: config.WalletConfig{
+			FromAddress: crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+			PrivateKey:  common.Bytes2Hex(crypto.FromECDSA(privateKey)),
+		}},
+	}
+	b := newUserOpBatcher(l, config.BatchConfig{Enabled: true, MaxSize: 10, MaxDelay: time.Second})
+
+	destChain := &client.ChainClient{
+		Client: ethClient,
+		Config: config.ChainConfig{ChainID: testDestChainID, EntrypointAddress: entrypointAddress},
+	}
+	newOp := func(sender common.Address) *abi.PackedUserOperation {
+		return &abi.PackedUserOperation{Sender: sender, Nonce: big.NewInt(0), PreVerificationGas: big.NewInt(0)}
+	}
+
+	outcomes := b.sendBatch(context.Background(), destChain, []pendingUserOp{
+		{messageID: [32]byte{1}, op: newOp(senderA)},
+		{messageID: [32]byte{2}, op: newOp(senderB)},
+		{messageID: [32]byte{3}, op: newOp(senderC)},
+	})
+	require.Len(t, outcomes, 3)
+
+	require.Equal(t, [32]byte{1}, outcomes[0].MessageID)
+	require.True(t, outcomes[0].Included)
+	require.True(t, outcomes[0].Success)
+	require.Equal(t, big.NewInt(1000), outcomes[0].ActualGasCost)
+	require.NotEqual(t, common.Hash{}, outcomes[0].TxHash)
+
+	require.Equal(t, [32]byte{2}, outcomes[1].MessageID)
+	require.False(t, outcomes[1].Included)
+	require.ErrorContains(t, outcomes[1].Err, "simulating handleOps")
+
+	require.Equal(t, [32]byte{3}, outcomes[2].MessageID)
+	require.True(t, outcomes[2].Included)
+	require.False(t, outcomes[2].Success)
+	require.Equal(t, outcomes[0].TxHash, outcomes[2].TxHash)
+}
+
+// newRiskTestListener returns a listener sending from a new wallet, with a risk manager holding the exposure of
+// messageID and logs observing the releases
+func newRiskTestListener(t *testing.T, messageID [32]byte) (*OutboxListener, *observer.ObservedLogs) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	cfg := &config.Config{
+		Wallets: config.WalletConfig{
+			FromAddress: crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+			PrivateKey:  common.Bytes2Hex(crypto.FromECDSA(privateKey)),
+		},
+		Risk: config.RiskConfig{Enabled: true, StorePath: filepath.Join(t.TempDir(), "exposure.jsonl")},
+	}
+	manager, err := risk.NewManager(&client.Manager{}, cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, manager.Close()) })
+	require.NoError(t, manager.Reserve(&risk.Exposure{MessageID: messageID, Amount: big.NewInt(1)}))
+
+	return &OutboxListener{logger: logger, config: cfg, risk: manager}, logs
+}
+
+func TestUserOpBatcherReleasesExposureOnlyOnceMined(t *testing.T) {
+	tests := []struct {
+		name        string
+		receipt     *types.Receipt
+		wantMined   bool
+		wantRelease bool
+	}{
+		{
+			// The batch may still be mined after the listener stopped waiting, the request may be fulfilled
+			name:        "receipt unknown",
+			wantMined:   false,
+			wantRelease: false,
+		},
+		{
+			name:        "reverted",
+			receipt:     &types.Receipt{Status: types.ReceiptStatusFailed},
+			wantMined:   true,
+			wantRelease: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageID := [32]byte{1}
+			l, logs := newRiskTestListener(t, messageID)
+
+			ctrl := gomock.NewController(t)
+			ethClient := mocks.NewMockEthClient(ctrl)
+			ethClient.EXPECT().CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).Return(nil, nil)
+			ethClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil)
+			ethClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(1), nil)
+			ethClient.EXPECT().PendingNonceAt(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+			ethClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).Return(nil)
+			if tt.receipt != nil {
+				ethClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(tt.receipt, nil)
+			} else {
+				ethClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(nil, ethereum.NotFound).AnyTimes()
+			}
+
+			b := newUserOpBatcher(l, config.BatchConfig{Enabled: true, MaxSize: 10, MaxDelay: time.Second})
+			destChain := &client.ChainClient{
+				Client: ethClient,
+				Config: config.ChainConfig{ChainID: testDestChainID, EntrypointAddress: common.HexToAddress("0x1234")},
+			}
+			op := &abi.PackedUserOperation{Sender: common.HexToAddress("0xaaaa"), Nonce: big.NewInt(0), PreVerificationGas: big.NewInt(0)}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			outcomes := b.sendBatch(ctx, destChain, []pendingUserOp{{messageID: messageID, op: op}})
+
+			require.Len(t, outcomes, 1)
+			require.NotEqual(t, common.Hash{}, outcomes[0].TxHash)
+			require.Equal(t, tt.wantMined, outcomes[0].Mined)
+			require.Error(t, outcomes[0].Err)
+			if tt.wantRelease {
+				require.Equal(t, 1, logs.FilterMessage("Released risk exposure").Len())
+			} else {
+				require.Zero(t, logs.FilterMessage("Released risk exposure").Len())
+				require.Equal(t, 1, logs.FilterMessage("Batched user op pending").Len())
+			}
+		})
+	}
+}
@@ -4,12 +4,21 @@ const (
 	// Buffer sizes
 	crossChainCallRequestedBufferSize = 10
 
+	// Number of canceled message IDs remembered
+	canceledMessagesSize = 10000
+
+	// Maximum number of blocks requested per eth_getLogs call while backfilling
+	backfillBlockRange = 1000
+
 	// Attribute selectors
 	nonceAttributeSelector     uint32 = 0xce03fdab
 	rewardAttributeSelector    uint32 = 0xa362e5db
 	delayAttributeSelector     uint32 = 0x84f550e0
 	requesterAttributeSelector uint32 = 0x3bd94e4c
 	l2OracleAttributeSelector  uint32 = 0x7ff7245a
+	precheckAttributeSelector  uint32 = 0xbef86027
+	// magicSpendRequest(address,uint256), only found in UserOp paymaster data
+	magicSpendRequestAttributeSelector uint32 = 0x92041278
 
 	// Attribute sizes
 	attributeBaseSize     = 36 // 4 + 32 (selector + data)
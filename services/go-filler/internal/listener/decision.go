@@ -0,0 +1,149 @@
+package listener
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/audit"
+	"github.com/base-org/RRC-7755-poc/internal/paymaster"
+	"github.com/base-org/RRC-7755-poc/internal/policy"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+func newDecisionRecord(event *rrc_7755_outbox.RRC7755OutboxMessagePosted, sourceChain uint64, now time.Time) *audit.DecisionRecord {
+	return &audit.DecisionRecord{
+		Timestamp:    now.UTC(),
+		MessageID:    event.MessageId,
+		SourceChain:  sourceChain,
+		SourceBlock:  event.Raw.BlockNumber,
+		SourceTxHash: event.Raw.TxHash,
+		Outbox:       event.Raw.Address,
+	}
+}
+
+// recordDecision completes the record with the outcome of the request, err being the reason it was skipped, logs it
+// and writes it to the decision log when enabled
+func (l *OutboxListener) recordDecision(rec *audit.DecisionRecord, err error) {
+	if err != nil {
+		if rec.Outcome == "" {
+			rec.Outcome = audit.OutcomeSkipped
+		}
+		rec.Reason = err.Error()
+	}
+
+	fields := []zap.Field{
+		zap.String("message_id", rec.MessageID.Hex()),
+		zap.Uint64("source_chain", rec.SourceChain),
+		zap.String("outcome", rec.Outcome),
+	}
+	if check, ok := rec.Failed(); ok {
+		fields = append(fields, zap.String("failed_check", check.Name))
+	}
+	if rec.Reason != "" {
+		fields = append(fields, zap.String("reason", rec.Reason))
+	}
+	if rec.Reward != nil {
+		fields = append(fields, zap.Stringer("expected_profit", rec.Reward.Profit))
+	}
+	if rec.TxHash != nil {
+		fields = append(fields, zap.String("tx_hash", rec.TxHash.Hex()))
+	}
+	l.logger.Info("Decision", fields...)
+
+	if l.decisions == nil {
+		return
+	}
+	if err := l.decisions.Write(rec); err != nil {
+		l.logger.Error("Writing decision record", zap.Error(err))
+	}
+}
+
+func decisionInputs(parsed *ParsedMessage) *audit.Inputs {
+	attributes := parsed.Attributes
+	if parsed.ParsedUserOp != nil {
+		attributes = parsed.UserOpAttributes
+	}
+
+	inputs := &audit.Inputs{
+		DestinationChain: parsed.DestinationChain,
+		Sender:           parsed.Sender,
+		Receiver:         parsed.Receiver,
+		Payload:          parsed.Payload,
+		RawAttributes:    make([]hexutil.Bytes, len(parsed.RawAttributes)),
+		IsUserOp:         parsed.ParsedUserOp != nil,
+		Requester:        attributes.Requester,
+		RewardAsset:      attributes.RewardAsset,
+		RewardAmount:     attributes.RewardAmount.ToBig(),
+		Expiry:           attributes.Expiry.ToBig(),
+		FinalityDelay:    attributes.FinalityDelay.ToBig(),
+		MagicSpendToken:  attributes.MagicSpendToken,
+		MagicSpendAmount: attributes.MagicSpendAmount.ToBig(),
+	}
+	for i, attribute := range parsed.RawAttributes {
+		inputs.RawAttributes[i] = attribute
+	}
+	if parsed.ParsedUserOp != nil {
+		inputs.UserOpSender = &parsed.ParsedUserOp.Sender
+	}
+
+	return inputs
+}
+
+// newPolicyOutcome is nil when the policy could not be evaluated
+func newPolicyOutcome(err error) *audit.PolicyOutcome {
+	if err == nil {
+		return &audit.PolicyOutcome{Allowed: true}
+	}
+
+	var denied *policy.DeniedError
+	if errors.As(err, &denied) {
+		return &audit.PolicyOutcome{Rules: denied.Rules}
+	}
+	return nil
+}
+
+func decisionCall(call ethereum.CallMsg) *audit.Call {
+	c := &audit.Call{From: call.From, Value: call.Value, Data: call.Data}
+	if call.To != nil {
+		c.To = *call.To
+	}
+	return c
+}
+
+func newGasQuote(gasLimitAndPrice GasLimitAndPrice) *audit.GasQuote {
+	return &audit.GasQuote{
+		GasLimit:       gasLimitAndPrice.GasLimit,
+		QuotedGasPrice: gasLimitAndPrice.GasPrice,
+		GasPrice:       gasLimitAndPrice.GasPrice,
+		Strategy:       gasLimitAndPrice.Strategy,
+	}
+}
+
+// newRewardMath is nil for token rewards, which are not priced
+func newRewardMath(call ethereum.CallMsg, attributes *MessageAttributes, gasLimitAndPrice GasLimitAndPrice) *audit.RewardMath {
+	if attributes.RewardAsset != paymaster.NativeAsset {
+		return nil
+	}
+
+	value := call.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	return audit.NewRewardMath(
+		attributes.RewardAmount.ToBig(),
+		value,
+		attributes.MagicSpendAmount.ToBig(),
+		gasLimitAndPrice.GasLimit,
+		gasLimitAndPrice.GasPrice,
+	)
+}
+
+func txHash(hash common.Hash) *common.Hash {
+	return &hash
+}
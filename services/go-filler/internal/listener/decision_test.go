@@ -0,0 +1,90 @@
+package listener
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/base-org/RRC-7755-poc/internal/audit"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+)
+
+func TestProcessMessagePostedRecordsDecision(t *testing.T) {
+	tests := []struct {
+		name        string
+		gasPrice    int64
+		wantOutcome string
+		wantChecks  []audit.CheckResult
+		wantProfit  int64
+	}{
+		{
+			name:        "shadow fulfill",
+			gasPrice:    100000000,
+			wantOutcome: audit.OutcomeShadow,
+			wantChecks: []audit.CheckResult{
+				{Name: audit.CheckValidate, Passed: true},
+				{Name: audit.CheckExpiry, Passed: true},
+				{Name: audit.CheckReward, Passed: true},
+			},
+			// 200000000000000 reward - 100000000000000 value - 119999 gas * 100000000 gas price
+			wantProfit: 88000100000000,
+		},
+		{
+			name:        "reward too low",
+			gasPrice:    1000000000000,
+			wantOutcome: audit.OutcomeSkipped,
+			wantChecks: []audit.CheckResult{
+				{Name: audit.CheckValidate, Passed: true},
+				{Name: audit.CheckExpiry, Passed: true},
+				{
+					Name:  audit.CheckReward,
+					Error: "reward amount is not enough, required minimum: 120099000000000000, provided: 200000000000000",
+				},
+			},
+			wantProfit: -119899000000000000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destClient := mocks.NewMockEthClient(gomock.NewController(t))
+			destClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil)
+			destClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(tt.gasPrice), nil)
+
+			dir := t.TempDir()
+			l, sourceChain := newShadowTestListener(t, destClient, filepath.Join(dir, "shadow.jsonl"))
+			logPath := filepath.Join(dir, "decisions.jsonl")
+			sink, err := audit.OpenSink(logPath, 0, 0)
+			require.NoError(t, err)
+			l.decisions = sink
+
+			event := newShadowTestEvent(t)
+			_ = l.processMessagePosted(context.Background(), sourceChain, event)
+
+			var records []*audit.DecisionRecord
+			require.NoError(t, audit.Replay(logPath, func(r *audit.DecisionRecord) error {
+				records = append(records, r)
+				return nil
+			}))
+			require.Len(t, records, 1)
+
+			rec := records[0]
+			require.Equal(t, common.Hash(event.MessageId), rec.MessageID)
+			require.Equal(t, testSourceChainID, rec.SourceChain)
+			require.Equal(t, tt.wantOutcome, rec.Outcome)
+			require.Equal(t, tt.wantChecks, rec.Checks)
+			require.Equal(t, testDestChainID, rec.Inputs.DestinationChain)
+			require.Equal(t, big.NewInt(200000000000000), rec.Inputs.RewardAmount)
+			require.Equal(t, big.NewInt(100000000000000), rec.Call.Value)
+			require.Equal(t, big.NewInt(119999), rec.Gas.GasLimit)
+			require.Equal(t, big.NewInt(tt.gasPrice), rec.Gas.GasPrice)
+			require.Equal(t, big.NewInt(tt.wantProfit), rec.Reward.Profit)
+			require.Nil(t, rec.TxHash)
+		})
+	}
+}
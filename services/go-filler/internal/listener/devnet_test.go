@@ -0,0 +1,303 @@
+package listener
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+// contractsOutDir holds the forge build output of the contracts, from `forge build` in contracts/
+const contractsOutDir = "../../../../contracts/out"
+
+const (
+	// devnetBlockTime is how often the devnet chains seal a block
+	devnetBlockTime = 50 * time.Millisecond
+	// devnetTxTimeout bounds the wait for a devnet transaction to be mined
+	devnetTxTimeout = 10 * time.Second
+)
+
+// Forge artifacts of the contracts deployed on every devnet chain, and of the smart account UserOps are sent from
+const (
+	entrypointArtifact  = "EntryPoint.sol/EntryPoint"
+	paymasterArtifact   = "Paymaster.sol/Paymaster"
+	inboxArtifact       = "RRC7755Inbox.sol/RRC7755Inbox"
+	outboxArtifact      = "RRC7755OutboxToArbitrum.sol/RRC7755OutboxToArbitrum"
+	mockAccountArtifact = "MockAccount.sol/MockAccount"
+)
+
+// devnetEntrypoint is the canonical EntryPoint v0.7 address, the only receiver the outbox accepts for UserOps
+var devnetEntrypoint = common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032")
+
+// devnetL2Oracle is the l2Oracle of every devnet chain, the outbox does not check it when a message is sent
+var devnetL2Oracle = common.HexToAddress("0x7755000000000000000000000000000000007755")
+
+// devnetFunds is the genesis balance of the devnet accounts
+var devnetFunds = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+
+// devnetChain is an in-process chain running the RRC-7755 contracts
+type devnetChain struct {
+	ID      uint64
+	Backend *simulated.Backend
+	Client  client.EthClient
+
+	Entrypoint common.Address
+	Paymaster  common.Address
+	Inbox      common.Address
+	Outbox     common.Address
+}
+
+// forgeArtifact is the part of a forge build artifact needed to deploy its contract
+type forgeArtifact struct {
+	ABI      json.RawMessage `json:"abi"`
+	Bytecode struct {
+		Object         string                     `json:"object"`
+		LinkReferences map[string]json.RawMessage `json:"linkReferences"`
+	} `json:"bytecode"`
+}
+
+// requireArtifactsEnv makes the devnet tests fail instead of skipping when the contracts have not been built
+const requireArtifactsEnv = "DEVNET_REQUIRE_ARTIFACTS"
+
+// requireArtifacts skips the test when the contracts have not been built, unless requireArtifactsEnv is set
+func requireArtifacts(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{entrypointArtifact, paymasterArtifact, inboxArtifact, outboxArtifact, mockAccountArtifact} {
+		if _, err := os.Stat(artifactPath(name)); err != nil {
+			if os.Getenv(requireArtifactsEnv) != "" {
+				t.Fatalf("contract artifact %s not found: %v", name, err)
+			}
+			t.Skipf("contract artifact %s not found, run `make test-e2e` or `forge build` in contracts/: %v", name, err)
+		}
+	}
+}
+
+func artifactPath(name string) string {
+	return filepath.Join(contractsOutDir, name+".json")
+}
+
+// loadArtifact reads the ABI and creation bytecode of a forge artifact
+func loadArtifact(t *testing.T, name string) (abi.ABI, []byte) {
+	t.Helper()
+
+	data, err := os.ReadFile(artifactPath(name))
+	require.NoError(t, err)
+
+	var artifact forgeArtifact
+	require.NoError(t, json.Unmarshal(data, &artifact), "decoding artifact %s", name)
+	require.Empty(t, artifact.Bytecode.LinkReferences, "artifact %s needs linked libraries", name)
+
+	parsed, err := abi.JSON(strings.NewReader(string(artifact.ABI)))
+	require.NoError(t, err, "parsing ABI of %s", name)
+
+	bytecode, err := hex.DecodeString(strings.TrimPrefix(artifact.Bytecode.Object, "0x"))
+	require.NoError(t, err, "decoding bytecode of %s", name)
+
+	return parsed, bytecode
+}
+
+// withChainID makes the simulated backend use chainID instead of the default 1337
+func withChainID(chainID uint64) func(*node.Config, *ethconfig.Config) {
+	return func(_ *node.Config, ethConf *ethconfig.Config) {
+		chainConfig := *params.AllDevChainProtocolChanges
+		chainConfig.ChainID = new(big.Int).SetUint64(chainID)
+		ethConf.Genesis.Config = &chainConfig
+		ethConf.NetworkId = chainID
+	}
+}
+
+// newDevnet starts a chain for each chain ID, sealing blocks every devnetBlockTime, funds the accounts, places the
+// EntryPoint at its canonical address and deploys the Paymaster, inbox and outbox with the first account
+func newDevnet(t *testing.T, accounts []*ecdsa.PrivateKey, chainIDs ...uint64) []*devnetChain {
+	t.Helper()
+	requireArtifacts(t)
+
+	alloc := make(types.GenesisAlloc, len(accounts))
+	for _, key := range accounts {
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: devnetFunds}
+	}
+	maps.Copy(alloc, entrypointAlloc(t, accounts[0]))
+
+	chains := make([]*devnetChain, 0, len(chainIDs))
+	for _, chainID := range chainIDs {
+		backend := simulated.NewBackend(alloc, withChainID(chainID))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		sealed := make(chan struct{})
+		go func() {
+			defer close(sealed)
+			ticker := time.NewTicker(devnetBlockTime)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					backend.Commit()
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-sealed
+			require.NoError(t, backend.Close())
+		})
+
+		ethClient, ok := backend.Client().(client.EthClient)
+		require.True(t, ok, "simulated client does not implement EthClient")
+
+		chain := &devnetChain{ID: chainID, Backend: backend, Client: ethClient}
+		chain.deployContracts(t, accounts[0])
+		chains = append(chains, chain)
+	}
+
+	return chains
+}
+
+// entrypointAlloc is the genesis alloc placing the EntryPoint at devnetEntrypoint. The EntryPoint is deployed on a
+// scratch chain and its runtime code copied, along with the code of the SenderCreator it deploys and references as an
+// immutable. Its constructor only sets the reentrancy guard, whose zero value also reads as not entered.
+func entrypointAlloc(t *testing.T, deployer *ecdsa.PrivateKey) types.GenesisAlloc {
+	t.Helper()
+	ctx := context.Background()
+
+	deployerAddress := crypto.PubkeyToAddress(deployer.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{deployerAddress: {Balance: devnetFunds}})
+	defer backend.Close()
+
+	opts, err := bind.NewKeyedTransactorWithChainID(deployer, params.AllDevChainProtocolChanges.ChainID)
+	require.NoError(t, err)
+
+	parsed, bytecode := loadArtifact(t, entrypointArtifact)
+	address, tx, _, err := bind.DeployContract(opts, parsed, bytecode, backend.Client())
+	require.NoError(t, err, "deploying %s on the scratch chain", entrypointArtifact)
+	backend.Commit()
+
+	receipt, err := backend.Client().TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status, "deploying %s reverted", entrypointArtifact)
+
+	// Contracts start at nonce 1, the SenderCreator is the first contract created by the EntryPoint
+	senderCreator := crypto.CreateAddress(address, 1)
+
+	alloc := make(types.GenesisAlloc, 2)
+	for target, source := range map[common.Address]common.Address{devnetEntrypoint: address, senderCreator: senderCreator} {
+		code, err := backend.Client().CodeAt(ctx, source, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, code, "no code at %s on the scratch chain", source.Hex())
+		alloc[target] = types.Account{Code: code, Balance: new(big.Int)}
+	}
+	return alloc
+}
+
+// deployContracts deploys the RRC-7755 contracts the way the deploy script does, the Paymaster being deployed before
+// the inbox it is bound to. The EntryPoint is already in the genesis at devnetEntrypoint.
+func (c *devnetChain) deployContracts(t *testing.T, deployer *ecdsa.PrivateKey) {
+	t.Helper()
+
+	opts := c.transactor(t, deployer)
+	c.Entrypoint = devnetEntrypoint
+
+	nonce, err := c.Client.PendingNonceAt(context.Background(), opts.From)
+	require.NoError(t, err)
+	inbox := crypto.CreateAddress(opts.From, nonce+1)
+
+	c.Paymaster = c.deploy(t, opts, paymasterArtifact, c.Entrypoint, inbox)
+	c.Inbox = c.deploy(t, opts, inboxArtifact, c.Paymaster)
+	require.Equal(t, inbox, c.Inbox)
+	c.Outbox = c.deploy(t, opts, outboxArtifact)
+}
+
+func (c *devnetChain) deploy(t *testing.T, opts *bind.TransactOpts, name string, args ...interface{}) common.Address {
+	t.Helper()
+
+	parsed, bytecode := loadArtifact(t, name)
+	address, tx, _, err := bind.DeployContract(opts, parsed, bytecode, c.Client, args...)
+	require.NoError(t, err, "deploying %s on chain %d", name, c.ID)
+	c.waitMined(t, tx)
+
+	return address
+}
+
+func (c *devnetChain) transactor(t *testing.T, key *ecdsa.PrivateKey) *bind.TransactOpts {
+	t.Helper()
+
+	opts, err := bind.NewKeyedTransactorWithChainID(key, new(big.Int).SetUint64(c.ID))
+	require.NoError(t, err)
+	return opts
+}
+
+// waitMined waits for the transaction to be mined and requires it to succeed
+func (c *devnetChain) waitMined(t *testing.T, tx *types.Transaction) *types.Receipt {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), devnetTxTimeout)
+	defer cancel()
+
+	receipt, err := bind.WaitMined(ctx, c.Client, tx)
+	require.NoError(t, err, "waiting for transaction %s on chain %d", tx.Hash().Hex(), c.ID)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status, "transaction %s reverted on chain %d", tx.Hash().Hex(), c.ID)
+
+	return receipt
+}
+
+// chainConfig is the filler config of the chain
+func (c *devnetChain) chainConfig() config.ChainConfig {
+	return config.ChainConfig{
+		ChainID:           c.ID,
+		NodeURL:           fmt.Sprintf("simulated://%d", c.ID),
+		OutboxAddresses:   map[string]common.Address{config.ProverArbitrum: c.Outbox},
+		L2Oracle:          devnetL2Oracle,
+		InboxAddress:      c.Inbox,
+		EntrypointAddress: c.Entrypoint,
+	}
+}
+
+// newDevnetConfig is the filler config of the devnet chains, fulfilling requests with the fulfiller account
+func newDevnetConfig(chains []*devnetChain, fulfiller *ecdsa.PrivateKey) *config.Config {
+	cfg := &config.Config{
+		Chain: make(map[string]config.ChainConfig, len(chains)),
+		Wallets: config.WalletConfig{
+			FromAddress: crypto.PubkeyToAddress(fulfiller.PublicKey).Hex(),
+			PrivateKey:  hex.EncodeToString(crypto.FromECDSA(fulfiller)),
+		},
+	}
+	for _, chain := range chains {
+		cfg.Chain[strconv.FormatUint(chain.ID, decimalBase)] = chain.chainConfig()
+	}
+	return cfg
+}
+
+// newDevnetClientManager connects the client manager to the devnet chains instead of dialing their node URL
+func newDevnetClientManager(chains []*devnetChain, cfg *config.Config) *client.Manager {
+	clients := make(map[uint64]*client.ChainClient, len(chains))
+	for _, chain := range chains {
+		chainCfg, _ := config.GetChainConfigByID(cfg, chain.ID)
+		clients[chain.ID] = &client.ChainClient{Client: chain.Client, Config: chainCfg}
+	}
+	return &client.Manager{Chains: clients}
+}
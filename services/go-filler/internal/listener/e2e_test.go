@@ -0,0 +1,318 @@
+package listener
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/base-org/RRC-7755-poc/bindings/paymaster"
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_inbox"
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	internalpaymaster "github.com/base-org/RRC-7755-poc/internal/paymaster"
+)
+
+const (
+	// e2eTimeout bounds the wait for the listener to subscribe and to fulfill the requests
+	e2eTimeout = 30 * time.Second
+	// devnetUserOpGas is each gas limit of the devnet UserOps
+	devnetUserOpGas = 300000
+)
+
+// devnetAttribute encodes an attribute as its selector followed by its 32 bytes words
+func devnetAttribute(selector uint32, words ...[]byte) []byte {
+	attr := binary.BigEndian.AppendUint32(nil, selector)
+	for _, word := range words {
+		attr = append(attr, common.LeftPadBytes(word, bytes32Size)...)
+	}
+	return attr
+}
+
+// UserOp attributes the filler doesn't parse, checked by the outbox when the request is sent
+const (
+	// inbox(bytes32)
+	inboxAttributeSelector uint32 = 0xbd362374
+	// sourceChain(bytes32,bytes32)
+	sourceChainAttributeSelector uint32 = 0x10b2cb84
+)
+
+// devnetAttributes are the attributes the Arbitrum outbox requires on every request, paying reward in ETH
+func devnetAttributes(t *testing.T, source *devnetChain, requester *ecdsa.PrivateKey, nonce uint64, reward *big.Int) [][]byte {
+	t.Helper()
+
+	head, err := source.Client.HeaderByNumber(context.Background(), nil)
+	require.NoError(t, err)
+	// The Arbitrum outbox requires requests to be open for at least 8 days
+	expiry := head.Time + uint64((9 * 24 * time.Hour).Seconds())
+
+	requesterAddress := crypto.PubkeyToAddress(requester.PublicKey)
+	return [][]byte{
+		devnetAttribute(rewardAttributeSelector, internalpaymaster.NativeAsset.Bytes(), reward.Bytes()),
+		devnetAttribute(l2OracleAttributeSelector, devnetL2Oracle.Bytes()),
+		devnetAttribute(nonceAttributeSelector, new(big.Int).SetUint64(nonce).Bytes()),
+		devnetAttribute(requesterAttributeSelector, requesterAddress.Bytes()),
+		devnetAttribute(delayAttributeSelector, big.NewInt(int64(time.Hour.Seconds())).Bytes(), new(big.Int).SetUint64(expiry).Bytes()),
+	}
+}
+
+// postDevnetMessage posts a request on the outbox of source, paying reward in ETH, and returns its message ID
+func postDevnetMessage(
+	t *testing.T,
+	source *devnetChain,
+	destChainID uint64,
+	requester *ecdsa.PrivateKey,
+	receiver common.Address,
+	payload []byte,
+	attributes [][]byte,
+	reward *big.Int,
+) [32]byte {
+	t.Helper()
+
+	outbox, err := rrc_7755_outbox.NewRRC7755Outbox(source.Outbox, source.Client)
+	require.NoError(t, err)
+
+	opts := source.transactor(t, requester)
+	opts.Value = reward
+	tx, err := outbox.SendMessage(
+		opts,
+		common.BigToHash(new(big.Int).SetUint64(destChainID)),
+		common.BytesToHash(receiver.Bytes()),
+		payload,
+		attributes,
+	)
+	require.NoError(t, err)
+	receipt := source.waitMined(t, tx)
+
+	for _, log := range receipt.Logs {
+		event, err := outbox.ParseMessagePosted(*log)
+		if err == nil {
+			return event.MessageId
+		}
+	}
+	require.FailNow(t, "no MessagePosted event in the sendMessage receipt")
+	return [32]byte{}
+}
+
+// sendDevnetMessage posts a request on the outbox of source for calls on the inbox of dest, paying reward in ETH, and
+// returns its message ID
+func sendDevnetMessage(
+	t *testing.T,
+	source *devnetChain,
+	dest *devnetChain,
+	requester *ecdsa.PrivateKey,
+	nonce uint64,
+	calls []abi.Call,
+	reward *big.Int,
+) [32]byte {
+	t.Helper()
+
+	payload, err := abi.CallsArgs.Pack(calls)
+	require.NoError(t, err)
+
+	attributes := devnetAttributes(t, source, requester, nonce, reward)
+	return postDevnetMessage(t, source, dest.ID, requester, dest.Inbox, payload, attributes, reward)
+}
+
+// sendDevnetUserOp posts a UserOp request on the outbox of source for the EntryPoint of dest. The op of account
+// withdraws magicSpend from the paymaster of dest and forwards it with calls.
+func sendDevnetUserOp(
+	t *testing.T,
+	source *devnetChain,
+	dest *devnetChain,
+	requester *ecdsa.PrivateKey,
+	nonce uint64,
+	account common.Address,
+	calls []abi.Call,
+	magicSpend *big.Int,
+	reward *big.Int,
+) [32]byte {
+	t.Helper()
+
+	attributes := append(devnetAttributes(t, source, requester, nonce, reward),
+		devnetAttribute(inboxAttributeSelector, dest.Inbox.Bytes()),
+		devnetAttribute(sourceChainAttributeSelector, new(big.Int).SetUint64(source.ID).Bytes(), source.Outbox.Bytes()),
+		magicSpendRequestAttribute(internalpaymaster.NativeAsset, magicSpend),
+	)
+	bytesArray, err := gethabi.NewType("bytes[]", "", nil)
+	require.NoError(t, err)
+	paymasterData, err := gethabi.Arguments{{Type: bytesArray}}.Pack(attributes)
+	require.NoError(t, err)
+
+	accountABI, _ := loadArtifact(t, mockAccountArtifact)
+	callData, err := accountABI.Pack("executeUserOpWithCalls", dest.Paymaster, common.BytesToHash(internalpaymaster.NativeAsset.Bytes()), calls)
+	require.NoError(t, err)
+
+	paymasterAndData := append(dest.Paymaster.Bytes(), uint128Pair(devnetUserOpGas, devnetUserOpGas)...)
+	op := abi.PackedUserOperation{
+		Sender:             account,
+		Nonce:              big.NewInt(0),
+		InitCode:           []byte{},
+		CallData:           callData,
+		AccountGasLimits:   [32]byte(uint128Pair(devnetUserOpGas, devnetUserOpGas)),
+		PreVerificationGas: big.NewInt(devnetUserOpGas),
+		GasFees:            [32]byte(uint128Pair(params.GWei, params.GWei)),
+		PaymasterAndData:   append(paymasterAndData, paymasterData...),
+		Signature:          []byte{},
+	}
+	payload, err := abi.PackedUserOperationArgs.Pack(op)
+	require.NoError(t, err)
+
+	return postDevnetMessage(t, source, dest.ID, requester, dest.Entrypoint, payload, nil, reward)
+}
+
+// uint128Pair packs high and low into the 32 bytes of a UserOp gas limits or gas fees field
+func uint128Pair(high, low int64) []byte {
+	pair := make([]byte, bytes32Size)
+	big.NewInt(high).FillBytes(pair[:bytes32Size/2])
+	big.NewInt(low).FillBytes(pair[bytes32Size/2:])
+	return pair
+}
+
+// startDevnetListener runs the listener on the devnet chains until the test ends. It returns once the outboxes are
+// watched, as only the requests posted from then on are seen by the listener.
+func startDevnetListener(t *testing.T, chains []*devnetChain, fulfiller *ecdsa.PrivateKey) {
+	t.Helper()
+
+	cfg := newDevnetConfig(chains, fulfiller)
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zaptest.NewLogger(t, zaptest.WrapOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, core)
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l, err := NewOutboxListener(ctx, newDevnetClientManager(chains, cfg), cfg, logger)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+		require.NoError(t, l.Close())
+	})
+
+	require.Eventually(t, func() bool {
+		return logs.FilterMessage("Started outbox WatchMessagePosted and WatchCrossChainCallCanceled").Len() == len(chains)
+	}, e2eTimeout, devnetBlockTime)
+}
+
+// requireFulfilled waits for the inbox of dest to store the fulfillment of messageID by fulfiller
+func requireFulfilled(t *testing.T, dest *devnetChain, messageID [32]byte, fulfiller common.Address) {
+	t.Helper()
+
+	inbox, err := rrc_7755_inbox.NewRRC7755Inbox(dest.Inbox, dest.Client)
+	require.NoError(t, err)
+
+	var info rrc_7755_inbox.RRC7755InboxFulfillmentInfo
+	require.Eventually(t, func() bool {
+		info, err = inbox.GetFulfillmentInfo(&bind.CallOpts{}, messageID)
+		return err == nil && info.Timestamp.Sign() > 0
+	}, e2eTimeout, devnetBlockTime, "request %x to chain %d was not fulfilled", messageID, dest.ID)
+	require.Equal(t, fulfiller, info.Fulfiller)
+}
+
+// TestEndToEndFulfillment runs the listener against devnet chains running the contracts: the requests posted on the
+// source chain outbox must be fulfilled on the inbox of their destination chain
+func TestEndToEndFulfillment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping devnet end-to-end test in short mode")
+	}
+
+	requester, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	fulfiller, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	fulfillerAddress := crypto.PubkeyToAddress(fulfiller.PublicKey)
+
+	chains := newDevnet(t, []*ecdsa.PrivateKey{requester, fulfiller}, 77551, 77552, 77553)
+	source, destinations := chains[0], chains[1:]
+	startDevnetListener(t, chains, fulfiller)
+
+	reward := big.NewInt(params.Ether / 100)
+	value := big.NewInt(testValue)
+	recipients := make([]common.Address, len(destinations))
+	messageIDs := make([][32]byte, len(destinations))
+	for i, dest := range destinations {
+		recipients[i] = common.BigToAddress(big.NewInt(int64(0x7755_0000 + i)))
+		calls := []abi.Call{{To: common.BytesToHash(recipients[i].Bytes()), Data: []byte{}, Value: value}}
+		messageIDs[i] = sendDevnetMessage(t, source, dest, requester, uint64(i), calls, reward)
+	}
+
+	for i, dest := range destinations {
+		requireFulfilled(t, dest, messageIDs[i], fulfillerAddress)
+
+		inbox, err := rrc_7755_inbox.NewRRC7755Inbox(dest.Inbox, dest.Client)
+		require.NoError(t, err)
+		it, err := inbox.FilterCallFulfilled(&bind.FilterOpts{}, [][32]byte{messageIDs[i]}, []common.Address{fulfillerAddress})
+		require.NoError(t, err)
+		require.True(t, it.Next(), "no CallFulfilled event for request %x on chain %d", messageIDs[i], dest.ID)
+		require.NoError(t, it.Close())
+
+		balance, err := dest.Client.BalanceAt(context.Background(), recipients[i], nil)
+		require.NoError(t, err)
+		require.Equal(t, value, balance)
+	}
+}
+
+// TestEndToEndUserOpFulfillment posts a UserOp request: the listener must send it to the EntryPoint with handleOps,
+// the Paymaster sponsoring its gas and magic spend from the fulfiller balances and storing the fulfillment on the inbox
+func TestEndToEndUserOpFulfillment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping devnet end-to-end test in short mode")
+	}
+
+	requester, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	fulfiller, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	fulfillerAddress := crypto.PubkeyToAddress(fulfiller.PublicKey)
+
+	chains := newDevnet(t, []*ecdsa.PrivateKey{requester, fulfiller}, 77561, 77562)
+	source, dest := chains[0], chains[1]
+
+	account := dest.deploy(t, dest.transactor(t, requester), mockAccountArtifact)
+
+	// The fulfiller funds the gas it sponsors in the EntryPoint and the magic spend the op withdraws
+	magicSpend := big.NewInt(testValue)
+	gas := big.NewInt(params.Ether / 100)
+	paymasterContract, err := paymaster.NewPaymaster(dest.Paymaster, dest.Client)
+	require.NoError(t, err)
+	opts := dest.transactor(t, fulfiller)
+	opts.Value = new(big.Int).Add(gas, magicSpend)
+	tx, err := paymasterContract.EntryPointDeposit(opts, gas)
+	require.NoError(t, err)
+	dest.waitMined(t, tx)
+
+	startDevnetListener(t, chains, fulfiller)
+
+	recipient := common.HexToAddress("0x7755000000000000000000000000000000000001")
+	calls := []abi.Call{{To: common.BytesToHash(recipient.Bytes()), Data: []byte{}, Value: magicSpend}}
+	reward := big.NewInt(params.Ether / 100)
+	messageID := sendDevnetUserOp(t, source, dest, requester, 0, account, calls, magicSpend, reward)
+
+	requireFulfilled(t, dest, messageID, fulfillerAddress)
+
+	balance, err := dest.Client.BalanceAt(context.Background(), recipient, nil)
+	require.NoError(t, err)
+	require.Equal(t, magicSpend, balance)
+
+	magicSpendBalance, err := paymasterContract.GetMagicSpendBalance(&bind.CallOpts{}, fulfillerAddress, internalpaymaster.NativeAsset)
+	require.NoError(t, err)
+	require.Zero(t, magicSpendBalance.Sign(), "the magic spend was not taken from the fulfiller balance")
+}
@@ -0,0 +1,146 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CrossChainCallStatus values of RRC7755Outbox.getMessageStatus
+const (
+	messageStatusNone uint8 = iota
+	messageStatusRequested
+	messageStatusCanceled
+	messageStatusCompleted
+)
+
+var messageStatusNames = map[uint8]string{
+	messageStatusNone:      "None",
+	messageStatusRequested: "Requested",
+	messageStatusCanceled:  "Canceled",
+	messageStatusCompleted: "Completed",
+}
+
+// canceledMessages remembers the most recent message IDs canceled on the watched outboxes
+type canceledMessages struct {
+	mu    sync.Mutex
+	ids   map[[32]byte]struct{}
+	order [][32]byte
+	size  int
+}
+
+func newCanceledMessages(size int) *canceledMessages {
+	return &canceledMessages{
+		ids:  make(map[[32]byte]struct{}, size),
+		size: size,
+	}
+}
+
+func (c *canceledMessages) add(messageID [32]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ids[messageID]; ok {
+		return
+	}
+
+	if len(c.order) >= c.size {
+		delete(c.ids, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.ids[messageID] = struct{}{}
+	c.order = append(c.order, messageID)
+}
+
+func (c *canceledMessages) contains(messageID [32]byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.ids[messageID]
+	return ok
+}
+
+var errMessageCanceled = errors.New("message was canceled")
+
+// isCanceled reports whether a cancellation of the message was seen on its outbox
+func (l *OutboxListener) isCanceled(messageID [32]byte) bool {
+	return l.canceled != nil && l.canceled.contains(messageID)
+}
+
+// validateExpiry rejects requests that expire before their reward could be claimed. The requester can cancel a
+// request once it expired, so the claim has to be possible before then: no earlier than the min claim latency of the
+// destination chain, nor than the finality delay of the request.
+func validateExpiry(attributes *MessageAttributes, destChain *client.ChainClient, now time.Time) error {
+	if attributes.Expiry.IsZero() {
+		return errors.New("request has no expiry, it can be canceled at any time")
+	}
+
+	const maxSeconds = math.MaxInt64 / int64(time.Second)
+
+	if !attributes.FinalityDelay.IsUint64() || attributes.FinalityDelay.Uint64() > uint64(maxSeconds) {
+		return fmt.Errorf("finality delay of %s seconds is too long", attributes.FinalityDelay.Dec())
+	}
+	latency := time.Duration(attributes.FinalityDelay.Uint64()) * time.Second
+	if destChain.Config.MinClaimLatency > latency {
+		latency = destChain.Config.MinClaimLatency
+	}
+
+	if !attributes.Expiry.IsUint64() || attributes.Expiry.Uint64() > uint64(maxSeconds) {
+		return nil
+	}
+	expiry := time.Unix(int64(attributes.Expiry.Uint64()), 0)
+
+	claimableAt := now.Add(latency)
+	if claimableAt.After(expiry) {
+		return fmt.Errorf(
+			"request expires at %s, before its reward can be claimed at %s",
+			expiry.UTC().Format(time.RFC3339),
+			claimableAt.UTC().Format(time.RFC3339),
+		)
+	}
+
+	return nil
+}
+
+// checkMessageStatus makes sure the request is still open on its outbox right before it is fulfilled
+func (l *OutboxListener) checkMessageStatus(
+	ctx context.Context,
+	sourceChain *client.ChainClient,
+	outboxAddress common.Address,
+	messageID [32]byte,
+) error {
+	if l.isCanceled(messageID) {
+		return errMessageCanceled
+	}
+
+	outbox, err := rrc_7755_outbox.NewRRC7755OutboxCaller(outboxAddress, sourceChain.Client)
+	if err != nil {
+		return fmt.Errorf("binding outbox: %w", err)
+	}
+
+	status, err := outbox.GetMessageStatus(&bind.CallOpts{Context: ctx}, messageID)
+	if err != nil {
+		return fmt.Errorf("getting message status: %w", err)
+	}
+
+	if status != messageStatusRequested {
+		if status == messageStatusCanceled {
+			l.canceled.add(messageID)
+		}
+		return fmt.Errorf(
+			"message %s is not open anymore, status: %s",
+			common.Hash(messageID).Hex(),
+			messageStatusNames[status],
+		)
+	}
+
+	return nil
+}
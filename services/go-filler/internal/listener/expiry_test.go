@@ -0,0 +1,126 @@
+package listener
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+func TestValidateExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name            string
+		expiry          uint64
+		finalityDelay   uint64
+		minClaimLatency time.Duration
+		wantErr         string
+	}{
+		{
+			name:          "claimable before expiry",
+			expiry:        uint64(now.Add(2 * time.Hour).Unix()),
+			finalityDelay: 3600,
+		},
+		{
+			name:    "no expiry",
+			wantErr: "request has no expiry",
+		},
+		{
+			name:    "expired",
+			expiry:  uint64(now.Add(-time.Second).Unix()),
+			wantErr: "before its reward can be claimed",
+		},
+		{
+			name:          "finality delay past expiry",
+			expiry:        uint64(now.Add(time.Hour).Unix()),
+			finalityDelay: 7200,
+			wantErr:       "before its reward can be claimed",
+		},
+		{
+			name:            "destination claim latency past expiry",
+			expiry:          uint64(now.Add(24 * time.Hour).Unix()),
+			finalityDelay:   3600,
+			minClaimLatency: 7 * 24 * time.Hour,
+			wantErr:         "before its reward can be claimed",
+		},
+		{
+			name:          "finality delay overflow",
+			expiry:        uint64(now.Add(time.Hour).Unix()),
+			finalityDelay: 1 << 62,
+			wantErr:       "is too long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributes := &MessageAttributes{
+				Expiry:        *uint256.NewInt(tt.expiry),
+				FinalityDelay: *uint256.NewInt(tt.finalityDelay),
+			}
+			destChain := &client.ChainClient{Config: config.ChainConfig{MinClaimLatency: tt.minClaimLatency}}
+
+			err := validateExpiry(attributes, destChain, now)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCanceledMessagesEvictsOldest(t *testing.T) {
+	canceled := newCanceledMessages(2)
+	canceled.add([32]byte{1})
+	canceled.add([32]byte{2})
+	canceled.add([32]byte{2})
+	canceled.add([32]byte{3})
+
+	require.False(t, canceled.contains([32]byte{1}))
+	require.True(t, canceled.contains([32]byte{2}))
+	require.True(t, canceled.contains([32]byte{3}))
+}
+
+func TestCheckMessageStatus(t *testing.T) {
+	outboxAbi, err := rrc_7755_outbox.RRC7755OutboxMetaData.GetAbi()
+	require.NoError(t, err)
+	outbox := common.HexToAddress("0x3542dd26727844524ea7c136c5c38ff8088b30ba")
+
+	newSourceChain := func(status uint8) *client.ChainClient {
+		ethClient := mocks.NewMockEthClient(gomock.NewController(t))
+		ethClient.EXPECT().
+			CallContract(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+				require.Equal(t, outbox, *msg.To)
+				return outboxAbi.Methods["getMessageStatus"].Outputs.Pack(status)
+			}).
+			AnyTimes()
+		return &client.ChainClient{Client: ethClient}
+	}
+
+	l := &OutboxListener{logger: zaptest.NewLogger(t), canceled: newCanceledMessages(canceledMessagesSize)}
+	ctx := context.Background()
+
+	require.NoError(t, l.checkMessageStatus(ctx, newSourceChain(messageStatusRequested), outbox, [32]byte{1}))
+
+	err = l.checkMessageStatus(ctx, newSourceChain(messageStatusCompleted), outbox, [32]byte{2})
+	require.ErrorContains(t, err, "status: Completed")
+
+	// A canceled status is remembered, the next check doesn't need the outbox
+	err = l.checkMessageStatus(ctx, newSourceChain(messageStatusCanceled), outbox, [32]byte{3})
+	require.ErrorContains(t, err, "status: Canceled")
+	require.ErrorIs(t, l.checkMessageStatus(ctx, &client.ChainClient{}, outbox, [32]byte{3}), errMessageCanceled)
+}
@@ -0,0 +1,323 @@
+package listener
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_inbox"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+type pendingFulfill struct {
+	messageID  [32]byte
+	call       ethereum.CallMsg
+	attributes *MessageAttributes
+	// gasLimit is the estimate of the fulfill sent on its own, it weights the share of the batch gas of the request
+	gasLimit *big.Int
+}
+
+// FulfillOutcome is the result of a batched fulfill, attributed back to its request from the CallFulfilled logs
+type FulfillOutcome struct {
+	MessageID [32]byte
+	TxHash    common.Hash
+	// Mined is set once the receipt of the batch was read, whether it succeeded or reverted
+	Mined bool
+	// Fulfilled is set when the inbox emitted CallFulfilled for the request with our wallet as fulfiller
+	Fulfilled bool
+	Err       error
+}
+
+// fulfillBatcher groups the inbox fulfills of each destination chain into a single multicall transaction
+type fulfillBatcher struct {
+	listener  *OutboxListener
+	multicall common.Address
+	queue     *batchQueue[pendingFulfill]
+}
+
+func newFulfillBatcher(l *OutboxListener, cfg config.FulfillBatchConfig) *fulfillBatcher {
+	b := &fulfillBatcher{
+		listener:  l,
+		multicall: cfg.MulticallAddress,
+	}
+	if b.multicall == (common.Address{}) {
+		b.multicall = abi.Multicall3Address
+	}
+	b.queue = newBatchQueue(cfg.BatchConfig, func(ctx context.Context, destChain *client.ChainClient, batch []pendingFulfill) {
+		b.sendBatch(ctx, destChain, batch)
+	})
+	return b
+}
+
+// canBatchFulfill reports whether the fulfill can be sent through the multicall. The inbox passes msg.sender to the
+// precheck contract and withdraws magic spend from the msg.sender paymaster balance, both would see the multicall
+// instead of our wallet.
+func canBatchFulfill(rawAttributes [][]byte) bool {
+	for _, attr := range rawAttributes {
+		if len(attr) < selectorSize {
+			continue
+		}
+
+		switch binary.BigEndian.Uint32(attr[:selectorSize]) {
+		case precheckAttributeSelector, magicSpendRequestAttributeSelector:
+			return false
+		}
+	}
+	return true
+}
+
+// Add queues a fulfill for its destination chain. The batch is sent right away once full, otherwise after the max
+// delay.
+func (b *fulfillBatcher) Add(destChain *client.ChainClient, fulfill pendingFulfill) {
+	size := b.queue.Add(destChain, fulfill)
+
+	b.listener.logger.Info("Queued fulfill",
+		zap.String("message_id", common.Hash(fulfill.messageID).Hex()),
+		zap.Uint64("chain_id", destChain.Config.ChainID),
+		zap.Int("batch_size", size),
+	)
+}
+
+// Close sends the pending batches and waits for every batch to be sent
+func (b *fulfillBatcher) Close(ctx context.Context) {
+	b.queue.Close(ctx)
+}
+
+// sendBatch drops the requests canceled while queued, simulates every other fulfill on its own, checks the reward of
+// each request against its share of the batch gas and sends the remaining fulfills in a single multicall
+func (b *fulfillBatcher) sendBatch(
+	ctx context.Context,
+	destChain *client.ChainClient,
+	batch []pendingFulfill,
+) []FulfillOutcome {
+	l := b.listener
+
+	outcomes := make([]FulfillOutcome, len(batch))
+	included := make([]int, 0, len(batch))
+	for i, item := range batch {
+		outcomes[i].MessageID = item.messageID
+
+		if l.isCanceled(item.messageID) {
+			outcomes[i].Err = errMessageCanceled
+			continue
+		}
+
+		if _, err := destChain.Client.CallContract(ctx, item.call, nil); err != nil {
+			outcomes[i].Err = fmt.Errorf("simulating fulfill: %w", err)
+			continue
+		}
+		included = append(included, i)
+	}
+
+	call, gasLimitAndPrice, included, err := b.priceBatch(ctx, destChain, batch, outcomes, included)
+	if err == nil && len(included) > 0 {
+		err = b.send(ctx, destChain, batch, outcomes, included, call, gasLimitAndPrice)
+	}
+	if err != nil {
+		for _, i := range included {
+			outcomes[i].Err = err
+		}
+	}
+
+	for _, outcome := range outcomes {
+		fields := []zap.Field{
+			zap.String("message_id", common.Hash(outcome.MessageID).Hex()),
+			zap.Uint64("chain_id", destChain.Config.ChainID),
+			zap.String("tx_hash", outcome.TxHash.Hex()),
+			zap.Bool("fulfilled", outcome.Fulfilled),
+		}
+
+		switch {
+		case outcome.Err == nil && outcome.Fulfilled:
+			l.logger.Info("Batched fulfill succeeded", fields...)
+		case outcome.TxHash != (common.Hash{}) && !outcome.Mined:
+			// The batch was broadcast but its receipt is unknown, it may still fulfill the request so the exposure is
+			// kept until the request is claimed
+			l.logger.Warn("Batched fulfill pending", append(fields, zap.Error(outcome.Err))...)
+		default:
+			l.logger.Error("Batched fulfill failed", append(fields, zap.Error(outcome.Err))...)
+			l.releaseExposure(outcome.MessageID)
+		}
+	}
+
+	return outcomes
+}
+
+// priceBatch estimates the multicall and validates the reward of every request against its share of the batch gas.
+// Unprofitable requests are dropped and the smaller batch is priced again.
+func (b *fulfillBatcher) priceBatch(
+	ctx context.Context,
+	destChain *client.ChainClient,
+	batch []pendingFulfill,
+	outcomes []FulfillOutcome,
+	included []int,
+) (ethereum.CallMsg, GasLimitAndPrice, []int, error) {
+	l := b.listener
+
+	for len(included) > 0 {
+		call, err := b.createMulticallMsg(batch, included)
+		if err != nil {
+			return ethereum.CallMsg{}, GasLimitAndPrice{}, included, err
+		}
+
+		gasLimitAndPrice, err := l.getGasLimitAndPrice(ctx, destChain, call, nil)
+		if err != nil {
+			return ethereum.CallMsg{}, GasLimitAndPrice{}, included, fmt.Errorf("getting gas limit and price: %w", err)
+		}
+
+		weights := make([]*big.Int, len(included))
+		for j, i := range included {
+			weights[j] = batch[i].gasLimit
+		}
+		shares := batchGasShares(gasLimitAndPrice.GasLimit, weights)
+
+		profitable := included[:0:0]
+		for j, i := range included {
+			share := GasLimitAndPrice{GasLimit: shares[j], GasPrice: gasLimitAndPrice.GasPrice, Strategy: gasLimitAndPrice.Strategy}
+			if err := l.validateReward(batch[i].call, batch[i].attributes, share); err != nil {
+				outcomes[i].Err = fmt.Errorf("validating reward: %w", err)
+				continue
+			}
+			profitable = append(profitable, i)
+		}
+
+		if len(profitable) == len(included) {
+			return call, gasLimitAndPrice, included, nil
+		}
+		included = profitable
+	}
+
+	return ethereum.CallMsg{}, GasLimitAndPrice{}, nil, nil
+}
+
+func (b *fulfillBatcher) send(
+	ctx context.Context,
+	destChain *client.ChainClient,
+	batch []pendingFulfill,
+	outcomes []FulfillOutcome,
+	included []int,
+	call ethereum.CallMsg,
+	gasLimitAndPrice GasLimitAndPrice,
+) error {
+	l := b.listener
+
+	tx, err := l.SendTransaction(ctx, destChain, call, gasLimitAndPrice)
+	if err != nil {
+		return fmt.Errorf("sending fulfill batch: %w", err)
+	}
+	for _, i := range included {
+		outcomes[i].TxHash = tx.Hash()
+	}
+
+	receipt, err := bind.WaitMined(ctx, destChain.Client, tx)
+	if err != nil {
+		return fmt.Errorf("waiting for fulfill batch: %w", err)
+	}
+	for _, i := range included {
+		outcomes[i].Mined = true
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("fulfill batch %s reverted", tx.Hash().Hex())
+	}
+
+	inboxFilterer, err := rrc_7755_inbox.NewRRC7755InboxFilterer(common.Address{}, destChain.Client)
+	if err != nil {
+		return fmt.Errorf("binding inbox: %w", err)
+	}
+
+	byMessageID := make(map[[32]byte]int, len(included))
+	for _, i := range included {
+		byMessageID[batch[i].messageID] = i
+	}
+
+	callFulfilledID := inboxCallFulfilledEventID()
+	for _, log := range receipt.Logs {
+		if len(log.Topics) == 0 || log.Topics[0] != callFulfilledID {
+			continue
+		}
+
+		event, err := inboxFilterer.ParseCallFulfilled(*log)
+		if err != nil {
+			return fmt.Errorf("parsing CallFulfilled: %w", err)
+		}
+
+		i, ok := byMessageID[event.MessageId]
+		if !ok || log.Address != *batch[i].call.To || event.FulfilledBy != l.currentConfig().Wallets.GetFromAddress() {
+			continue
+		}
+		outcomes[i].Fulfilled = true
+	}
+
+	for _, i := range included {
+		if !outcomes[i].Fulfilled {
+			outcomes[i].Err = errors.New("no CallFulfilled event for the request")
+		}
+	}
+
+	return nil
+}
+
+// createMulticallMsg packs the included fulfills into aggregate3Value. Calls sending value can't fail on their own as
+// Multicall3 would keep the value of a failed call, they revert the whole batch instead.
+func (b *fulfillBatcher) createMulticallMsg(batch []pendingFulfill, included []int) (ethereum.CallMsg, error) {
+	calls := make([]abi.Call3Value, 0, len(included))
+	value := new(big.Int)
+	for _, i := range included {
+		fulfill := batch[i].call
+		calls = append(calls, abi.Call3Value{
+			Target:       *fulfill.To,
+			AllowFailure: fulfill.Value.Sign() == 0,
+			Value:        fulfill.Value,
+			CallData:     fulfill.Data,
+		})
+		value.Add(value, fulfill.Value)
+	}
+
+	data, err := abi.PackAggregate3Value(calls)
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+
+	return ethereum.CallMsg{
+		From:  b.listener.currentConfig().Wallets.GetFromAddress(),
+		To:    &b.multicall,
+		Data:  data,
+		Value: value,
+	}, nil
+}
+
+// batchGasShares splits the batch gas limit between the requests in proportion to their standalone gas estimates
+func batchGasShares(total *big.Int, weights []*big.Int) []*big.Int {
+	sum := new(big.Int)
+	for _, weight := range weights {
+		sum.Add(sum, weight)
+	}
+
+	shares := make([]*big.Int, len(weights))
+	for i, weight := range weights {
+		if sum.Sign() == 0 {
+			shares[i] = new(big.Int).Div(total, big.NewInt(int64(len(weights))))
+			continue
+		}
+		shares[i] = new(big.Int).Mul(total, weight)
+		shares[i].Div(shares[i], sum)
+	}
+	return shares
+}
+
+func inboxCallFulfilledEventID() common.Hash {
+	inboxAbi, err := rrc_7755_inbox.RRC7755InboxMetaData.GetAbi()
+	if err != nil {
+		return common.Hash{}
+	}
+	return inboxAbi.Events["CallFulfilled"].ID
+}
@@ -0,0 +1,203 @@
+package listener
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/paymaster"
+)
+
+func TestCanBatchFulfill(t *testing.T) {
+	attribute := func(selector uint32) []byte {
+		return append(big.NewInt(int64(selector)).FillBytes(make([]byte, selectorSize)), make([]byte, bytes32Size)...)
+	}
+
+	require.True(t, canBatchFulfill([][]byte{attribute(nonceAttributeSelector), attribute(rewardAttributeSelector)}))
+	require.False(t, canBatchFulfill([][]byte{attribute(nonceAttributeSelector), attribute(precheckAttributeSelector)}))
+	require.False(t, canBatchFulfill([][]byte{attribute(magicSpendRequestAttributeSelector)}))
+}
+
+func TestBatchGasShares(t *testing.T) {
+	shares := batchGasShares(big.NewInt(90000), []*big.Int{big.NewInt(100), big.NewInt(200)})
+	require.Equal(t, []*big.Int{big.NewInt(30000), big.NewInt(60000)}, shares)
+
+	shares = batchGasShares(big.NewInt(90000), []*big.Int{big.NewInt(0), big.NewInt(0)})
+	require.Equal(t, []*big.Int{big.NewInt(45000), big.NewInt(45000)}, shares)
+}
+
+func TestFulfillBatcherSendBatch(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	wallet := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	inbox := common.HexToAddress("0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb")
+	newFulfill := func(id byte, reward uint64) pendingFulfill {
+		return pendingFulfill{
+			messageID: [32]byte{id},
+			call:      ethereum.CallMsg{From: wallet, To: &inbox, Data: []byte{0xf0, id}, Value: big.NewInt(0)},
+			attributes: &MessageAttributes{
+				RewardAsset:  paymaster.NativeAsset,
+				RewardAmount: *uint256.NewInt(reward),
+			},
+			gasLimit: big.NewInt(100),
+		}
+	}
+
+	callFulfilledID := inboxCallFulfilledEventID()
+	fulfilledLog := &types.Log{
+		Address: inbox,
+		Topics:  []common.Hash{callFulfilledID, {1}, common.BytesToHash(wallet.Bytes())},
+	}
+
+	ctrl := gomock.NewController(t)
+	ethClient := mocks.NewMockEthClient(ctrl)
+	ethClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
+		DoAndReturn(func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+			if bytes.Equal(msg.Data, []byte{0xf0, 2}) {
+				return nil, revertError{}
+			}
+			return nil, nil
+		}).
+		Times(3)
+	gomock.InOrder(
+		// Both remaining fulfills share the batch gas, the second one can't pay for its half
+		ethClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil),
+		ethClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(60000), nil),
+	)
+	ethClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(1), nil).Times(2)
+	ethClient.EXPECT().PendingNonceAt(gomock.Any(), wallet).Return(uint64(0), nil)
+	ethClient.EXPECT().
+		SendTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, tx *types.Transaction) error {
+			require.Equal(t, abi.Multicall3Address, *tx.To())
+			return nil
+		})
+	ethClient.EXPECT().
+		TransactionReceipt(gomock.Any(), gomock.Any()).
+		Return(&types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{fulfilledLog}}, nil)
+
+	l := &OutboxListener{
+		logger: zaptest.NewLogger(t),
+		config: &config.Config{Wallets: config.WalletConfig{
+			FromAddress: wallet.Hex(),
+			PrivateKey:  common.Bytes2Hex(crypto.FromECDSA(privateKey)),
+		}},
+	}
+	b := newFulfillBatcher(l, config.FulfillBatchConfig{
+		BatchConfig: config.BatchConfig{Enabled: true, MaxSize: 10, MaxDelay: time.Second},
+	})
+
+	destChain := &client.ChainClient{Client: ethClient, Config: config.ChainConfig{ChainID: testDestChainID}}
+	outcomes := b.sendBatch(context.Background(), destChain, []pendingFulfill{
+		newFulfill(1, 1000000),
+		newFulfill(2, 1000000),
+		newFulfill(3, 50000),
+	})
+	require.Len(t, outcomes, 3)
+
+	require.True(t, outcomes[0].Fulfilled)
+	require.NoError(t, outcomes[0].Err)
+	require.NotEqual(t, common.Hash{}, outcomes[0].TxHash)
+
+	require.False(t, outcomes[1].Fulfilled)
+	require.ErrorContains(t, outcomes[1].Err, "simulating fulfill")
+
+	require.False(t, outcomes[2].Fulfilled)
+	require.ErrorContains(t, outcomes[2].Err, "reward amount is not enough")
+	require.Equal(t, common.Hash{}, outcomes[2].TxHash)
+}
+
+func TestFulfillBatcherReleasesExposureOnlyOnceMined(t *testing.T) {
+	tests := []struct {
+		name        string
+		receipt     *types.Receipt
+		wantMined   bool
+		wantRelease bool
+	}{
+		{
+			// The batch may still be mined after the listener stopped waiting, the request may be fulfilled
+			name:        "receipt unknown",
+			wantMined:   false,
+			wantRelease: false,
+		},
+		{
+			name:        "reverted",
+			receipt:     &types.Receipt{Status: types.ReceiptStatusFailed},
+			wantMined:   true,
+			wantRelease: true,
+		},
+		{
+			name:        "not fulfilled by the batch",
+			receipt:     &types.Receipt{Status: types.ReceiptStatusSuccessful},
+			wantMined:   true,
+			wantRelease: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageID := [32]byte{1}
+			l, logs := newRiskTestListener(t, messageID)
+
+			ctrl := gomock.NewController(t)
+			ethClient := mocks.NewMockEthClient(ctrl)
+			ethClient.EXPECT().CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).Return(nil, nil)
+			ethClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil)
+			ethClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(1), nil)
+			ethClient.EXPECT().PendingNonceAt(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+			ethClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).Return(nil)
+			if tt.receipt != nil {
+				ethClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(tt.receipt, nil)
+			} else {
+				ethClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(nil, ethereum.NotFound).AnyTimes()
+			}
+
+			b := newFulfillBatcher(l, config.FulfillBatchConfig{
+				BatchConfig: config.BatchConfig{Enabled: true, MaxSize: 10, MaxDelay: time.Second},
+			})
+			destChain := &client.ChainClient{Client: ethClient, Config: config.ChainConfig{ChainID: testDestChainID}}
+			inbox := common.HexToAddress("0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb")
+			fulfill := pendingFulfill{
+				messageID: messageID,
+				call:      ethereum.CallMsg{To: &inbox, Data: []byte{0xf0, 1}, Value: big.NewInt(0)},
+				attributes: &MessageAttributes{
+					RewardAsset:  paymaster.NativeAsset,
+					RewardAmount: *uint256.NewInt(1000000),
+				},
+				gasLimit: big.NewInt(100),
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			outcomes := b.sendBatch(ctx, destChain, []pendingFulfill{fulfill})
+
+			require.Len(t, outcomes, 1)
+			require.NotEqual(t, common.Hash{}, outcomes[0].TxHash)
+			require.Equal(t, tt.wantMined, outcomes[0].Mined)
+			require.False(t, outcomes[0].Fulfilled)
+			if tt.wantRelease {
+				require.Equal(t, 1, logs.FilterMessage("Released risk exposure").Len())
+			} else {
+				require.Zero(t, logs.FilterMessage("Released risk exposure").Len())
+				require.Equal(t, 1, logs.FilterMessage("Batched fulfill pending").Len())
+			}
+		})
+	}
+}
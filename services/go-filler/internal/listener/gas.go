@@ -0,0 +1,132 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"go.uber.org/zap"
+)
+
+// GasRequest is what a GasStrategy prices
+type GasRequest struct {
+	GasLimit *big.Int
+	// Reward is the ETH reward of the request and Cost what fulfilling it costs besides gas, both are nil when the
+	// price covers more than one request
+	Reward *big.Int
+	Cost   *big.Int
+}
+
+// GasStrategy sets the gas price of a fulfillment on a destination chain
+type GasStrategy interface {
+	Name() string
+	GasPrice(ctx context.Context, destChain *client.ChainClient, req GasRequest) (*big.Int, error)
+}
+
+// newGasStrategy returns the strategy selected in the chain config
+func newGasStrategy(cfg config.GasStrategyConfig, logger *zap.Logger) (GasStrategy, error) {
+	switch cfg.Name() {
+	case config.GasStrategyNode:
+		return nodeGasStrategy{}, nil
+	case config.GasStrategyFeeHistory:
+		return feeHistoryGasStrategy{percentile: cfg.Percentile, blockCount: cfg.BlockCount}, nil
+	case config.GasStrategyMargin:
+		return marginGasStrategy{base: nodeGasStrategy{}, profitShare: cfg.ProfitShare, logger: logger}, nil
+	default:
+		return nil, fmt.Errorf("unknown gas strategy %q", cfg.Type)
+	}
+}
+
+// nodeGasStrategy uses the node eth_gasPrice suggestion
+type nodeGasStrategy struct{}
+
+func (nodeGasStrategy) Name() string {
+	return config.GasStrategyNode
+}
+
+func (nodeGasStrategy) GasPrice(ctx context.Context, destChain *client.ChainClient, _ GasRequest) (*big.Int, error) {
+	return destChain.Client.SuggestGasPrice(ctx)
+}
+
+// feeHistoryGasStrategy bids the base fee of the next block plus the median over the recent blocks of a percentile of
+// their priority fees
+type feeHistoryGasStrategy struct {
+	percentile float64
+	blockCount uint64
+}
+
+func (feeHistoryGasStrategy) Name() string {
+	return config.GasStrategyFeeHistory
+}
+
+func (s feeHistoryGasStrategy) GasPrice(ctx context.Context, destChain *client.ChainClient, _ GasRequest) (*big.Int, error) {
+	history, err := destChain.Client.FeeHistory(ctx, s.blockCount, nil, []float64{s.percentile})
+	if err != nil {
+		return nil, fmt.Errorf("getting fee history: %w", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, errors.New("fee history has no base fee")
+	}
+
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for _, reward := range history.Reward {
+		if len(reward) > 0 && reward[0] != nil {
+			tips = append(tips, reward[0])
+		}
+	}
+
+	// The last base fee is the one of the next block
+	gasPrice := new(big.Int).Set(history.BaseFee[len(history.BaseFee)-1])
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+		gasPrice.Add(gasPrice, tips[len(tips)/2])
+	}
+
+	return gasPrice, nil
+}
+
+// marginGasStrategy raises the base price so that profitShare of the expected profit of the request at the base price
+// goes to gas. Requests that are not profitable at the base price, or prices covering several requests, keep the base
+// price.
+type marginGasStrategy struct {
+	base        GasStrategy
+	profitShare float64
+	logger      *zap.Logger
+}
+
+func (marginGasStrategy) Name() string {
+	return config.GasStrategyMargin
+}
+
+func (s marginGasStrategy) GasPrice(ctx context.Context, destChain *client.ChainClient, req GasRequest) (*big.Int, error) {
+	basePrice, err := s.base.GasPrice(ctx, destChain, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Reward == nil || req.GasLimit.Sign() == 0 {
+		return basePrice, nil
+	}
+
+	profit := new(big.Int).Sub(req.Reward, req.Cost)
+	profit.Sub(profit, new(big.Int).Mul(req.GasLimit, basePrice))
+	if profit.Sign() <= 0 {
+		return basePrice, nil
+	}
+
+	bid, _ := new(big.Float).Mul(new(big.Float).SetInt(profit), big.NewFloat(s.profitShare)).Int(nil)
+	bid.Div(bid, req.GasLimit)
+
+	s.logger.Info("Bidding share of expected profit",
+		zap.Stringer("expected_profit", profit),
+		zap.Float64("profit_share", s.profitShare),
+		zap.Stringer("base_gas_price", basePrice),
+		zap.Stringer("gas_price_increase", bid),
+	)
+
+	return bid.Add(bid, basePrice), nil
+}
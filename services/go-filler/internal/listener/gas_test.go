@@ -0,0 +1,112 @@
+package listener
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/paymaster"
+)
+
+func TestNewGasStrategy(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	for _, cfg := range []config.GasStrategyConfig{
+		{},
+		{Type: config.GasStrategyNode},
+		{Type: config.GasStrategyFeeHistory, Percentile: 50, BlockCount: 10},
+		{Type: config.GasStrategyMargin, ProfitShare: 0.5},
+	} {
+		strategy, err := newGasStrategy(cfg, logger)
+		require.NoError(t, err)
+		require.Equal(t, cfg.Name(), strategy.Name())
+	}
+
+	_, err := newGasStrategy(config.GasStrategyConfig{Type: "fastest"}, logger)
+	require.ErrorContains(t, err, "unknown gas strategy")
+}
+
+func TestFeeHistoryGasStrategy(t *testing.T) {
+	ethClient := mocks.NewMockEthClient(gomock.NewController(t))
+	ethClient.EXPECT().
+		FeeHistory(gomock.Any(), uint64(3), gomock.Nil(), []float64{75}).
+		Return(&ethereum.FeeHistory{
+			Reward:  [][]*big.Int{{big.NewInt(5)}, {big.NewInt(1)}, {big.NewInt(3)}},
+			BaseFee: []*big.Int{big.NewInt(90), big.NewInt(95), big.NewInt(100), big.NewInt(110)},
+		}, nil)
+	destChain := &client.ChainClient{Client: ethClient}
+
+	strategy := feeHistoryGasStrategy{percentile: 75, blockCount: 3}
+	gasPrice, err := strategy.GasPrice(context.Background(), destChain, GasRequest{GasLimit: big.NewInt(100)})
+	require.NoError(t, err)
+	// Next base fee plus the median tip
+	require.Equal(t, big.NewInt(113), gasPrice)
+}
+
+func TestMarginGasStrategy(t *testing.T) {
+	ethClient := mocks.NewMockEthClient(gomock.NewController(t))
+	ethClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(10), nil).AnyTimes()
+	destChain := &client.ChainClient{Client: ethClient}
+	ctx := context.Background()
+
+	strategy := marginGasStrategy{base: nodeGasStrategy{}, profitShare: 0.5, logger: zaptest.NewLogger(t)}
+
+	tests := []struct {
+		name string
+		req  GasRequest
+		want int64
+	}{
+		{
+			// Profit of 10000 - 2000 - 100 * 10 = 7000, half of it over 100 gas
+			name: "profitable",
+			req:  GasRequest{GasLimit: big.NewInt(100), Reward: big.NewInt(10000), Cost: big.NewInt(2000)},
+			want: 45,
+		},
+		{
+			name: "unprofitable",
+			req:  GasRequest{GasLimit: big.NewInt(100), Reward: big.NewInt(1000), Cost: big.NewInt(2000)},
+			want: 10,
+		},
+		{
+			name: "several requests",
+			req:  GasRequest{GasLimit: big.NewInt(100)},
+			want: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasPrice, err := strategy.GasPrice(ctx, destChain, tt.req)
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(tt.want), gasPrice)
+		})
+	}
+}
+
+func TestNewGasRequest(t *testing.T) {
+	call := ethereum.CallMsg{Value: big.NewInt(300)}
+	attributes := &MessageAttributes{
+		RewardAsset:      paymaster.NativeAsset,
+		RewardAmount:     *uint256.NewInt(10000),
+		MagicSpendAmount: *uint256.NewInt(200),
+	}
+
+	req := newGasRequest(call, attributes, big.NewInt(100))
+	require.Equal(t, big.NewInt(10000), req.Reward)
+	require.Equal(t, big.NewInt(500), req.Cost)
+
+	// The profit of a token reward is unknown
+	attributes.RewardAsset = common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	require.Nil(t, newGasRequest(call, attributes, big.NewInt(100)).Reward)
+	require.Nil(t, newGasRequest(call, nil, big.NewInt(100)).Reward)
+}
@@ -0,0 +1,122 @@
+package listener
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/paymaster"
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_inbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	internalpaymaster "github.com/base-org/RRC-7755-poc/internal/paymaster"
+)
+
+func magicSpendRequestAttribute(token common.Address, amount *big.Int) []byte {
+	attr := make([]byte, attributeExtendedSize)
+	binary.BigEndian.PutUint32(attr[0:], magicSpendRequestAttributeSelector)
+	copy(attr[selectorSize+addressOffset:attributeBaseSize], token[:])
+	amount.FillBytes(attr[attributeBaseSize:attributeExtendedSize])
+	return attr
+}
+
+func TestParseAttributesMagicSpendRequest(t *testing.T) {
+	attributes, err := parseAttributes([][]byte{
+		magicSpendRequestAttribute(internalpaymaster.NativeAsset, big.NewInt(testValue)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, internalpaymaster.NativeAsset, attributes.MagicSpendToken)
+	require.Equal(t, big.NewInt(testValue), attributes.MagicSpendAmount.ToBig())
+
+	_, err = parseAttributes([][]byte{
+		magicSpendRequestAttribute(internalpaymaster.NativeAsset, big.NewInt(testValue))[:attributeBaseSize],
+	})
+	require.ErrorContains(t, err, "magicSpendRequest attribute too short")
+}
+
+func TestValidateRewardCountsMagicSpend(t *testing.T) {
+	l := &OutboxListener{logger: zaptest.NewLogger(t)}
+	call := ethereum.CallMsg{Value: big.NewInt(100)}
+	gas := GasLimitAndPrice{GasLimit: big.NewInt(100), GasPrice: big.NewInt(1)}
+
+	newAttributes := func(token common.Address, magicSpend uint64) *MessageAttributes {
+		attributes := &MessageAttributes{
+			RewardAsset:     internalpaymaster.NativeAsset,
+			RewardAmount:    *uint256.NewInt(1000),
+			MagicSpendToken: token,
+		}
+		attributes.MagicSpendAmount.SetUint64(magicSpend)
+		return attributes
+	}
+
+	require.NoError(t, l.validateReward(call, newAttributes(internalpaymaster.NativeAsset, 700), gas))
+
+	err := l.validateReward(call, newAttributes(internalpaymaster.NativeAsset, 850), gas)
+	require.ErrorContains(t, err, "required minimum: 1050, provided: 1000")
+
+	err = l.validateReward(call, newAttributes(common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e"), 1), gas)
+	require.ErrorContains(t, err, "is not ETH")
+}
+
+// newMagicSpendTestClient serves the inbox PAYMASTER and paymaster getMagicSpendBalance calls
+func newMagicSpendTestClient(t *testing.T, ctrl *gomock.Controller, balance *big.Int) *mocks.MockEthClient {
+	inboxABI, err := rrc_7755_inbox.RRC7755InboxMetaData.GetAbi()
+	require.NoError(t, err)
+	paymasterABI, err := paymaster.PaymasterMetaData.GetAbi()
+	require.NoError(t, err)
+
+	ethClient := mocks.NewMockEthClient(ctrl)
+	ethClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+			switch {
+			case bytes.HasPrefix(msg.Data, inboxABI.Methods["PAYMASTER"].ID):
+				return inboxABI.Methods["PAYMASTER"].Outputs.Pack(common.HexToAddress("0x1234"))
+			case bytes.HasPrefix(msg.Data, paymasterABI.Methods["getMagicSpendBalance"].ID):
+				return paymasterABI.Methods["getMagicSpendBalance"].Outputs.Pack(balance)
+			}
+			t.Fatalf("unexpected call %x", msg.Data)
+			return nil, nil
+		}).
+		AnyTimes()
+	return ethClient
+}
+
+func TestCheckMagicSpendBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	destChain := &client.ChainClient{
+		Client: newMagicSpendTestClient(t, ctrl, big.NewInt(500)),
+		Config: config.ChainConfig{
+			ChainID:      testDestChainID,
+			InboxAddress: common.HexToAddress("0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb"),
+		},
+	}
+	cfg := &config.Config{}
+	l, err := NewOutboxListener(context.Background(), &client.Manager{
+		Chains: map[uint64]*client.ChainClient{testDestChainID: destChain},
+	}, cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	attributes := &MessageAttributes{MagicSpendToken: internalpaymaster.NativeAsset}
+
+	// Ops without magic spend don't read the balance
+	require.NoError(t, l.checkMagicSpendBalance(context.Background(), destChain, attributes))
+
+	attributes.MagicSpendAmount.SetUint64(500)
+	require.NoError(t, l.checkMagicSpendBalance(context.Background(), destChain, attributes))
+
+	attributes.MagicSpendAmount.SetUint64(501)
+	err = l.checkMagicSpendBalance(context.Background(), destChain, attributes)
+	require.ErrorContains(t, err, "magic spend balance is not enough")
+}
@@ -7,19 +7,26 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/base-org/RRC-7755-poc/bindings/entrypoint"
 	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_inbox"
 	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
 	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/audit"
 	"github.com/base-org/RRC-7755-poc/internal/client"
 	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/market"
+	"github.com/base-org/RRC-7755-poc/internal/paymaster"
+	"github.com/base-org/RRC-7755-poc/internal/policy"
+	"github.com/base-org/RRC-7755-poc/internal/reconcile"
+	"github.com/base-org/RRC-7755-poc/internal/risk"
+	"github.com/base-org/RRC-7755-poc/internal/screening"
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/holiman/uint256"
 	"go.uber.org/zap"
 )
@@ -31,11 +38,45 @@ type OutboxListener struct {
 	config    *config.Config
 	logger    *zap.Logger
 	clientMgr *client.Manager
+
+	// shadow is set when running in shadow mode, in which case decisions are recorded instead of broadcast
+	shadow *shadowRecorder
+	// paymaster checks the paymaster balances before each UserOp
+	paymaster *paymaster.Service
+	// reconciler records the decision taken for each request when set
+	reconciler *reconcile.Service
+	// market follows the competing fulfillers and sets the gas price bid when set
+	market *market.Watcher
+	// policy filters requests before they are priced when set
+	policy *policy.Engine
+	// screening screens the addresses of validated requests when set
+	screening *screening.Service
+	// risk enforces the exposure and requester limits when set
+	risk *risk.Manager
+	// decisions appends a record of every decision when set
+	decisions *audit.Sink
+	// batcher groups UserOps into handleOps batches when set
+	batcher *userOpBatcher
+	// fulfillBatcher groups inbox fulfills into multicall batches when set
+	fulfillBatcher *fulfillBatcher
+	// receipts releases the exposure of the reverted direct fulfillments, set along with risk
+	receipts *receiptWatcher
+	// sendMu keeps the nonce of a transaction from being reused by a concurrent batch or paymaster transaction
+	sendMu sync.Mutex
+	// canceled holds the messages canceled on the watched outboxes
+	canceled *canceledMessages
+	// now is the clock requests expiries are checked against
+	now func() time.Time
+
+	// configMu guards config, which ApplyConfig replaces on reload
+	configMu sync.RWMutex
+	// reload signals Run to resubscribe after ApplyConfig changed the chains
+	reload chan struct{}
 }
 
 type combinedMsgPostedPayload struct {
 	msgPosted *rrc_7755_outbox.RRC7755OutboxMessagePosted
-	chain     *client.ChainClient
+	chainID   uint64
 }
 
 type MessageAttributes struct {
@@ -46,6 +87,10 @@ type MessageAttributes struct {
 	Expiry        uint256.Int
 	Requester     [32]byte
 	L2Oracle      common.Address
+
+	// MagicSpendToken and MagicSpendAmount are what a UserOp withdraws from the fulfiller paymaster balance
+	MagicSpendToken  common.Address
+	MagicSpendAmount uint256.Int
 }
 
 type ParsedMessage struct {
@@ -72,12 +117,133 @@ func NewOutboxListener(
 	clientMgr *client.Manager,
 	config *config.Config,
 	logger *zap.Logger,
-) (Service, error) {
-	return &OutboxListener{
+) (*OutboxListener, error) {
+	l := &OutboxListener{
 		config:    config,
 		logger:    logger,
 		clientMgr: clientMgr,
-	}, nil
+		reload:    make(chan struct{}, 1),
+		canceled:  newCanceledMessages(canceledMessagesSize),
+		now:       time.Now,
+	}
+
+	if config.Shadow.Enabled {
+		shadow, err := newShadowRecorder(logger, config.Shadow.OutputPath)
+		if err != nil {
+			return nil, err
+		}
+		l.shadow = shadow
+
+		logger.Info("Shadow mode enabled, transactions will not be broadcast",
+			zap.String("output_path", config.Shadow.OutputPath))
+	}
+
+	// The paymaster balances are always read before UserOps, they are only managed when enabled. Its transactions are
+	// sent from the fulfiller wallet too, under the same nonce lock.
+	l.paymaster = paymaster.NewService(clientMgr, config, &l.sendMu, logger)
+
+	if config.Reconcile.Enabled {
+		reconciler, err := reconcile.NewService(clientMgr, config, logger)
+		if err != nil {
+			return nil, err
+		}
+		l.reconciler = reconciler
+	}
+
+	if config.Market.Enabled {
+		watcher, err := market.NewWatcher(clientMgr, config, logger)
+		if err != nil {
+			return nil, err
+		}
+		l.market = watcher
+	}
+
+	if config.Policy.Enabled {
+		engine, err := policy.NewEngine(config.Policy, logger)
+		if err != nil {
+			return nil, err
+		}
+		l.policy = engine
+	}
+
+	if config.Screening.Enabled {
+		service, err := screening.NewService(config.Screening, logger)
+		if err != nil {
+			return nil, err
+		}
+		l.screening = service
+	}
+
+	if config.Risk.Enabled {
+		manager, err := risk.NewManager(clientMgr, config, logger)
+		if err != nil {
+			return nil, err
+		}
+		l.risk = manager
+		l.receipts = newReceiptWatcher(l)
+	}
+
+	if config.DecisionLog.Enabled {
+		sink, err := audit.OpenSink(config.DecisionLog.Path, config.DecisionLog.MaxSizeMB, config.DecisionLog.MaxFiles)
+		if err != nil {
+			return nil, err
+		}
+		l.decisions = sink
+	}
+
+	if config.UserOpBatch.Enabled {
+		l.batcher = newUserOpBatcher(l, config.UserOpBatch)
+	}
+	if config.FulfillBatch.Enabled {
+		l.fulfillBatcher = newFulfillBatcher(l, config.FulfillBatch)
+	}
+
+	return l, nil
+}
+
+// currentConfig returns the config of the last applied reload
+func (l *OutboxListener) currentConfig() *config.Config {
+	l.configMu.RLock()
+	defer l.configMu.RUnlock()
+	return l.config
+}
+
+// Close sends the pending batches, Run or Backfill having returned, then releases the resources held by the listener
+func (l *OutboxListener) Close() error {
+	// The batches are sent and the receipts read before the risk manager is closed as failed sends release their
+	// exposure
+	flushCtx, cancel := context.WithTimeout(context.Background(), batchShutdownTimeout)
+	defer cancel()
+	if l.batcher != nil {
+		l.batcher.Close(flushCtx)
+	}
+	if l.fulfillBatcher != nil {
+		l.fulfillBatcher.Close(flushCtx)
+	}
+	if l.receipts != nil {
+		l.receipts.Close(flushCtx)
+	}
+
+	var errs []error
+	if l.shadow != nil {
+		errs = append(errs, l.shadow.Close())
+	}
+	if l.reconciler != nil {
+		errs = append(errs, l.reconciler.Close())
+	}
+	if l.market != nil {
+		errs = append(errs, l.market.Close())
+	}
+	if l.risk != nil {
+		errs = append(errs, l.risk.Close())
+	}
+	if l.screening != nil {
+		errs = append(errs, l.screening.Close())
+	}
+	if l.decisions != nil {
+		errs = append(errs, l.decisions.Close())
+	}
+	return errors.Join(errs...)
 }
 
 func (l *OutboxListener) ServiceName() string {
@@ -86,87 +252,234 @@ func (l *OutboxListener) ServiceName() string {
 
 //nolint:cyclomatic,cognitive-complexity
 func (l *OutboxListener) Run(ctx context.Context) error {
-	var wg sync.WaitGroup
+	subs := newOutboxSubscriptions(l.logger, crossChainCallRequestedBufferSize, l.canceled)
+	defer subs.stopAll()
 
-	combinedMsgPostedChan := make(chan combinedMsgPostedPayload, crossChainCallRequestedBufferSize)
+	if err := subs.sync(ctx, l.clientMgr.GetAllClients()); err != nil {
+		return err
+	}
 
-	for _, chain := range l.clientMgr.GetAllClients() {
-		for _, address := range chain.Config.OutboxAddresses {
-			outbox, err := rrc_7755_outbox.NewRRC7755OutboxFilterer(
-				address,
-				chain.Client,
-			)
-			if err != nil {
-				return fmt.Errorf("[DEBUG] creating outbox contract on chain %d: %w", chain.Config.ChainID, err)
+	if l.paymaster != nil && l.currentConfig().Paymaster.Enabled {
+		go func() {
+			if err := l.paymaster.Run(ctx); err != nil {
+				l.logger.Error("Running paymaster service", zap.Error(err))
 			}
+		}()
+	}
 
-			msgPostedChan := make(chan *rrc_7755_outbox.RRC7755OutboxMessagePosted, crossChainCallRequestedBufferSize)
-
-
-			// For real-time events, don't set Start block - this will watch from the latest block
-			// which avoids the "exceed maximum block range" error
-			subscription, err := outbox.WatchMessagePosted(&bind.WatchOpts{Context: ctx}, msgPostedChan, [][32]byte{})
-			if err != nil {
-				return fmt.Errorf("creating WatchMessagePosted subscription on chain %d: %w", chain.Config.ChainID, err)
+	// The reconciler, market watcher and risk manager subscribe to the chains they find when they start, they are
+	// restarted on reload to follow the reloaded chains
+	var services []Service
+	if l.reconciler != nil {
+		services = append(services, l.reconciler)
+	}
+	if l.market != nil {
+		services = append(services, l.market)
+	}
+	if l.risk != nil {
+		services = append(services, l.risk)
+	}
+	watchers := newChainWatchers(l.logger, services)
+	watchers.start(ctx)
+	defer watchers.stop()
+
+	if l.screening != nil {
+		go func() {
+			if err := l.screening.Run(ctx); err != nil {
+				l.logger.Error("Running screening", zap.Error(err))
 			}
-			l.logger.Info(
-				"Started outbox WatchMessagePosted",
-				zap.Uint64("chain_id", chain.Config.ChainID),
-				zap.String("outbox_address", address.Hex()),
-			)
-			defer subscription.Unsubscribe()
-
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-
-				for {
-					select {
-					case m := <-msgPostedChan:
-						combinedMsgPostedChan <- combinedMsgPostedPayload{msgPosted: m, chain: chain}
-					case <-ctx.Done():
-						return
-					}
-				}
-			}()
-		}
+		}()
 	}
 
 loop:
 	for {
 		select {
-		case c := <-combinedMsgPostedChan:
-			l.logger.Info("Received message posted log", zap.Any("event", c.msgPosted), zap.Uint64("chain_id", c.chain.Config.ChainID))
-			err := l.processMessagePosted(ctx, c.chain, c.msgPosted)
+		case c := <-subs.out:
+			l.logger.Info("Received message posted log", zap.Any("event", c.msgPosted), zap.Uint64("chain_id", c.chainID))
+			// The chain is looked up again as a reload may have changed its config since the log was received
+			chain, err := l.clientMgr.GetChainClient(c.chainID)
+			if err != nil {
+				l.logger.Warn("Dropping message posted log of removed chain", zap.Uint64("chain_id", c.chainID))
+				continue
+			}
+			err = l.processMessagePosted(ctx, chain, c.msgPosted)
 			if err != nil {
 				l.logger.Error("Processing message posted", zap.Error(err))
 			}
+			l.trackRequest(c.msgPosted, err)
+		case <-l.reload:
+			if err := subs.sync(ctx, l.clientMgr.GetAllClients()); err != nil {
+				l.logger.Error("Resubscribing outboxes after config reload", zap.Error(err))
+			}
+			watchers.restart(ctx)
 		case <-ctx.Done():
 			break loop
 		}
 	}
 
-	wg.Wait()
+	return nil
+}
+
+// ApplyConfig applies a reloaded config without restarting: changed chains are reconnected through the client
+// manager, the running Run loop resubscribes to the affected outboxes and restarts the reconciler, market watcher and
+// risk manager on the reloaded chains. The screening list is reloaded. Wallet, shadow, paymaster, batch, reconcile,
+// market, risk, policy, screening and decision log settings keep their startup values.
+func (l *OutboxListener) ApplyConfig(ctx context.Context, cfg *config.Config) error {
+	diff, err := l.clientMgr.Apply(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("applying chain config: %w", err)
+	}
+
+	current := l.currentConfig()
+	if cfg.Wallets != current.Wallets || cfg.Shadow != current.Shadow || !reflect.DeepEqual(cfg.Paymaster, current.Paymaster) ||
+		cfg.UserOpBatch != current.UserOpBatch || cfg.FulfillBatch != current.FulfillBatch ||
+		cfg.Reconcile != current.Reconcile || cfg.Market != current.Market ||
+		!reflect.DeepEqual(cfg.Risk, current.Risk) || !reflect.DeepEqual(cfg.Policy, current.Policy) ||
+		cfg.Screening != current.Screening || cfg.DecisionLog != current.DecisionLog {
+		l.logger.Warn("Wallet, shadow, paymaster, batch, reconcile, market, risk, policy, screening and decision log config changes require a restart and were not applied")
+	}
+
+	// Only the chains and proof settings are reloaded, the others keep the values the services were started with
+	applied := *current
+	applied.Chain = cfg.Chain
+	applied.Proof = cfg.Proof
+
+	l.configMu.Lock()
+	l.config = &applied
+	l.configMu.Unlock()
+
+	if l.screening != nil {
+		if err := l.screening.Reload(); err != nil {
+			l.logger.Error("Reloading screening list", zap.Error(err))
+		}
+	}
+
+	if diff.Empty() {
+		l.logger.Info("Config reloaded, no chain changes")
+		return nil
+	}
+
+	l.logger.Info("Config reloaded",
+		zap.Uint64s("added_chains", diff.Added),
+		zap.Uint64s("removed_chains", diff.Removed),
+		zap.Uint64s("reconnected_chains", diff.Reconnected),
+		zap.Uint64s("updated_chains", diff.Updated),
+	)
+
+	select {
+	case l.reload <- struct{}{}:
+	default:
+		// A resubscription is already pending, it will pick up this config as well
+	}
 
 	return nil
 }
 
+// trackRequest records with the reconciler whether the request was fulfilled, err being the reason it was skipped.
+// Requests decided in shadow mode are never fulfilled.
+func (l *OutboxListener) trackRequest(event *rrc_7755_outbox.RRC7755OutboxMessagePosted, err error) {
+	if l.reconciler == nil {
+		return
+	}
+	if err == nil && l.shadow != nil {
+		err = errors.New("shadow mode")
+	}
+
+	parsed := parseMessage(event)
+	l.reconciler.TrackRequest(event.MessageId, parsed.SourceChain, parsed.DestinationChain, rewardAttributes(event), err)
+}
+
+// rewardAttributes returns the attributes the outbox reads the reward of the request from: those of the message, or
+// the ones in the paymaster data of its UserOp. It returns nil when the UserOp can't be decoded.
+func rewardAttributes(event *rrc_7755_outbox.RRC7755OutboxMessagePosted) [][]byte {
+	if len(event.Attributes) > 0 {
+		return event.Attributes
+	}
+
+	op, err := abi.UnmarshalPackedUserOperation(event.Payload)
+	if err != nil {
+		return nil
+	}
+	attributes, err := op.GetPaymasterData()
+	if err != nil {
+		return nil
+	}
+	return attributes
+}
+
+// processMessagePosted fulfills the request when it passes every check and records the decision. In shadow mode a
+// shadow decision is recorded for every request, whichever check rejected it.
 func (l *OutboxListener) processMessagePosted(
 	ctx context.Context,
 	sourceChain *client.ChainClient,
 	event *rrc_7755_outbox.RRC7755OutboxMessagePosted,
 ) error {
+	// A reload closes the connection of a replaced chain only once the request is decided
+	defer sourceChain.Acquire()()
+
+	rec := newDecisionRecord(event, sourceChain.Config.ChainID, l.now())
+	err := l.decide(ctx, sourceChain, event, rec)
+	if l.shadow != nil {
+		if err := l.shadow.Record(newShadowDecision(event, rec, err)); err != nil {
+			l.logger.Error("Recording shadow decision", zap.Error(err))
+		}
+	}
+	l.recordDecision(rec, err)
+	return err
+}
+
+func (l *OutboxListener) decide(
+	ctx context.Context,
+	sourceChain *client.ChainClient,
+	event *rrc_7755_outbox.RRC7755OutboxMessagePosted,
+	rec *audit.DecisionRecord,
+) error {
+	if l.market != nil {
+		l.market.ObservePosted(event.MessageId, sourceChain.Config.ChainID, event.Raw.BlockNumber)
+	}
+
 	parsed, err := l.ValidateMessagePosted(ctx, sourceChain, event)
-	if err != nil {
+	if err := rec.Check(audit.CheckValidate, err); err != nil {
 		l.logger.Error("Validating message posted", zap.Error(err))
 		return err
 	}
+	rec.Inputs = decisionInputs(parsed)
+
+	if l.screening != nil {
+		if err := rec.Check(audit.CheckScreening, l.screen(ctx, event.MessageId, parsed)); err != nil {
+			l.logger.Error("Screening request", zap.Error(err))
+			return fmt.Errorf("screening request: %w", err)
+		}
+	}
 
 	var (
 		call       ethereum.CallMsg
 		attributes *MessageAttributes
 	)
-	destChain := l.clientMgr.GetAllClients()[parsed.DestinationChain]
+	destChain, ok := l.clientMgr.GetAllClients()[parsed.DestinationChain]
+	if !ok {
+		return fmt.Errorf("destination chain is not configured: %d", parsed.DestinationChain)
+	}
+	defer destChain.Acquire()()
+
+	attributes = parsed.Attributes
+	if parsed.ParsedUserOp != nil {
+		attributes = parsed.UserOpAttributes
+	}
+
+	if err := rec.Check(audit.CheckExpiry, validateExpiry(attributes, destChain, l.now())); err != nil {
+		l.logger.Error("Validating expiry", zap.Error(err))
+		return fmt.Errorf("validating expiry: %w", err)
+	}
+
+	if l.policy != nil {
+		err := l.evaluatePolicy(event.MessageId, parsed, attributes)
+		rec.Policy = newPolicyOutcome(err)
+		if err := rec.Check(audit.CheckPolicy, err); err != nil {
+			l.logger.Error("Evaluating policy", zap.Error(err))
+			return fmt.Errorf("evaluating policy: %w", err)
+		}
+	}
 
 	if parsed.ParsedUserOp == nil {
 		call, err = l.createCallMsg(parsed)
@@ -174,8 +487,6 @@ func (l *OutboxListener) processMessagePosted(
 			l.logger.Error("Creating EOA call message", zap.Error(err))
 			return fmt.Errorf("creating EOA call message: %w", err)
 		}
-
-		attributes = parsed.Attributes
 	} else {
 		call, err = l.createUserOpCallMsg(parsed)
 		if err != nil {
@@ -183,39 +494,249 @@ func (l *OutboxListener) processMessagePosted(
 			return fmt.Errorf("creating user op call message: %w", err)
 		}
 
-		attributes = parsed.UserOpAttributes
+		if l.paymaster != nil {
+			balances, err := l.paymaster.EnsureBalances(ctx, destChain)
+			if err != nil {
+				l.logger.Error("Ensuring paymaster balances", zap.Error(err))
+				return fmt.Errorf("ensuring paymaster balances: %w", err)
+			}
+			l.logger.Info("Paymaster balances",
+				zap.Stringer("gas_balance", balances.Gas),
+				zap.Stringer("magic_spend_balance", balances.MagicSpend),
+			)
+
+			if err := rec.Check(audit.CheckMagicSpend, l.checkMagicSpendBalance(ctx, destChain, attributes)); err != nil {
+				l.logger.Error("Checking magic spend balance", zap.Error(err))
+				return fmt.Errorf("checking magic spend balance: %w", err)
+			}
+		}
+
+		if err := rec.Check(audit.CheckUserOp, l.validateUserOp(ctx, destChain, parsed.ParsedUserOp, call)); err != nil {
+			l.logger.Error("Validating user op", zap.Error(err))
+			return fmt.Errorf("validating user op: %w", err)
+		}
 	}
 
-	l.logger.Info("Formed call message", zap.Any("call", call))
+	l.logger.Info("Formed call message",
+		zap.String("from", call.From.Hex()),
+		zap.Stringer("to", call.To),
+		zap.Stringer("value", call.Value),
+		zap.Int("data_size", len(call.Data)),
+	)
+	rec.Call = decisionCall(call)
+
+	var exposure *risk.Exposure
+	if l.risk != nil {
+		exposure = newExposure(event, parsed, call, attributes)
+		if err := rec.Check(audit.CheckRisk, l.risk.Check(exposure)); err != nil {
+			l.logger.Error("Checking risk limits", zap.Error(err))
+			return fmt.Errorf("checking risk limits: %w", err)
+		}
+	}
 
-	gasLimitAndPrice, err := l.getGasLimitAndPrice(ctx, destChain, call)
+	gasLimitAndPrice, err := l.getGasLimitAndPrice(ctx, destChain, call, attributes)
 	if err != nil {
 		l.logger.Error("Getting gas limit and price", zap.Error(err))
 		return fmt.Errorf("getting gas limit and price: %w", err)
 	}
+	rec.Gas = newGasQuote(gasLimitAndPrice)
+	rec.Reward = newRewardMath(call, attributes, gasLimitAndPrice)
 
-	if err := l.validateReward(call, attributes, gasLimitAndPrice); err != nil {
+	err = l.validateReward(call, attributes, gasLimitAndPrice)
+	if err == nil && parsed.ParsedUserOp != nil {
+		err = l.validateUserOpGas(parsed.ParsedUserOp, attributes, gasLimitAndPrice)
+	}
+	if err := rec.Check(audit.CheckReward, err); err != nil {
 		l.logger.Error("Validating reward", zap.Error(err))
 		return fmt.Errorf("validating reward: %w", err)
 	}
 
-	if err := l.SendTransaction(ctx, destChain, call, gasLimitAndPrice); err != nil {
+	if l.market != nil {
+		gasLimitAndPrice = l.outbid(destChain, parsed, call, attributes, gasLimitAndPrice)
+		rec.Gas.GasPrice = gasLimitAndPrice.GasPrice
+		rec.Reward = newRewardMath(call, attributes, gasLimitAndPrice)
+	}
+
+	if l.shadow != nil {
+		rec.Outcome = audit.OutcomeShadow
+		return nil
+	}
+
+	err = l.checkMessageStatus(ctx, sourceChain, event.Raw.Address, event.MessageId)
+	if err := rec.Check(audit.CheckMessageStatus, err); err != nil {
+		l.logger.Error("Checking message status", zap.Error(err))
+		return fmt.Errorf("checking message status: %w", err)
+	}
+
+	if l.risk != nil {
+		if err := rec.Check(audit.CheckRisk, l.risk.Reserve(exposure)); err != nil {
+			l.logger.Error("Reserving risk exposure", zap.Error(err))
+			return fmt.Errorf("reserving risk exposure: %w", err)
+		}
+	}
+
+	if parsed.ParsedUserOp != nil && l.batcher != nil {
+		l.batcher.Add(destChain, pendingUserOp{messageID: event.MessageId, op: parsed.ParsedUserOp})
+		rec.Outcome = audit.OutcomeBatched
+		return nil
+	}
+
+	if parsed.ParsedUserOp == nil && l.fulfillBatcher != nil && canBatchFulfill(parsed.RawAttributes) {
+		l.fulfillBatcher.Add(destChain, pendingFulfill{
+			messageID:  event.MessageId,
+			call:       call,
+			attributes: attributes,
+			gasLimit:   gasLimitAndPrice.GasLimit,
+		})
+		rec.Outcome = audit.OutcomeBatched
+		return nil
+	}
+
+	tx, err := l.SendTransaction(ctx, destChain, call, gasLimitAndPrice)
+	if err != nil {
 		l.logger.Error("Sending transaction", zap.Error(err))
+		l.releaseExposure(event.MessageId)
+		rec.Outcome = audit.OutcomeFailed
 		return fmt.Errorf("sending transaction: %w", err)
 	}
+	rec.Outcome = audit.OutcomeFulfilled
+	rec.TxHash = txHash(tx.Hash())
+
+	if l.receipts != nil {
+		l.receipts.Watch(destChain, event.MessageId, tx)
+	}
+
+	return nil
+}
+
+// newExposure is the capital fronted to fulfill a request: the value forwarded to its calls and the magic spend
+// withdrawn from our paymaster balance
+func newExposure(
+	event *rrc_7755_outbox.RRC7755OutboxMessagePosted,
+	parsed *ParsedMessage,
+	call ethereum.CallMsg,
+	attributes *MessageAttributes,
+) *risk.Exposure {
+	amount := new(big.Int).Add(attributes.MagicSpendAmount.ToBig(), call.Value)
+
+	return &risk.Exposure{
+		MessageID:        event.MessageId,
+		SourceChain:      parsed.SourceChain,
+		DestinationChain: parsed.DestinationChain,
+		Outbox:           event.Raw.Address,
+		Requester:        attributes.Requester,
+		Amount:           amount,
+	}
+}
+
+// releaseExposure releases the exposure of a request whose fulfillment failed
+func (l *OutboxListener) releaseExposure(messageID [32]byte) {
+	if l.risk != nil {
+		l.risk.Release(messageID, risk.ReleaseFailed)
+	}
+}
+
+// evaluatePolicy runs the policy on the calls of the request. A UserOp is evaluated as a single call of its callData
+// to its sender.
+func (l *OutboxListener) evaluatePolicy(messageID [32]byte, parsed *ParsedMessage, attributes *MessageAttributes) error {
+	req := &policy.Request{MessageID: messageID, Requester: attributes.Requester}
+
+	if parsed.ParsedUserOp != nil {
+		req.Calls = []policy.Call{{
+			To:   common.BytesToHash(parsed.ParsedUserOp.Sender.Bytes()),
+			Data: parsed.ParsedUserOp.CallData,
+		}}
+	} else {
+		calls, err := abi.UnmarshalCalls(parsed.Payload)
+		if err != nil {
+			return fmt.Errorf("unmarshalling calls: %w", err)
+		}
+		for _, call := range calls {
+			req.Calls = append(req.Calls, policy.Call{To: call.To, Data: call.Data})
+		}
+	}
+
+	return l.policy.Evaluate(req)
+}
+
+// checkMagicSpendBalance rejects a UserOp requesting more magic spend than our paymaster balance holds, which would
+// otherwise only fail inside handleOps
+func (l *OutboxListener) checkMagicSpendBalance(
+	ctx context.Context,
+	destChain *client.ChainClient,
+	attributes *MessageAttributes,
+) error {
+	requested := attributes.MagicSpendAmount.ToBig()
+	if requested.Sign() == 0 {
+		return nil
+	}
+
+	balance, err := l.paymaster.MagicSpendBalance(ctx, destChain, attributes.MagicSpendToken)
+	if err != nil {
+		return err
+	}
+
+	if balance.Cmp(requested) < 0 {
+		return fmt.Errorf(
+			"magic spend balance is not enough, token: %s, balance: %d, requested: %d",
+			attributes.MagicSpendToken.Hex(),
+			balance,
+			requested,
+		)
+	}
 
 	return nil
 }
 
+// outbid raises the gas price to the market bid when the request is still profitable at that price, otherwise it is
+// left to the node suggestion
+func (l *OutboxListener) outbid(
+	destChain *client.ChainClient,
+	parsed *ParsedMessage,
+	call ethereum.CallMsg,
+	attributes *MessageAttributes,
+	gasLimitAndPrice GasLimitAndPrice,
+) GasLimitAndPrice {
+	bid := l.market.BidGasPrice(destChain.Config.ChainID, gasLimitAndPrice.GasPrice)
+	if bid.Cmp(gasLimitAndPrice.GasPrice) == 0 {
+		return gasLimitAndPrice
+	}
+
+	outbid := GasLimitAndPrice{GasLimit: gasLimitAndPrice.GasLimit, GasPrice: bid, Strategy: gasLimitAndPrice.Strategy}
+	err := l.validateReward(call, attributes, outbid)
+	if err == nil && parsed.ParsedUserOp != nil {
+		err = l.validateUserOpGas(parsed.ParsedUserOp, attributes, outbid)
+	}
+	if err != nil {
+		l.logger.Info("Not outbidding competitors, the request is not worth it at their price",
+			zap.Stringer("suggested_gas_price", gasLimitAndPrice.GasPrice),
+			zap.Stringer("bid_gas_price", bid),
+			zap.Error(err),
+		)
+		return gasLimitAndPrice
+	}
+
+	l.logger.Info("Outbidding competitors",
+		zap.Stringer("suggested_gas_price", gasLimitAndPrice.GasPrice),
+		zap.Stringer("bid_gas_price", bid),
+	)
+	return outbid
+}
+
 type GasLimitAndPrice struct {
 	GasPrice *big.Int
 	GasLimit *big.Int
+	// Strategy is the name of the gas strategy that set the price
+	Strategy string
 }
 
+// getGasLimitAndPrice estimates the call and prices it with the gas strategy of the destination chain. attributes are
+// those of the request the call fulfills, nil when it fulfills several.
 func (l *OutboxListener) getGasLimitAndPrice(
 	ctx context.Context,
 	destChain *client.ChainClient,
 	call ethereum.CallMsg,
+	attributes *MessageAttributes,
 ) (GasLimitAndPrice, error) {
 	// Estimate gas first
 	estimatedGas, err := destChain.Client.EstimateGas(ctx, call)
@@ -229,7 +750,12 @@ func (l *OutboxListener) getGasLimitAndPrice(
 	// truncate the gas limit to the nearest integer
 	gasLimit, _ := gasLimitFloat.Int(nil)
 
-	gasPrice, err := destChain.Client.SuggestGasPrice(ctx)
+	strategy, err := newGasStrategy(destChain.Config.GasStrategy, l.logger)
+	if err != nil {
+		return GasLimitAndPrice{}, err
+	}
+
+	gasPrice, err := strategy.GasPrice(ctx, destChain, newGasRequest(call, attributes, gasLimit))
 	if err != nil {
 		return GasLimitAndPrice{}, fmt.Errorf("getting gas price: %w", err)
 	}
@@ -238,29 +764,48 @@ func (l *OutboxListener) getGasLimitAndPrice(
 		"Got gas limit and price",
 		zap.String("gas_limit", gasLimit.String()),
 		zap.String("gas_price", gasPrice.String()),
+		zap.String("gas_strategy", strategy.Name()),
+		zap.Uint64("chain_id", destChain.Config.ChainID),
 	)
 
 	return GasLimitAndPrice{
 		GasPrice: gasPrice,
 		GasLimit: gasLimit,
+		Strategy: strategy.Name(),
 	}, nil
 }
 
+// newGasRequest prices the call on its own when the request has an ETH reward, the only one its profit can be computed
+// from
+func newGasRequest(call ethereum.CallMsg, attributes *MessageAttributes, gasLimit *big.Int) GasRequest {
+	req := GasRequest{GasLimit: gasLimit}
+	if attributes == nil || attributes.RewardAsset != paymaster.NativeAsset {
+		return req
+	}
+
+	req.Reward = attributes.RewardAmount.ToBig()
+	req.Cost = new(big.Int).Add(attributes.MagicSpendAmount.ToBig(), call.Value)
+	return req
+}
+
 func (l *OutboxListener) SendTransaction(
 	ctx context.Context,
 	destChain *client.ChainClient,
 	call ethereum.CallMsg,
 	gasLimitAndPrice GasLimitAndPrice,
-) error {
+) (*types.Transaction, error) {
 	l.logger.Info("Starting transaction creation",
 		zap.String("from", call.From.Hex()),
 		zap.String("to", call.To.Hex()),
 		zap.String("value", call.Value.String()),
 		zap.Uint64("chain_id", destChain.Config.ChainID))
 
-	nonce, err := destChain.Client.PendingNonceAt(ctx, common.HexToAddress(l.config.Wallets.FromAddress))
+	l.sendMu.Lock()
+	defer l.sendMu.Unlock()
+
+	nonce, err := destChain.Client.PendingNonceAt(ctx, common.HexToAddress(l.currentConfig().Wallets.FromAddress))
 	if err != nil {
-		return fmt.Errorf("getting nonce: %w", err)
+		return nil, fmt.Errorf("getting nonce: %w", err)
 	}
 	l.logger.Info("Got nonce", zap.Uint64("nonce", nonce))
 
@@ -281,15 +826,9 @@ func (l *OutboxListener) SendTransaction(
 	)
 	l.logger.Info("Created unsigned transaction", zap.String("hash", tx.Hash().Hex()))
 
-	// Strip "0x" prefix if present
-	privKeyStr := l.config.Wallets.PrivateKey
-	if len(privKeyStr) >= 2 && privKeyStr[:2] == "0x" {
-		privKeyStr = privKeyStr[2:]
-	}
-
-	privateKey, err := crypto.HexToECDSA(privKeyStr)
+	privateKey, err := l.currentConfig().Wallets.GetPrivateKey()
 	if err != nil {
-		return fmt.Errorf("parsing private key: %w", err)
+		return nil, err
 	}
 	l.logger.Info("Parsed private key")
 
@@ -299,13 +838,13 @@ func (l *OutboxListener) SendTransaction(
 		privateKey,
 	)
 	if err != nil {
-		return fmt.Errorf("signing transaction: %w", err)
+		return nil, fmt.Errorf("signing transaction: %w", err)
 	}
 	l.logger.Info("Signed transaction", zap.String("hash", signedTx.Hash().Hex()))
 
 	if err := destChain.Client.SendTransaction(ctx, signedTx); err != nil {
 		l.logger.Error("Sending transaction", zap.Error(err))
-		return fmt.Errorf("sending transaction: %w", err)
+		return nil, fmt.Errorf("sending transaction: %w", err)
 	}
 
 	l.logger.Info("Transaction sent successfully",
@@ -314,7 +853,7 @@ func (l *OutboxListener) SendTransaction(
 		zap.Stringer("gas_price", gasLimitAndPrice.GasPrice),
 		zap.Stringer("gas_limit", gasLimitAndPrice.GasLimit),
 	)
-	return nil
+	return signedTx, nil
 }
 
 func (l *OutboxListener) ValidateMessagePosted(
@@ -322,7 +861,34 @@ func (l *OutboxListener) ValidateMessagePosted(
 	sourceChain *client.ChainClient,
 	event *rrc_7755_outbox.RRC7755OutboxMessagePosted,
 ) (*ParsedMessage, error) {
-	parsed := l.parseMessage(event)
+	parsed, err := ParseMessagePosted(event)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Attributes != nil {
+		l.logger.Info("Parsed attributes", zap.Any("attributes", parsed.Attributes))
+	}
+
+	destChain, ok := l.clientMgr.GetAllClients()[parsed.DestinationChain]
+	if !ok {
+		return nil, fmt.Errorf("destination chain is not configured: %d", parsed.DestinationChain)
+	}
+
+	if err := validateAddresses(sourceChain, destChain, parsed); err != nil {
+		return nil, fmt.Errorf("validating addresses: %w", err)
+	}
+
+	// TODO: validate prover
+
+	l.logParsedMessage(parsed)
+
+	return parsed, nil
+}
+
+// ParseMessagePosted decodes the attributes of the request, or its UserOp and the attributes of its paymaster data,
+// without checking it against the config
+func ParseMessagePosted(event *rrc_7755_outbox.RRC7755OutboxMessagePosted) (*ParsedMessage, error) {
+	parsed := parseMessage(event)
 
 	if len(event.Attributes) == 0 {
 		packedUserOperation, err := abi.UnmarshalPackedUserOperation(event.Payload)
@@ -349,27 +915,12 @@ func (l *OutboxListener) ValidateMessagePosted(
 		}
 
 		parsed.Attributes = attributes
-
-		l.logger.Info("Parsed attributes", zap.Any("attributes", attributes))
 	}
 
-	destChain, ok := l.clientMgr.GetAllClients()[parsed.DestinationChain]
-	if !ok {
-		return nil, fmt.Errorf("destination chain is not configured: %d", parsed.DestinationChain)
-	}
-
-	if err := validateAddresses(sourceChain, destChain, parsed); err != nil {
-		return nil, fmt.Errorf("validating addresses: %w", err)
-	}
-
-	// TODO: validate prover
-
-	l.logParsedMessage(parsed)
-
 	return parsed, nil
 }
 
-func (l *OutboxListener) parseMessage(event *rrc_7755_outbox.RRC7755OutboxMessagePosted) *ParsedMessage {
+func parseMessage(event *rrc_7755_outbox.RRC7755OutboxMessagePosted) *ParsedMessage {
 	sourceChainBytes := make([]byte, uint64Size)
 	destChainBytes := make([]byte, uint64Size)
 	copy(sourceChainBytes, event.SourceChain[uint64Offset:])
@@ -474,6 +1025,13 @@ func parseAttributes(attributes [][]byte) (*MessageAttributes, error) {
 				return nil, errors.New("l2Oracle attribute too short")
 			}
 			parsed.L2Oracle.SetBytes(attr[selectorSize:attributeBaseSize])
+
+		case magicSpendRequestAttributeSelector:
+			if len(attr) < attributeExtendedSize {
+				return nil, errors.New("magicSpendRequest attribute too short")
+			}
+			parsed.MagicSpendToken.SetBytes(attr[selectorSize:attributeBaseSize])
+			parsed.MagicSpendAmount.SetBytes32(attr[attributeBaseSize:attributeExtendedSize])
 		}
 	}
 
@@ -509,22 +1067,34 @@ func (l *OutboxListener) logParsedMessage(parsed *ParsedMessage) {
 		zap.String("expiry", expiryStr),
 		zap.Binary("requester", attrs.Requester[:]),
 		zap.Binary("l2_oracle", attrs.L2Oracle[:]),
+		zap.Stringer("magic_spend_token", attrs.MagicSpendToken),
+		zap.String("magic_spend_amount", attrs.MagicSpendAmount.Dec()),
 		zap.Any("user_op", parsed.ParsedUserOp),
 	)
 }
 
+// validateReward checks the reward covers the call value, the magic spend and the gas. For a batched fulfill
+// gasLimitAndPrice holds the share of the batch gas attributed to the request.
 func (l *OutboxListener) validateReward(
 	call ethereum.CallMsg,
 	attributes *MessageAttributes,
 	gasLimitAndPrice GasLimitAndPrice,
 ) error {
-	if attributes.RewardAsset != common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE") {
+	if attributes.RewardAsset != paymaster.NativeAsset {
 		return errors.New("reward asset is not ETH")
 	}
 
+	// Magic spend is capital fronted from our paymaster balance, it can only be priced against an ETH reward if it is
+	// ETH as well
+	magicSpend := attributes.MagicSpendAmount.ToBig()
+	if magicSpend.Sign() > 0 && attributes.MagicSpendToken != paymaster.NativeAsset {
+		return fmt.Errorf("magic spend token %s is not ETH", attributes.MagicSpendToken.Hex())
+	}
+
 	estimatedGasUsed := new(big.Int).Mul(gasLimitAndPrice.GasLimit, gasLimitAndPrice.GasPrice)
 
-	totalAmount := call.Value.Add(call.Value, estimatedGasUsed)
+	totalAmount := new(big.Int).Add(call.Value, estimatedGasUsed)
+	totalAmount.Add(totalAmount, magicSpend)
 	if totalAmount.Cmp(attributes.RewardAmount.ToBig()) >= 0 {
 		return fmt.Errorf(
 			"reward amount is not enough, required minimum: %d, provided: %d",
@@ -536,10 +1106,9 @@ func (l *OutboxListener) validateReward(
 	l.logger.Info(
 		"Valid reward",
 		zap.Stringer("total_amount_required", totalAmount),
+		zap.Stringer("magic_spend_amount", magicSpend),
 		zap.Stringer("reward_amount", attributes.RewardAmount.ToBig()),
 		zap.Stringer("reward_asset", attributes.RewardAsset),
-		zap.Any("call", call),
-		zap.Any("attributes", attributes),
 	)
 
 	return nil
@@ -560,7 +1129,7 @@ func (l *OutboxListener) createCallMsg(parsed *ParsedMessage) (ethereum.CallMsg,
 		parsed.SenderBytes32,
 		parsed.Payload,
 		parsed.RawAttributes,
-		l.config.Wallets.GetFromAddress(),
+		l.currentConfig().Wallets.GetFromAddress(),
 	)
 	if err != nil {
 		return ethereum.CallMsg{}, fmt.Errorf("packing fulfill data: %w", err)
@@ -578,7 +1147,7 @@ func (l *OutboxListener) createCallMsg(parsed *ParsedMessage) (ethereum.CallMsg,
 	}
 
 	return ethereum.CallMsg{
-		From:  l.config.Wallets.GetFromAddress(),
+		From:  l.currentConfig().Wallets.GetFromAddress(),
 		To:    &parsed.Receiver,
 		Data:  data,
 		Value: requiredValue,
@@ -586,26 +1155,31 @@ func (l *OutboxListener) createCallMsg(parsed *ParsedMessage) (ethereum.CallMsg,
 }
 
 func (l *OutboxListener) createUserOpCallMsg(parsed *ParsedMessage) (ethereum.CallMsg, error) {
+	return l.createHandleOpsCallMsg(parsed.Receiver, []abi.PackedUserOperation{*parsed.ParsedUserOp})
+}
+
+// createHandleOpsCallMsg packs ops into a single handleOps call on the entrypoint, paying the fees to our wallet
+func (l *OutboxListener) createHandleOpsCallMsg(
+	entrypointAddress common.Address,
+	ops []abi.PackedUserOperation,
+) (ethereum.CallMsg, error) {
 	entrypointAbi, err := entrypoint.EntrypointMetaData.GetAbi()
 	if err != nil {
 		return ethereum.CallMsg{}, fmt.Errorf("getting ABI: %w", err)
 	}
 
-	var sourceChainBytes [32]byte
-	binary.BigEndian.PutUint64(sourceChainBytes[24:], parsed.SourceChain)
-
 	data, err := entrypointAbi.Pack(
 		"handleOps",
-		[]abi.PackedUserOperation{*parsed.ParsedUserOp},
-		l.config.Wallets.GetFromAddress(),
+		ops,
+		l.currentConfig().Wallets.GetFromAddress(),
 	)
 	if err != nil {
 		return ethereum.CallMsg{}, fmt.Errorf("packing handleOps data: %w", err)
 	}
 
 	return ethereum.CallMsg{
-		From:  l.config.Wallets.GetFromAddress(),
-		To:    &parsed.Receiver,
+		From:  l.currentConfig().Wallets.GetFromAddress(),
+		To:    &entrypointAddress,
 		Data:  data,
 		Value: big.NewInt(0),
 	}, nil
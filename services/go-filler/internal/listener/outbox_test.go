@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"testing"
 
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
@@ -16,6 +17,7 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
 	"github.com/base-org/RRC-7755-poc/internal/client"
 	"github.com/base-org/RRC-7755-poc/internal/config"
 )
@@ -192,6 +194,59 @@ func setupTestData() []testCase {
 	return tests
 }
 
+func TestParseMessagePostedWithoutConfig(t *testing.T) {
+	tt := setupTestData()[0]
+
+	// The destination chain is not configured, the message can be decoded but not validated
+	l := &OutboxListener{
+		config:    &config.Config{},
+		logger:    zap.NewNop(),
+		clientMgr: newTestClientManager(&config.Config{}),
+	}
+	_, err := l.ValidateMessagePosted(context.Background(), tt.chain, tt.event)
+	require.ErrorContains(t, err, "destination chain is not configured")
+
+	parsed, err := ParseMessagePosted(tt.event)
+	require.NoError(t, err)
+	require.Equal(t, testSourceChainID, parsed.SourceChain)
+	require.Equal(t, testDestChainID, parsed.DestinationChain)
+	require.Equal(t, uint64(3), parsed.Attributes.Nonce.Uint64())
+	require.Nil(t, parsed.ParsedUserOp)
+}
+
+func TestApplyConfig(t *testing.T) {
+	chains := map[string]config.ChainConfig{
+		"base-sepolia": {ChainID: testSourceChainID, NodeURL: "wss://example.com"},
+	}
+	startup := &config.Config{
+		Chain:   chains,
+		Wallets: config.WalletConfig{FromAddress: "0x1111111111111111111111111111111111111111"},
+	}
+	core, logs := observer.New(zapcore.WarnLevel)
+	l := &OutboxListener{
+		config:    startup,
+		logger:    zap.New(core),
+		clientMgr: newTestClientManager(startup),
+		reload:    make(chan struct{}, 1),
+	}
+
+	reloaded := &config.Config{
+		Chain:   chains,
+		Wallets: config.WalletConfig{FromAddress: "0x2222222222222222222222222222222222222222"},
+		Proof:   config.ProofConfig{CacheDir: "proofs"},
+	}
+	require.NoError(t, l.ApplyConfig(context.Background(), reloaded))
+
+	// The proof settings are applied, the wallet keeps its startup value until a restart
+	require.Equal(t, reloaded.Proof, l.currentConfig().Proof)
+	require.Equal(t, startup.Wallets, l.currentConfig().Wallets)
+	require.Equal(t, 1, logs.FilterMessageSnippet("require a restart").Len())
+
+	// The wallet change is reported again as long as it is not applied
+	require.NoError(t, l.ApplyConfig(context.Background(), reloaded))
+	require.Equal(t, 2, logs.FilterMessageSnippet("require a restart").Len())
+}
+
 func newTestClientManager(cfg *config.Config) *client.Manager {
 	chains := make(map[uint64]*client.ChainClient, len(cfg.Chain))
 
@@ -277,3 +332,27 @@ func createTestMessage(
 		Attributes:       attributes,
 	}
 }
+
+func TestRewardAttributes(t *testing.T) {
+	tt := setupTestData()[0]
+	require.Equal(t, tt.event.Attributes, rewardAttributes(tt.event))
+
+	// A UserOp request carries its attributes in the paymaster data of the op
+	attributes := [][]byte{{0xa3, 0x62, 0xe5, 0xdb, 0x01}, {0x84, 0x6a, 0x3b, 0x2f, 0x02}}
+	bytesArray, err := gethabi.NewType("bytes[]", "", nil)
+	require.NoError(t, err)
+	paymasterData, err := gethabi.Arguments{{Type: bytesArray}}.Pack(attributes)
+	require.NoError(t, err)
+
+	op := abi.PackedUserOperation{
+		Nonce:              big.NewInt(0),
+		PreVerificationGas: big.NewInt(0),
+		PaymasterAndData:   append(make([]byte, 52), paymasterData...),
+	}
+	payload, err := abi.PackedUserOperationArgs.Pack(op)
+	require.NoError(t, err)
+	require.Equal(t, attributes, rewardAttributes(&rrc_7755_outbox.RRC7755OutboxMessagePosted{Payload: payload}))
+
+	// Without a decodable UserOp the reward can't be read
+	require.Nil(t, rewardAttributes(&rrc_7755_outbox.RRC7755OutboxMessagePosted{Payload: []byte{0x01}}))
+}
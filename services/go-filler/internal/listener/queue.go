@@ -0,0 +1,143 @@
+package listener
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+// batchShutdownTimeout bounds the flush of the pending batches once the listener stops
+const batchShutdownTimeout = 30 * time.Second
+
+// batchQueue groups items per destination chain and hands each batch to flush, once full or after the max delay.
+// Batches are sent under the context of the queue rather than the one of the request that filled them, so a batch
+// already broadcast keeps waiting for its receipt when the listener stops.
+type batchQueue[T any] struct {
+	config config.BatchConfig
+	// ctx is canceled by Close once its deadline passes, giving up on the batches still in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[uint64][]T
+	chains  map[uint64]*client.ChainClient
+	// releases hold the connection of each chain for the items pending or being sent on it
+	releases map[uint64][]func()
+	timers   map[uint64]*time.Timer
+	// sending sends the batches of each chain one at a time, the batches of different chains are sent concurrently
+	sending map[uint64]*sync.Mutex
+	wg      sync.WaitGroup
+
+	flush func(ctx context.Context, destChain *client.ChainClient, batch []T)
+}
+
+func newBatchQueue[T any](
+	cfg config.BatchConfig,
+	flush func(ctx context.Context, destChain *client.ChainClient, batch []T),
+) *batchQueue[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &batchQueue[T]{
+		config:   cfg,
+		ctx:      ctx,
+		cancel:   cancel,
+		pending:  make(map[uint64][]T),
+		chains:   make(map[uint64]*client.ChainClient),
+		releases: make(map[uint64][]func()),
+		sending:  make(map[uint64]*sync.Mutex),
+		timers:   make(map[uint64]*time.Timer),
+		flush:    flush,
+	}
+}
+
+// Add queues item for its destination chain and returns the size of the pending batch, 0 if it was just sent
+func (q *batchQueue[T]) Add(destChain *client.ChainClient, item T) int {
+	chainID := destChain.Config.ChainID
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending[chainID] = append(q.pending[chainID], item)
+	q.chains[chainID] = destChain
+	q.releases[chainID] = append(q.releases[chainID], destChain.Acquire())
+
+	if len(q.pending[chainID]) >= q.config.MaxSize {
+		q.sendLocked(chainID)
+		return 0
+	}
+
+	if _, ok := q.timers[chainID]; !ok {
+		q.timers[chainID] = time.AfterFunc(q.config.MaxDelay, func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.sendLocked(chainID)
+		})
+	}
+
+	return len(q.pending[chainID])
+}
+
+// sendLocked takes the pending batch of chainID and sends it in the background, q.mu must be held
+func (q *batchQueue[T]) sendLocked(chainID uint64) {
+	if timer, ok := q.timers[chainID]; ok {
+		timer.Stop()
+		delete(q.timers, chainID)
+	}
+
+	batch := q.pending[chainID]
+	destChain := q.chains[chainID]
+	releases := q.releases[chainID]
+	delete(q.pending, chainID)
+	delete(q.releases, chainID)
+	if len(batch) == 0 {
+		return
+	}
+
+	sending, ok := q.sending[chainID]
+	if !ok {
+		sending = &sync.Mutex{}
+		q.sending[chainID] = sending
+	}
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		defer func() {
+			for _, release := range releases {
+				release()
+			}
+		}()
+
+		sending.Lock()
+		defer sending.Unlock()
+
+		q.flush(q.ctx, destChain, batch)
+	}()
+}
+
+// Close sends the pending batches and waits for every batch to be sent, the batches still in flight once ctx is done
+// stop waiting for their receipts
+func (q *batchQueue[T]) Close(ctx context.Context) {
+	defer q.cancel()
+
+	q.mu.Lock()
+	for chainID := range q.pending {
+		q.sendLocked(chainID)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+	}
+}
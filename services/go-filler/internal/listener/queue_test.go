@@ -0,0 +1,47 @@
+package listener
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+func TestBatchQueueSendsChainsConcurrently(t *testing.T) {
+	const slowChainID, fastChainID = 421614, 11155420
+
+	slowSending := make(chan struct{})
+	fastSent := make(chan struct{})
+	slowSent := make(chan struct{})
+	q := newBatchQueue(config.BatchConfig{MaxSize: 1, MaxDelay: time.Minute}, func(ctx context.Context, destChain *client.ChainClient, batch []int) {
+		if destChain.Config.ChainID == fastChainID {
+			close(fastSent)
+			return
+		}
+
+		// The batch of the slow chain waits for its receipt until the other chain got its batch out
+		close(slowSending)
+		select {
+		case <-fastSent:
+		case <-ctx.Done():
+		}
+		close(slowSent)
+	})
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q.Close(ctx)
+	}()
+
+	q.Add(&client.ChainClient{Config: config.ChainConfig{ChainID: slowChainID}}, 1)
+	<-slowSending
+	q.Add(&client.ChainClient{Config: config.ChainConfig{ChainID: fastChainID}}, 2)
+
+	select {
+	case <-slowSent:
+	case <-time.After(time.Second):
+		t.Fatal("the batch of a chain waited for the batch of another chain")
+	}
+}
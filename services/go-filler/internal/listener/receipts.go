@@ -0,0 +1,74 @@
+package listener
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+)
+
+// receiptWatcher waits for the receipts of the fulfillments sent outside of a batch and releases the exposure of the
+// reverted ones. Like the batch queues it waits under its own context, so a fulfillment already broadcast keeps
+// waiting for its receipt when the listener stops.
+type receiptWatcher struct {
+	l *OutboxListener
+	// ctx is canceled by Close once its deadline passes, giving up on the receipts not read yet
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newReceiptWatcher(l *OutboxListener) *receiptWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &receiptWatcher{l: l, ctx: ctx, cancel: cancel}
+}
+
+// Watch waits for the receipt of tx in the background. The exposure is kept when the receipt can't be read, the
+// transaction may still fulfill the request. The connection of destChain is held until the receipt is read.
+func (w *receiptWatcher) Watch(destChain *client.ChainClient, messageID [32]byte, tx *types.Transaction) {
+	release := destChain.Acquire()
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer release()
+
+		fields := []zap.Field{
+			zap.String("message_id", common.Hash(messageID).Hex()),
+			zap.Uint64("chain_id", destChain.Config.ChainID),
+			zap.String("tx_hash", tx.Hash().Hex()),
+		}
+
+		receipt, err := bind.WaitMined(w.ctx, destChain.Client, tx)
+		if err != nil {
+			w.l.logger.Warn("Fulfillment pending", append(fields, zap.Error(err))...)
+			return
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			w.l.logger.Error("Fulfillment reverted", fields...)
+			w.l.releaseExposure(messageID)
+		}
+	}()
+}
+
+// Close waits for the receipts being watched, the ones not read once ctx is done are given up on
+func (w *receiptWatcher) Close(ctx context.Context) {
+	defer w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		w.cancel()
+		<-done
+	}
+}
@@ -0,0 +1,75 @@
+package listener
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+func TestReceiptWatcherReleasesExposureOfReverted(t *testing.T) {
+	tests := []struct {
+		name        string
+		receipt     *types.Receipt
+		wantRelease bool
+		wantLog     string
+	}{
+		{
+			// The transaction may still be mined after the listener stopped waiting, the request may be fulfilled
+			name:    "receipt unknown",
+			wantLog: "Fulfillment pending",
+		},
+		{
+			name:        "reverted",
+			receipt:     &types.Receipt{Status: types.ReceiptStatusFailed},
+			wantRelease: true,
+			wantLog:     "Fulfillment reverted",
+		},
+		{
+			name:    "successful",
+			receipt: &types.Receipt{Status: types.ReceiptStatusSuccessful},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageID := [32]byte{1}
+			l, logs := newRiskTestListener(t, messageID)
+
+			ctrl := gomock.NewController(t)
+			ethClient := mocks.NewMockEthClient(ctrl)
+			if tt.receipt != nil {
+				ethClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(tt.receipt, nil)
+			} else {
+				ethClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(nil, ethereum.NotFound).AnyTimes()
+			}
+			destChain := &client.ChainClient{Client: ethClient, Config: config.ChainConfig{ChainID: testDestChainID}}
+			tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000})
+
+			w := newReceiptWatcher(l)
+			w.Watch(destChain, messageID, tx)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			w.Close(ctx)
+
+			if tt.wantRelease {
+				require.Equal(t, 1, logs.FilterMessage("Released risk exposure").Len())
+			} else {
+				require.Zero(t, logs.FilterMessage("Released risk exposure").Len())
+			}
+			if tt.wantLog != "" {
+				require.Equal(t, 1, logs.FilterMessage(tt.wantLog).Len())
+			}
+		})
+	}
+}
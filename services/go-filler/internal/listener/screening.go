@@ -0,0 +1,69 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/paymaster"
+	"github.com/base-org/RRC-7755-poc/internal/screening"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// screen screens every address of a validated request
+func (l *OutboxListener) screen(ctx context.Context, messageID [32]byte, parsed *ParsedMessage) error {
+	var payout *common.Address
+	if l.currentConfig().Wallets.RecipientAddress != "" {
+		recipient := l.currentConfig().Wallets.GetRecipientAddress()
+		payout = &recipient
+	}
+
+	subjects, err := screeningSubjects(parsed, payout)
+	if err != nil {
+		return err
+	}
+
+	return l.screening.Screen(ctx, messageID, subjects)
+}
+
+// screeningSubjects lists the requester, the UserOp sender or the call targets, the reward asset and the address the
+// reward is paid out to, when set
+func screeningSubjects(parsed *ParsedMessage, payout *common.Address) ([]screening.Subject, error) {
+	attributes := parsed.Attributes
+	if parsed.ParsedUserOp != nil {
+		attributes = parsed.UserOpAttributes
+	}
+
+	subjects := []screening.Subject{{Role: screening.RoleRequester, Address: attributes.Requester}}
+
+	if parsed.ParsedUserOp != nil {
+		subjects = append(subjects, screening.Subject{
+			Role:    screening.RoleUserOpSender,
+			Address: common.BytesToHash(parsed.ParsedUserOp.Sender.Bytes()),
+		})
+	} else {
+		calls, err := abi.UnmarshalCalls(parsed.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshalling calls: %w", err)
+		}
+		for _, call := range calls {
+			subjects = append(subjects, screening.Subject{Role: screening.RoleCallTarget, Address: call.To})
+		}
+	}
+
+	if attributes.RewardAsset != paymaster.NativeAsset {
+		subjects = append(subjects, screening.Subject{
+			Role:    screening.RoleRewardAsset,
+			Address: common.BytesToHash(attributes.RewardAsset.Bytes()),
+		})
+	}
+
+	if payout != nil {
+		subjects = append(subjects, screening.Subject{
+			Role:    screening.RoleRewardPayout,
+			Address: common.BytesToHash(payout.Bytes()),
+		})
+	}
+
+	return subjects, nil
+}
@@ -0,0 +1,47 @@
+package listener
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/paymaster"
+	"github.com/base-org/RRC-7755-poc/internal/screening"
+)
+
+func TestScreeningSubjects(t *testing.T) {
+	var (
+		requester = common.HexToHash("0x8C1a617BdB47342F9C17Ac8750E0b070c372C721")
+		target    = common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+		sender    = common.HexToAddress("0x722122dF12D4e14e13Ac3b6895a86e84145b6967")
+		token     = common.HexToAddress("0x75faf114eafb1BDbe2F0316DF893fd58CE46AA4d")
+		payout    = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	)
+
+	payload, err := abi.CallsArgs.Pack([]abi.Call{{To: common.BytesToHash(target.Bytes()), Data: []byte{}, Value: common.Big0}})
+	require.NoError(t, err)
+
+	subjects, err := screeningSubjects(&ParsedMessage{
+		Payload:    payload,
+		Attributes: &MessageAttributes{Requester: requester, RewardAsset: token},
+	}, &payout)
+	require.NoError(t, err)
+	require.Equal(t, []screening.Subject{
+		{Role: screening.RoleRequester, Address: requester},
+		{Role: screening.RoleCallTarget, Address: common.BytesToHash(target.Bytes())},
+		{Role: screening.RoleRewardAsset, Address: common.BytesToHash(token.Bytes())},
+		{Role: screening.RoleRewardPayout, Address: common.BytesToHash(payout.Bytes())},
+	}, subjects)
+
+	subjects, err = screeningSubjects(&ParsedMessage{
+		ParsedUserOp:     &abi.PackedUserOperation{Sender: sender},
+		UserOpAttributes: &MessageAttributes{Requester: requester, RewardAsset: paymaster.NativeAsset},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []screening.Subject{
+		{Role: screening.RoleRequester, Address: requester},
+		{Role: screening.RoleUserOpSender, Address: common.BytesToHash(sender.Bytes())},
+	}, subjects)
+}
@@ -0,0 +1,162 @@
+package listener
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/audit"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+const (
+	shadowDecisionFulfill = "fulfill"
+	shadowDecisionSkip    = "skip"
+)
+
+// ShadowDecision is the record written in shadow mode in place of a broadcast transaction
+type ShadowDecision struct {
+	Timestamp        time.Time   `json:"timestamp"`
+	MessageID        common.Hash `json:"message_id"`
+	SourceChain      uint64      `json:"source_chain"`
+	DestinationChain uint64      `json:"destination_chain"`
+	SourceBlock      uint64      `json:"source_block"`
+	SourceTxHash     common.Hash `json:"source_tx_hash"`
+	IsUserOp         bool        `json:"is_user_op"`
+	Decision         string      `json:"decision"`
+	Reason           string      `json:"reason,omitempty"`
+	// The call and gas fields are empty for requests rejected before they were priced
+	From          common.Address `json:"from"`
+	To            common.Address `json:"to"`
+	Value         string         `json:"value,omitempty"`
+	Data          hexutil.Bytes  `json:"data,omitempty"`
+	GasLimit      string         `json:"gas_limit,omitempty"`
+	GasPrice      string         `json:"gas_price,omitempty"`
+	GasStrategy   string         `json:"gas_strategy,omitempty"`
+	EstimatedCost string         `json:"estimated_cost,omitempty"`
+	// The reward fields are empty for requests that could not be parsed
+	RewardAsset  common.Address `json:"reward_asset"`
+	RewardAmount string         `json:"reward_amount,omitempty"`
+}
+
+// shadowRecorder logs ShadowDecisions and optionally appends them to a JSONL file
+type shadowRecorder struct {
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newShadowRecorder(logger *zap.Logger, outputPath string) (*shadowRecorder, error) {
+	r := &shadowRecorder{logger: logger}
+
+	if outputPath != "" {
+		file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening shadow output file: %w", err)
+		}
+		r.file = file
+	}
+
+	return r, nil
+}
+
+func (r *shadowRecorder) Record(decision *ShadowDecision) error {
+	r.logger.Info("Shadow decision",
+		zap.String("message_id", decision.MessageID.Hex()),
+		zap.Uint64("source_chain", decision.SourceChain),
+		zap.Uint64("destination_chain", decision.DestinationChain),
+		zap.String("decision", decision.Decision),
+		zap.String("reason", decision.Reason),
+		zap.String("value", decision.Value),
+		zap.String("gas_limit", decision.GasLimit),
+		zap.String("gas_price", decision.GasPrice),
+		zap.String("gas_strategy", decision.GasStrategy),
+		zap.String("reward_amount", decision.RewardAmount),
+	)
+
+	if r.file == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(decision)
+	if err != nil {
+		return fmt.Errorf("encoding shadow decision: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing shadow decision: %w", err)
+	}
+
+	return nil
+}
+
+func (r *shadowRecorder) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Close()
+}
+
+// newShadowDecision is the shadow decision of the request from its decision record, reason being why it was skipped.
+// The call, gas and reward fields are only set when the request got far enough to be priced.
+func newShadowDecision(
+	event *rrc_7755_outbox.RRC7755OutboxMessagePosted,
+	rec *audit.DecisionRecord,
+	reason error,
+) *ShadowDecision {
+	parsed := parseMessage(event)
+	decision := &ShadowDecision{
+		Timestamp:        rec.Timestamp,
+		MessageID:        event.MessageId,
+		SourceChain:      parsed.SourceChain,
+		DestinationChain: parsed.DestinationChain,
+		SourceBlock:      event.Raw.BlockNumber,
+		SourceTxHash:     event.Raw.TxHash,
+		IsUserOp:         len(event.Attributes) == 0,
+		Decision:         shadowDecisionFulfill,
+	}
+
+	if rec.Inputs != nil {
+		decision.RewardAsset = rec.Inputs.RewardAsset
+		decision.RewardAmount = bigString(rec.Inputs.RewardAmount)
+	}
+	if rec.Call != nil {
+		decision.From = rec.Call.From
+		decision.To = rec.Call.To
+		decision.Value = bigString(rec.Call.Value)
+		decision.Data = rec.Call.Data
+	}
+	if rec.Gas != nil {
+		decision.GasLimit = bigString(rec.Gas.GasLimit)
+		decision.GasPrice = bigString(rec.Gas.GasPrice)
+		decision.GasStrategy = rec.Gas.Strategy
+		if rec.Gas.GasLimit != nil && rec.Gas.GasPrice != nil {
+			decision.EstimatedCost = new(big.Int).Mul(rec.Gas.GasLimit, rec.Gas.GasPrice).String()
+		}
+	}
+
+	if reason != nil {
+		decision.Decision = shadowDecisionSkip
+		decision.Reason = reason.Error()
+	}
+
+	return decision
+}
+
+// bigString is the decimal string of n, empty when n is not set
+func bigString(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
@@ -0,0 +1,167 @@
+package listener
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+// newShadowTestEvent returns the Base Sepolia -> Arbitrum Sepolia request used by the fixture test
+func newShadowTestEvent(t *testing.T) *rrc_7755_outbox.RRC7755OutboxMessagePosted {
+	payload, err := base64.StdEncoding.DecodeString("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAgAAAAAAAAAAAAAAAA5KNxFGLTcadzbya1+DFQ+QfE6O8AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWvMQekAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	require.NoError(t, err)
+
+	var attributes [][]byte
+	for _, attr := range []string{
+		"o2Ll2wAAAAAAAAAAAAAAAO7u7u7u7u7u7u7u7u7u7u7u7u7uAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAC15iD0gAA=",
+		"hPVQ4AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA4QAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAGfKt5o=",
+		"zgP9qwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAD",
+		"O9lOTAAAAAAAAAAAAAAAAOSjcRRi03Gnc28mtfgxUPkHxOjv",
+		"f/ckWgAAAAAAAAAAAAAAAAQrLmxemdTFIb1Jvu1emWUdmwz0",
+	} {
+		decoded, err := base64.StdEncoding.DecodeString(attr)
+		require.NoError(t, err)
+		attributes = append(attributes, decoded)
+	}
+
+	event := createTestMessage(
+		testSourceChainID,
+		testDestChainID,
+		common.HexToAddress("0x2504b1c3b78b2711e24eadf7ea077b0ca1b91859"),
+		common.HexToAddress("0x1bb8dacba30b1cd82ce1d3d7f24e16ee549aebe8"),
+		payload,
+		attributes,
+	)
+	event.MessageId = common.HexToHash("0x6419748c633af160077f208bbe75b69b65bfabb24f12893f604b01a53d69143d")
+
+	return event
+}
+
+func newShadowTestListener(t *testing.T, destClient client.EthClient, outputPath string) (*OutboxListener, *client.ChainClient) {
+	receiver := common.HexToAddress("0x1bb8dacba30b1cd82ce1d3d7f24e16ee549aebe8")
+	l2Oracle := common.HexToAddress("0x042b2e6c5e99d4c521bd49beed5e99651d9b0cf4")
+
+	sourceChain := &client.ChainClient{
+		Config: config.ChainConfig{ChainID: testSourceChainID, InboxAddress: receiver},
+	}
+	destChain := &client.ChainClient{
+		Client: destClient,
+		Config: config.ChainConfig{ChainID: testDestChainID, InboxAddress: receiver, L2Oracle: l2Oracle},
+	}
+
+	cfg := &config.Config{
+		Shadow: config.ShadowConfig{Enabled: true, OutputPath: outputPath},
+	}
+
+	l, err := NewOutboxListener(context.Background(), &client.Manager{
+		Chains: map[uint64]*client.ChainClient{
+			testSourceChainID: sourceChain,
+			testDestChainID:   destChain,
+		},
+	}, cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, l.Close()) })
+
+	// The fixture request expires at 1741338522 with a one hour finality delay
+	l.now = func() time.Time { return time.Unix(1741338522, 0).Add(-2 * time.Hour) }
+
+	return l, sourceChain
+}
+
+func readShadowDecisions(t *testing.T, path string) []ShadowDecision {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var decisions []ShadowDecision
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var decision ShadowDecision
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &decision))
+		decisions = append(decisions, decision)
+	}
+	require.NoError(t, scanner.Err())
+
+	return decisions
+}
+
+func TestShadowModeRecordsFulfillDecision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	destClient := mocks.NewMockEthClient(ctrl)
+
+	// No PendingNonceAt or SendTransaction expectations: shadow mode must not broadcast
+	destClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil)
+	destClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(100000000), nil)
+
+	outputPath := filepath.Join(t.TempDir(), "shadow.jsonl")
+	l, sourceChain := newShadowTestListener(t, destClient, outputPath)
+
+	event := newShadowTestEvent(t)
+	require.NoError(t, l.processMessagePosted(context.Background(), sourceChain, event))
+
+	decisions := readShadowDecisions(t, outputPath)
+	require.Len(t, decisions, 1)
+	require.Equal(t, shadowDecisionFulfill, decisions[0].Decision)
+	require.Equal(t, common.Hash(event.MessageId), decisions[0].MessageID)
+	require.Equal(t, testDestChainID, decisions[0].DestinationChain)
+	require.Equal(t, "100000000000000", decisions[0].Value)
+	require.Equal(t, "200000000000000", decisions[0].RewardAmount)
+}
+
+func TestShadowModeRecordsSkipDecision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	destClient := mocks.NewMockEthClient(ctrl)
+
+	destClient.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil)
+	destClient.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(1000000000000), nil)
+
+	outputPath := filepath.Join(t.TempDir(), "shadow.jsonl")
+	l, sourceChain := newShadowTestListener(t, destClient, outputPath)
+
+	err := l.processMessagePosted(context.Background(), sourceChain, newShadowTestEvent(t))
+	require.ErrorContains(t, err, "reward amount is not enough")
+
+	decisions := readShadowDecisions(t, outputPath)
+	require.Len(t, decisions, 1)
+	require.Equal(t, shadowDecisionSkip, decisions[0].Decision)
+	require.Contains(t, decisions[0].Reason, "reward amount is not enough")
+}
+
+func TestShadowModeRecordsRequestsRejectedBeforePricing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	// No EstimateGas expectation: the request is rejected by the validation
+	destClient := mocks.NewMockEthClient(ctrl)
+
+	outputPath := filepath.Join(t.TempDir(), "shadow.jsonl")
+	l, sourceChain := newShadowTestListener(t, destClient, outputPath)
+
+	event := newShadowTestEvent(t)
+	event.Receiver = common.BytesToHash(common.HexToAddress("0x1234").Bytes())
+	err := l.processMessagePosted(context.Background(), sourceChain, event)
+	require.ErrorContains(t, err, "receiver address mismatch")
+
+	decisions := readShadowDecisions(t, outputPath)
+	require.Len(t, decisions, 1)
+	require.Equal(t, shadowDecisionSkip, decisions[0].Decision)
+	require.Equal(t, common.Hash(event.MessageId), decisions[0].MessageID)
+	require.Equal(t, testDestChainID, decisions[0].DestinationChain)
+	require.Contains(t, decisions[0].Reason, "receiver address mismatch")
+	require.Empty(t, decisions[0].GasLimit)
+}
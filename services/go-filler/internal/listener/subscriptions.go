@@ -0,0 +1,252 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"go.uber.org/zap"
+)
+
+// resubscribePolicy spaces the attempts to restore an outbox subscription dropped by the node, it never gives up
+var resubscribePolicy = retry.Policy{
+	Attempts:       math.MaxInt,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+}
+
+type outboxKey struct {
+	chainID uint64
+	address common.Address
+}
+
+type outboxSubscription struct {
+	client client.EthClient
+	cancel context.CancelFunc
+	// done is closed once the subscription is cancelled
+	done chan struct{}
+}
+
+// outboxSubscriptions keeps WatchMessagePosted and WatchCrossChainCallCanceled subscriptions per configured outbox,
+// forwarding every posted message to out and recording canceled ones
+type outboxSubscriptions struct {
+	logger   *zap.Logger
+	out      chan combinedMsgPostedPayload
+	canceled *canceledMessages
+	subs     map[outboxKey]*outboxSubscription
+	wg       sync.WaitGroup
+}
+
+func newOutboxSubscriptions(logger *zap.Logger, bufferSize int, canceled *canceledMessages) *outboxSubscriptions {
+	return &outboxSubscriptions{
+		logger:   logger,
+		out:      make(chan combinedMsgPostedPayload, bufferSize),
+		canceled: canceled,
+		subs:     make(map[outboxKey]*outboxSubscription),
+	}
+}
+
+// sync subscribes to the outboxes of chains that are not watched yet and stops the subscriptions of outboxes that
+// were removed or whose chain was reconnected. Subscriptions dropped by the node resubscribe on their own.
+func (s *outboxSubscriptions) sync(ctx context.Context, chains map[uint64]*client.ChainClient) error {
+	desired := make(map[outboxKey]*client.ChainClient)
+	for _, chain := range chains {
+		for _, address := range chain.Config.OutboxAddresses {
+			desired[outboxKey{chainID: chain.Config.ChainID, address: address}] = chain
+		}
+	}
+
+	for key, sub := range s.subs {
+		chain, ok := desired[key]
+		if ok && chain.Client == sub.client {
+			continue
+		}
+
+		sub.cancel()
+		delete(s.subs, key)
+		s.logger.Info(
+			"Stopped outbox subscriptions",
+			zap.Uint64("chain_id", key.chainID),
+			zap.String("outbox_address", key.address.Hex()),
+		)
+	}
+
+	var errs []error
+	for key, chain := range desired {
+		if _, ok := s.subs[key]; ok {
+			continue
+		}
+
+		if err := s.subscribe(ctx, key, chain.Client); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *outboxSubscriptions) subscribe(ctx context.Context, key outboxKey, ethClient client.EthClient) error {
+	subCtx, cancel := context.WithCancel(ctx)
+	w, err := watchOutbox(subCtx, key, ethClient, cap(s.out))
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.logger.Info(
+		"Started outbox WatchMessagePosted and WatchCrossChainCallCanceled",
+		zap.Uint64("chain_id", key.chainID),
+		zap.String("outbox_address", key.address.Hex()),
+	)
+
+	sub := &outboxSubscription{client: ethClient, cancel: cancel, done: make(chan struct{})}
+	s.subs[key] = sub
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(sub.done)
+
+		for {
+			err := s.forward(subCtx, key, w)
+			w.unsubscribe()
+			if subCtx.Err() != nil {
+				return
+			}
+
+			s.logger.Error(
+				"Outbox subscription dropped, resubscribing",
+				zap.Uint64("chain_id", key.chainID),
+				zap.String("outbox_address", key.address.Hex()),
+				zap.Error(err),
+			)
+			if w = s.resubscribe(subCtx, key, ethClient); w == nil {
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// forward handles the events of w until ctx is done, returning nil, or the node drops one of its subscriptions
+func (s *outboxSubscriptions) forward(ctx context.Context, key outboxKey, w *outboxWatch) error {
+	for {
+		select {
+		case m := <-w.msgPosted:
+			select {
+			case s.out <- combinedMsgPostedPayload{msgPosted: m, chainID: key.chainID}:
+			case <-ctx.Done():
+				return nil
+			}
+		case c := <-w.canceled:
+			s.canceled.add(c.MessageId)
+			s.logger.Info(
+				"Message canceled",
+				zap.String("message_id", common.Hash(c.MessageId).Hex()),
+				zap.Uint64("chain_id", key.chainID),
+			)
+		case err := <-w.msgPostedSub.Err():
+			return fmt.Errorf("WatchMessagePosted: %w", err)
+		case err := <-w.canceledSub.Err():
+			return fmt.Errorf("WatchCrossChainCallCanceled: %w", err)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// resubscribe watches the outbox again after a drop, backing off between the attempts until ctx is done. It returns
+// nil once ctx is done.
+func (s *outboxSubscriptions) resubscribe(ctx context.Context, key outboxKey, ethClient client.EthClient) *outboxWatch {
+	// A node dropping subscriptions right after accepting them is not resubscribed to in a loop
+	timer := time.NewTimer(resubscribePolicy.InitialBackoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-timer.C:
+	}
+
+	var w *outboxWatch
+	err := retry.Do(ctx, resubscribePolicy, func(ctx context.Context) error {
+		var err error
+		if w, err = watchOutbox(ctx, key, ethClient, cap(s.out)); err != nil {
+			s.logger.Warn(
+				"Resubscribing outbox",
+				zap.Uint64("chain_id", key.chainID),
+				zap.String("outbox_address", key.address.Hex()),
+				zap.Error(err),
+			)
+		}
+		return err
+	})
+	if err != nil {
+		return nil
+	}
+
+	s.logger.Info(
+		"Resubscribed outbox WatchMessagePosted and WatchCrossChainCallCanceled",
+		zap.Uint64("chain_id", key.chainID),
+		zap.String("outbox_address", key.address.Hex()),
+	)
+	return w
+}
+
+// outboxWatch is the WatchMessagePosted and WatchCrossChainCallCanceled subscriptions of an outbox
+type outboxWatch struct {
+	msgPosted    chan *rrc_7755_outbox.RRC7755OutboxMessagePosted
+	msgPostedSub event.Subscription
+	canceled     chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCanceled
+	canceledSub  event.Subscription
+}
+
+func watchOutbox(ctx context.Context, key outboxKey, ethClient client.EthClient, bufferSize int) (*outboxWatch, error) {
+	outbox, err := rrc_7755_outbox.NewRRC7755OutboxFilterer(key.address, ethClient)
+	if err != nil {
+		return nil, fmt.Errorf("creating outbox contract on chain %d: %w", key.chainID, err)
+	}
+
+	w := &outboxWatch{
+		msgPosted: make(chan *rrc_7755_outbox.RRC7755OutboxMessagePosted, bufferSize),
+		canceled:  make(chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCanceled, bufferSize),
+	}
+
+	// For real-time events, don't set Start block - this will watch from the latest block
+	// which avoids the "exceed maximum block range" error
+	w.msgPostedSub, err = outbox.WatchMessagePosted(&bind.WatchOpts{Context: ctx}, w.msgPosted, [][32]byte{})
+	if err != nil {
+		return nil, fmt.Errorf("creating WatchMessagePosted subscription on chain %d: %w", key.chainID, err)
+	}
+
+	w.canceledSub, err = outbox.WatchCrossChainCallCanceled(&bind.WatchOpts{Context: ctx}, w.canceled, [][32]byte{})
+	if err != nil {
+		w.msgPostedSub.Unsubscribe()
+		return nil, fmt.Errorf("creating WatchCrossChainCallCanceled subscription on chain %d: %w", key.chainID, err)
+	}
+
+	return w, nil
+}
+
+func (w *outboxWatch) unsubscribe() {
+	w.msgPostedSub.Unsubscribe()
+	w.canceledSub.Unsubscribe()
+}
+
+// stopAll cancels every subscription and waits for their goroutines to exit
+func (s *outboxSubscriptions) stopAll() {
+	for key, sub := range s.subs {
+		sub.cancel()
+		delete(s.subs, key)
+	}
+	s.wg.Wait()
+}
@@ -0,0 +1,212 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+// newSubscribingClient returns a client whose log subscriptions stay open until unsubscribed, recording the
+// addresses watched for MessagePosted
+func newSubscribingClient(ctrl *gomock.Controller, subscribed *[]common.Address) *mocks.MockEthClient {
+	messagePostedID := outboxEventID("MessagePosted")
+
+	ethClient := mocks.NewMockEthClient(ctrl)
+	ethClient.EXPECT().
+		SubscribeFilterLogs(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+			if query.Topics[0][0] == messagePostedID {
+				*subscribed = append(*subscribed, query.Addresses...)
+			}
+			return event.NewSubscription(func(quit <-chan struct{}) error {
+				<-quit
+				return nil
+			}), nil
+		}).
+		AnyTimes()
+	return ethClient
+}
+
+func TestOutboxSubscriptionsSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	opStackOutbox := common.HexToAddress("0x3542dd26727844524ea7c136c5c38ff8088b30ba")
+	arbitrumOutbox := common.HexToAddress("0x657c8b8d05001e51b1cdcfc8709537a8963390a4")
+	hashiOutbox := common.HexToAddress("0x8f9c8d8b2b8a6d1e1e9a5c3b3f3e4d5c6b7a8f9e")
+
+	var subscribed []common.Address
+	sourceClient := newSubscribingClient(ctrl, &subscribed)
+	source := &client.ChainClient{
+		Client: sourceClient,
+		Config: config.ChainConfig{
+			ChainID: testSourceChainID,
+			OutboxAddresses: map[string]common.Address{
+				config.ProverOPStack:  opStackOutbox,
+				config.ProverArbitrum: arbitrumOutbox,
+			},
+		},
+	}
+
+	subs := newOutboxSubscriptions(zaptest.NewLogger(t), crossChainCallRequestedBufferSize, newCanceledMessages(canceledMessagesSize))
+	defer subs.stopAll()
+
+	require.NoError(t, subs.sync(ctx, map[uint64]*client.ChainClient{testSourceChainID: source}))
+	require.ElementsMatch(t, []common.Address{opStackOutbox, arbitrumOutbox}, subscribed)
+
+	// Replacing an outbox only touches that subscription
+	subscribed = nil
+	arbitrumSub := subs.subs[outboxKey{chainID: testSourceChainID, address: arbitrumOutbox}]
+	updated := &client.ChainClient{
+		Client: sourceClient,
+		Config: config.ChainConfig{
+			ChainID: testSourceChainID,
+			OutboxAddresses: map[string]common.Address{
+				config.ProverHashi:    hashiOutbox,
+				config.ProverArbitrum: arbitrumOutbox,
+			},
+		},
+	}
+	require.NoError(t, subs.sync(ctx, map[uint64]*client.ChainClient{testSourceChainID: updated}))
+	require.Equal(t, []common.Address{hashiOutbox}, subscribed)
+	require.Len(t, subs.subs, 2)
+	require.Same(t, arbitrumSub, subs.subs[outboxKey{chainID: testSourceChainID, address: arbitrumOutbox}])
+
+	// A reconnected chain resubscribes all of its outboxes on the new client
+	var resubscribed []common.Address
+	reconnected := &client.ChainClient{
+		Client: newSubscribingClient(ctrl, &resubscribed),
+		Config: updated.Config,
+	}
+	require.NoError(t, subs.sync(ctx, map[uint64]*client.ChainClient{testSourceChainID: reconnected}))
+	require.ElementsMatch(t, []common.Address{hashiOutbox, arbitrumOutbox}, resubscribed)
+	<-arbitrumSub.done
+
+	// Removing the chain stops its subscriptions
+	require.NoError(t, subs.sync(ctx, map[uint64]*client.ChainClient{}))
+	require.Empty(t, subs.subs)
+}
+
+func outboxEventID(name string) common.Hash {
+	outboxAbi, err := rrc_7755_outbox.RRC7755OutboxMetaData.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return outboxAbi.Events[name].ID
+}
+
+func TestOutboxSubscriptionsRecordCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	canceledID := outboxEventID("CrossChainCallCanceled")
+	messageID := common.HexToHash("0x6419748c633af160077f208bbe75b69b65bfabb24f12893f604b01a53d69143d")
+	outbox := common.HexToAddress("0x3542dd26727844524ea7c136c5c38ff8088b30ba")
+
+	ethClient := mocks.NewMockEthClient(ctrl)
+	ethClient.EXPECT().
+		SubscribeFilterLogs(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+			return event.NewSubscription(func(quit <-chan struct{}) error {
+				if query.Topics[0][0] == canceledID {
+					ch <- types.Log{Address: outbox, Topics: []common.Hash{canceledID, messageID}}
+				}
+				<-quit
+				return nil
+			}), nil
+		}).
+		Times(2)
+
+	canceled := newCanceledMessages(canceledMessagesSize)
+	subs := newOutboxSubscriptions(zaptest.NewLogger(t), crossChainCallRequestedBufferSize, canceled)
+	defer subs.stopAll()
+
+	chain := &client.ChainClient{
+		Client: ethClient,
+		Config: config.ChainConfig{
+			ChainID:         testSourceChainID,
+			OutboxAddresses: map[string]common.Address{config.ProverOPStack: outbox},
+		},
+	}
+	require.NoError(t, subs.sync(ctx, map[uint64]*client.ChainClient{testSourceChainID: chain}))
+
+	require.Eventually(t, func() bool { return canceled.contains(messageID) }, time.Second, 10*time.Millisecond)
+}
+
+func TestOutboxSubscriptionsResubscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	policy := resubscribePolicy
+	resubscribePolicy.InitialBackoff = time.Millisecond
+	resubscribePolicy.MaxBackoff = time.Millisecond
+	t.Cleanup(func() { resubscribePolicy = policy })
+
+	messagePostedID := outboxEventID("MessagePosted")
+	outbox := common.HexToAddress("0x3542dd26727844524ea7c136c5c38ff8088b30ba")
+
+	// The node drops the first MessagePosted subscription and refuses the next one
+	var watches atomic.Int32
+	ethClient := mocks.NewMockEthClient(ctrl)
+	ethClient.EXPECT().
+		SubscribeFilterLogs(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+			if query.Topics[0][0] != messagePostedID {
+				return event.NewSubscription(func(quit <-chan struct{}) error {
+					<-quit
+					return nil
+				}), nil
+			}
+
+			switch watches.Add(1) {
+			case 1:
+				return event.NewSubscription(func(quit <-chan struct{}) error {
+					return errors.New("connection reset")
+				}), nil
+			case 2:
+				return nil, errors.New("connection refused")
+			default:
+				return event.NewSubscription(func(quit <-chan struct{}) error {
+					<-quit
+					return nil
+				}), nil
+			}
+		}).
+		AnyTimes()
+
+	subs := newOutboxSubscriptions(zaptest.NewLogger(t), crossChainCallRequestedBufferSize, newCanceledMessages(canceledMessagesSize))
+	defer subs.stopAll()
+
+	chain := &client.ChainClient{
+		Client: ethClient,
+		Config: config.ChainConfig{
+			ChainID:         testSourceChainID,
+			OutboxAddresses: map[string]common.Address{config.ProverOPStack: outbox},
+		},
+	}
+	require.NoError(t, subs.sync(ctx, map[uint64]*client.ChainClient{testSourceChainID: chain}))
+
+	require.Eventually(t, func() bool { return watches.Load() == 3 }, time.Second, 10*time.Millisecond)
+	sub := subs.subs[outboxKey{chainID: testSourceChainID, address: outbox}]
+	require.NotNil(t, sub)
+	select {
+	case <-sub.done:
+		t.Fatal("subscription stopped after a drop")
+	default:
+	}
+}
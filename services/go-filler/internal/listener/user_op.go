@@ -0,0 +1,164 @@
+package listener
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/bindings/entrypoint"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+// validateUserOp runs the ERC-4337 checks that would otherwise only fail inside handleOps, once the op has been
+// packed into the handleOps call
+func (l *OutboxListener) validateUserOp(
+	ctx context.Context,
+	destChain *client.ChainClient,
+	op *abi.PackedUserOperation,
+	call ethereum.CallMsg,
+) error {
+	if err := op.Validate(); err != nil {
+		return fmt.Errorf("malformed user op: %w", err)
+	}
+
+	if l.paymaster != nil {
+		expected, err := l.paymaster.Address(ctx, destChain)
+		if err != nil {
+			return err
+		}
+
+		if op.Paymaster() != expected {
+			return fmt.Errorf("user op paymaster mismatch, want: %s, got: %s", expected.Hex(), op.Paymaster().Hex())
+		}
+	}
+
+	if err := validateInitCode(ctx, destChain, op); err != nil {
+		return err
+	}
+
+	if err := simulateHandleOps(ctx, destChain, call); err != nil {
+		return err
+	}
+
+	l.logger.Info("Valid user op",
+		zap.Stringer("sender", op.Sender),
+		zap.Stringer("verification_gas_limit", op.VerificationGasLimit()),
+		zap.Stringer("call_gas_limit", op.CallGasLimit()),
+		zap.Stringer("paymaster_verification_gas_limit", op.PaymasterVerificationGasLimit()),
+		zap.Stringer("paymaster_post_op_gas_limit", op.PaymasterPostOpGasLimit()),
+		zap.Stringer("pre_verification_gas", op.PreVerificationGas),
+		zap.Stringer("max_fee_per_gas", op.MaxFeePerGas()),
+		zap.Stringer("max_priority_fee_per_gas", op.MaxPriorityFeePerGas()),
+	)
+
+	return nil
+}
+
+// validateInitCode checks the sender is deployed, or is deployed by the op through an existing factory
+func validateInitCode(ctx context.Context, destChain *client.ChainClient, op *abi.PackedUserOperation) error {
+	senderCode, err := destChain.Client.CodeAt(ctx, op.Sender, nil)
+	if err != nil {
+		return fmt.Errorf("getting sender code: %w", err)
+	}
+
+	if len(op.InitCode) == 0 {
+		if len(senderCode) == 0 {
+			return fmt.Errorf("user op sender %s is not deployed and has no initCode", op.Sender.Hex())
+		}
+		return nil
+	}
+
+	if len(senderCode) > 0 {
+		return fmt.Errorf("user op sender %s is already deployed but has an initCode", op.Sender.Hex())
+	}
+
+	factoryCode, err := destChain.Client.CodeAt(ctx, op.Factory(), nil)
+	if err != nil {
+		return fmt.Errorf("getting factory code: %w", err)
+	}
+	if len(factoryCode) == 0 {
+		return fmt.Errorf("user op factory %s is not deployed", op.Factory().Hex())
+	}
+
+	return nil
+}
+
+// simulateHandleOps runs the handleOps call with eth_call. The EntryPoint v0.7 binding has no simulateValidation, but
+// handleOps performs the same validation and reverts with FailedOp, which carries the AAxx reason. No state override
+// is needed as handleOps does not depend on the caller balance.
+func simulateHandleOps(ctx context.Context, destChain *client.ChainClient, call ethereum.CallMsg) error {
+	_, err := destChain.Client.CallContract(ctx, call, nil)
+	if err == nil {
+		return nil
+	}
+
+	if reason, ok := decodeEntryPointRevert(err); ok {
+		return fmt.Errorf("simulating handleOps: %s", reason)
+	}
+	return fmt.Errorf("simulating handleOps: %w", err)
+}
+
+// decodeEntryPointRevert decodes the EntryPoint custom error carried by an eth_call error
+func decodeEntryPointRevert(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return "", false
+	}
+
+	encoded, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return "", false
+	}
+
+	data, decodeErr := hexutil.Decode(encoded)
+	if decodeErr != nil || len(data) < selectorSize {
+		return "", false
+	}
+
+	entrypointAbi, abiErr := entrypoint.EntrypointMetaData.GetAbi()
+	if abiErr != nil {
+		return "", false
+	}
+
+	for name, abiError := range entrypointAbi.Errors {
+		if !bytes.Equal(abiError.ID[:selectorSize], data[:selectorSize]) {
+			continue
+		}
+
+		args, unpackErr := abiError.Inputs.Unpack(data[selectorSize:])
+		if unpackErr != nil {
+			return name, true
+		}
+		return fmt.Sprintf("%s%v", name, args), true
+	}
+
+	return "", false
+}
+
+// validateUserOpGas rejects ops whose gas limits would make the reward unprofitable if fully used. The paymaster
+// sponsors up to the op required gas from our balance on top of the magic spend it releases.
+func (l *OutboxListener) validateUserOpGas(
+	op *abi.PackedUserOperation,
+	attributes *MessageAttributes,
+	gasLimitAndPrice GasLimitAndPrice,
+) error {
+	worstCase := new(big.Int).Mul(op.RequiredGas(), gasLimitAndPrice.GasPrice)
+	worstCase.Add(worstCase, attributes.MagicSpendAmount.ToBig())
+
+	if worstCase.Cmp(attributes.RewardAmount.ToBig()) >= 0 {
+		return fmt.Errorf(
+			"user op gas limits make the reward unprofitable, worst case cost: %d, reward: %d",
+			worstCase,
+			attributes.RewardAmount.ToBig(),
+		)
+	}
+
+	return nil
+}
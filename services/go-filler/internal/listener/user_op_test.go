@@ -0,0 +1,140 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/mock/gomock"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/entrypoint"
+	"github.com/base-org/RRC-7755-poc/internal/abi"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+// revertError mimics the error returned by eth_call for a reverted call
+type revertError struct {
+	data string
+}
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+func newUserOpTestChain(ethClient client.EthClient) *client.ChainClient {
+	return &client.ChainClient{
+		Client: ethClient,
+		Config: config.ChainConfig{ChainID: testDestChainID},
+	}
+}
+
+func TestValidateInitCode(t *testing.T) {
+	sender := common.HexToAddress("0x5678")
+	factory := common.HexToAddress("0x9abc")
+	deployed := []byte{0x60, 0x80}
+
+	tests := []struct {
+		name     string
+		initCode []byte
+		code     map[common.Address][]byte
+		wantErr  string
+	}{
+		{
+			name: "deployed sender",
+			code: map[common.Address][]byte{sender: deployed},
+		},
+		{
+			name:    "undeployed sender without init code",
+			wantErr: "is not deployed and has no initCode",
+		},
+		{
+			name:     "deployed sender with init code",
+			initCode: append(factory.Bytes(), 0x01),
+			code:     map[common.Address][]byte{sender: deployed, factory: deployed},
+			wantErr:  "is already deployed but has an initCode",
+		},
+		{
+			name:     "init code with deployed factory",
+			initCode: append(factory.Bytes(), 0x01),
+			code:     map[common.Address][]byte{factory: deployed},
+		},
+		{
+			name:     "init code with missing factory",
+			initCode: append(factory.Bytes(), 0x01),
+			wantErr:  "factory " + factory.Hex() + " is not deployed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			ethClient := mocks.NewMockEthClient(ctrl)
+			ethClient.EXPECT().
+				CodeAt(gomock.Any(), gomock.Any(), gomock.Nil()).
+				DoAndReturn(func(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+					return tt.code[account], nil
+				}).
+				AnyTimes()
+
+			op := &abi.PackedUserOperation{Sender: sender, InitCode: tt.initCode}
+			err := validateInitCode(context.Background(), newUserOpTestChain(ethClient), op)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSimulateHandleOpsDecodesFailedOp(t *testing.T) {
+	entrypointAbi, err := entrypoint.EntrypointMetaData.GetAbi()
+	require.NoError(t, err)
+
+	failedOp := entrypointAbi.Errors["FailedOp"]
+	encoded, err := failedOp.Inputs.Pack(big.NewInt(0), "AA21 didn't pay prefund")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	ethClient := mocks.NewMockEthClient(ctrl)
+	ethClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
+		Return(nil, revertError{data: hexutil.Encode(append(failedOp.ID[:selectorSize], encoded...))})
+
+	err = simulateHandleOps(context.Background(), newUserOpTestChain(ethClient), ethereum.CallMsg{})
+	require.ErrorContains(t, err, "FailedOp[0 AA21 didn't pay prefund]")
+
+	ethClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
+		Return(nil, errors.New("connection reset"))
+
+	err = simulateHandleOps(context.Background(), newUserOpTestChain(ethClient), ethereum.CallMsg{})
+	require.ErrorContains(t, err, "connection reset")
+}
+
+func TestValidateUserOpGas(t *testing.T) {
+	l := &OutboxListener{logger: zaptest.NewLogger(t)}
+
+	var accountGasLimits [32]byte
+	big.NewInt(100000).FillBytes(accountGasLimits[:16])
+	big.NewInt(100000).FillBytes(accountGasLimits[16:])
+	op := &abi.PackedUserOperation{AccountGasLimits: accountGasLimits, PreVerificationGas: big.NewInt(0)}
+
+	attributes := &MessageAttributes{RewardAmount: *uint256.NewInt(300000)}
+	gas := GasLimitAndPrice{GasLimit: big.NewInt(21000), GasPrice: big.NewInt(1)}
+
+	require.NoError(t, l.validateUserOpGas(op, attributes, gas))
+
+	// The op could burn 200000 gas at 2 wei on our paymaster balance
+	gas.GasPrice = big.NewInt(2)
+	err := l.validateUserOpGas(op, attributes, gas)
+	require.ErrorContains(t, err, "worst case cost: 400000, reward: 300000")
+}
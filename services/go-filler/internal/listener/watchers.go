@@ -0,0 +1,51 @@
+package listener
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// chainWatchers runs the services that subscribe to the chains of the client manager when they start, so they can be
+// restarted to follow the chains of a reloaded config
+type chainWatchers struct {
+	logger   *zap.Logger
+	services []Service
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newChainWatchers(logger *zap.Logger, services []Service) *chainWatchers {
+	return &chainWatchers{logger: logger, services: services}
+}
+
+// start runs every service until ctx is done or the watchers are stopped
+func (w *chainWatchers) start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	for _, service := range w.services {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			if err := service.Run(ctx); err != nil {
+				w.logger.Error("Running chain watcher", zap.String("service", service.ServiceName()), zap.Error(err))
+			}
+		}()
+	}
+}
+
+// stop stops the services and waits for them to return
+func (w *chainWatchers) stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// restart stops the services and runs them again on the current chains
+func (w *chainWatchers) restart(ctx context.Context) {
+	w.stop()
+	w.start(ctx)
+	w.logger.Info("Restarted chain watchers after config reload", zap.Int("services", len(w.services)))
+}
@@ -0,0 +1,45 @@
+package listener
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// countingService signals every start and every return of its runs
+type countingService struct {
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (s *countingService) ServiceName() string {
+	return "CountingService"
+}
+
+func (s *countingService) Run(ctx context.Context) error {
+	s.started <- struct{}{}
+	<-ctx.Done()
+	s.stopped <- struct{}{}
+	return nil
+}
+
+func TestChainWatchersRestart(t *testing.T) {
+	service := &countingService{started: make(chan struct{}, 2), stopped: make(chan struct{}, 2)}
+	watchers := newChainWatchers(zaptest.NewLogger(t), []Service{service})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watchers.start(ctx)
+	<-service.started
+
+	// The running service returns before it is started again
+	watchers.restart(ctx)
+	require.Len(t, service.stopped, 1)
+	<-service.started
+
+	watchers.stop()
+	require.Len(t, service.stopped, 2)
+}
@@ -0,0 +1,61 @@
+package market
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// gasPrices keeps the gas prices paid by the last competitor fulfillments of every destination chain
+type gasPrices struct {
+	mu     sync.Mutex
+	window int
+	chains map[uint64]*gasPriceWindow
+}
+
+type gasPriceWindow struct {
+	prices []*big.Int
+	next   int
+}
+
+func newGasPrices(window int) *gasPrices {
+	return &gasPrices{window: window, chains: make(map[uint64]*gasPriceWindow)}
+}
+
+func (g *gasPrices) add(chainID uint64, price *big.Int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	w, ok := g.chains[chainID]
+	if !ok {
+		w = &gasPriceWindow{}
+		g.chains[chainID] = w
+	}
+
+	if len(w.prices) < g.window {
+		w.prices = append(w.prices, price)
+		return
+	}
+	w.prices[w.next] = price
+	w.next = (w.next + 1) % g.window
+}
+
+// percentile returns the nearest rank percentile of the gas prices of the chain, false when none was seen yet
+func (g *gasPrices) percentile(chainID uint64, percentile float64) (*big.Int, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	w, ok := g.chains[chainID]
+	if !ok || len(w.prices) == 0 {
+		return nil, false
+	}
+
+	sorted := make([]*big.Int, len(w.prices))
+	copy(sorted, w.prices)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return new(big.Int).Set(sorted[rank-1]), true
+}
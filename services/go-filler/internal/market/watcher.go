@@ -0,0 +1,311 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_inbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+const (
+	fulfilledBufferSize = 10
+
+	// Number of posted messages remembered to join fulfillments with
+	postedMessagesSize = 10000
+)
+
+// Fulfillment is a CallFulfilled event of a destination inbox joined with the MessagePosted of its request
+type Fulfillment struct {
+	Timestamp        time.Time      `json:"timestamp"`
+	MessageID        common.Hash    `json:"message_id"`
+	DestinationChain uint64         `json:"destination_chain"`
+	Block            uint64         `json:"block"`
+	TxHash           common.Hash    `json:"tx_hash"`
+	Fulfiller        common.Address `json:"fulfiller"`
+	Ours             bool           `json:"ours"`
+	// GasPrice is the effective gas price of the fulfillment transaction
+	GasPrice *big.Int `json:"gas_price"`
+	// Joined is set when the MessagePosted of the request was seen, SourceChain, PostedBlock and Latency are only set
+	// then
+	Joined      bool          `json:"joined"`
+	SourceChain uint64        `json:"source_chain,omitempty"`
+	PostedBlock uint64        `json:"posted_block,omitempty"`
+	Latency     time.Duration `json:"latency,omitempty"`
+}
+
+type posted struct {
+	sourceChain uint64
+	block       uint64
+}
+
+type fulfilled struct {
+	chainID uint64
+	event   *rrc_7755_inbox.RRC7755InboxCallFulfilled
+}
+
+// Watcher follows the fulfillments of every destination inbox to learn the latency and gas price of the competing
+// fulfillers, which the listener bids against
+type Watcher struct {
+	config config.MarketConfig
+	wallet common.Address
+	logger *zap.Logger
+
+	clientMgr *client.Manager
+	getChain  func(chainID uint64) (*client.ChainClient, error)
+
+	mu     sync.Mutex
+	posted map[[32]byte]posted
+	order  [][32]byte
+
+	gasPrices *gasPrices
+	file      *os.File
+	now       func() time.Time
+}
+
+func NewWatcher(clientMgr *client.Manager, cfg *config.Config, logger *zap.Logger) (*Watcher, error) {
+	w := &Watcher{
+		config:    cfg.Market,
+		wallet:    cfg.Wallets.GetFromAddress(),
+		logger:    logger,
+		clientMgr: clientMgr,
+		getChain:  clientMgr.GetChainClient,
+		posted:    make(map[[32]byte]posted),
+		gasPrices: newGasPrices(cfg.Market.Window),
+		now:       time.Now,
+	}
+
+	if cfg.Market.OutputPath != "" {
+		file, err := os.OpenFile(cfg.Market.OutputPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening market output file: %w", err)
+		}
+		w.file = file
+	}
+
+	return w, nil
+}
+
+func (w *Watcher) ServiceName() string {
+	return "MarketWatcher"
+}
+
+// Close releases the output file
+func (w *Watcher) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// ObservePosted remembers a posted request so its fulfillment can be joined with it
+func (w *Watcher) ObservePosted(messageID [32]byte, sourceChain uint64, block uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.posted[messageID]; ok {
+		return
+	}
+
+	if len(w.order) >= postedMessagesSize {
+		delete(w.posted, w.order[0])
+		w.order = w.order[1:]
+	}
+	w.posted[messageID] = posted{sourceChain: sourceChain, block: block}
+	w.order = append(w.order, messageID)
+}
+
+// BidGasPrice returns the gas price to fulfill on the chain with: the outbid percentile of the competitor gas prices
+// raised by the outbid premium, or suggested when it is higher or no competitor was seen
+func (w *Watcher) BidGasPrice(chainID uint64, suggested *big.Int) *big.Int {
+	if w.config.OutbidPercentile == 0 {
+		return suggested
+	}
+
+	competitor, ok := w.gasPrices.percentile(chainID, w.config.OutbidPercentile)
+	if !ok {
+		return suggested
+	}
+
+	bid, _ := new(big.Float).Mul(
+		new(big.Float).SetInt(competitor),
+		big.NewFloat(1+w.config.OutbidPremium),
+	).Int(nil)
+	if bid.Cmp(suggested) <= 0 {
+		return suggested
+	}
+	return bid
+}
+
+// Run watches the CallFulfilled events of every destination inbox until ctx is done
+func (w *Watcher) Run(ctx context.Context) error {
+	events := make(chan fulfilled, fulfilledBufferSize)
+
+	for _, chain := range w.clientMgr.GetAllClients() {
+		if !chain.Config.IsDestination() {
+			continue
+		}
+
+		if err := w.watch(ctx, chain, events); err != nil {
+			w.logger.Error("Watching inbox fulfillments", zap.Uint64("chain_id", chain.Config.ChainID), zap.Error(err))
+		}
+	}
+
+	for {
+		select {
+		case f := <-events:
+			if err := w.handleFulfilled(ctx, f.chainID, f.event); err != nil {
+				w.logger.Error("Recording fulfillment", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (w *Watcher) watch(ctx context.Context, chain *client.ChainClient, events chan<- fulfilled) error {
+	inbox, err := rrc_7755_inbox.NewRRC7755InboxFilterer(chain.Config.InboxAddress, chain.Client)
+	if err != nil {
+		return fmt.Errorf("binding inbox: %w", err)
+	}
+
+	sink := make(chan *rrc_7755_inbox.RRC7755InboxCallFulfilled, fulfilledBufferSize)
+	sub, err := inbox.WatchCallFulfilled(&bind.WatchOpts{Context: ctx}, sink, nil, nil)
+	if err != nil {
+		return fmt.Errorf("creating WatchCallFulfilled subscription: %w", err)
+	}
+
+	w.logger.Info("Started inbox WatchCallFulfilled",
+		zap.Uint64("chain_id", chain.Config.ChainID),
+		zap.String("inbox_address", chain.Config.InboxAddress.Hex()),
+	)
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case event := <-sink:
+				select {
+				case events <- fulfilled{chainID: chain.Config.ChainID, event: event}:
+				case <-ctx.Done():
+					return
+				}
+			case err := <-sub.Err():
+				if ctx.Err() == nil {
+					w.logger.Error("Inbox fulfillment subscription dropped",
+						zap.Uint64("chain_id", chain.Config.ChainID),
+						zap.Error(err),
+					)
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleFulfilled joins a fulfillment with its posted request, reads the gas price it paid and records it. Only the
+// gas prices of competitors are bid against.
+func (w *Watcher) handleFulfilled(ctx context.Context, chainID uint64, event *rrc_7755_inbox.RRC7755InboxCallFulfilled) error {
+	destChain, err := w.getChain(chainID)
+	if err != nil {
+		return err
+	}
+
+	f := &Fulfillment{
+		Timestamp:        w.now().UTC(),
+		MessageID:        event.MessageId,
+		DestinationChain: chainID,
+		Block:            event.Raw.BlockNumber,
+		TxHash:           event.Raw.TxHash,
+		Fulfiller:        event.FulfilledBy,
+		Ours:             event.FulfilledBy == w.wallet,
+	}
+
+	receipt, err := destChain.Client.TransactionReceipt(ctx, event.Raw.TxHash)
+	if err != nil {
+		return fmt.Errorf("getting fulfillment receipt: %w", err)
+	}
+	f.GasPrice = receipt.EffectiveGasPrice
+
+	w.mu.Lock()
+	p, ok := w.posted[event.MessageId]
+	w.mu.Unlock()
+
+	if ok {
+		latency, err := w.latency(ctx, p, destChain, event.Raw.BlockNumber)
+		if err != nil {
+			return err
+		}
+		f.Joined = true
+		f.SourceChain = p.sourceChain
+		f.PostedBlock = p.block
+		f.Latency = latency
+	}
+
+	if !f.Ours && f.GasPrice != nil {
+		w.gasPrices.add(chainID, f.GasPrice)
+	}
+
+	return w.record(f)
+}
+
+// latency is the time between the blocks the request was posted and fulfilled in
+func (w *Watcher) latency(ctx context.Context, p posted, destChain *client.ChainClient, block uint64) (time.Duration, error) {
+	sourceChain, err := w.getChain(p.sourceChain)
+	if err != nil {
+		return 0, err
+	}
+
+	postedHeader, err := sourceChain.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(p.block))
+	if err != nil {
+		return 0, fmt.Errorf("getting posted block: %w", err)
+	}
+	fulfilledHeader, err := destChain.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return 0, fmt.Errorf("getting fulfilled block: %w", err)
+	}
+
+	return time.Duration(int64(fulfilledHeader.Time)-int64(postedHeader.Time)) * time.Second, nil
+}
+
+func (w *Watcher) record(f *Fulfillment) error {
+	w.logger.Info("Request fulfilled",
+		zap.String("message_id", f.MessageID.Hex()),
+		zap.Uint64("destination_chain", f.DestinationChain),
+		zap.String("fulfiller", f.Fulfiller.Hex()),
+		zap.Bool("ours", f.Ours),
+		zap.Stringer("gas_price", f.GasPrice),
+		zap.Bool("joined", f.Joined),
+		zap.Duration("latency", f.Latency),
+	)
+
+	if w.file == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("encoding fulfillment: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing fulfillment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package market
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_inbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+const (
+	testSourceChainID = 84532
+	testDestChainID   = 421614
+)
+
+var (
+	testWallet     = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	testCompetitor = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+)
+
+func newTestWatcher(t *testing.T, cfg config.MarketConfig, chains map[uint64]*client.ChainClient) *Watcher {
+	return &Watcher{
+		config: cfg,
+		wallet: testWallet,
+		logger: zaptest.NewLogger(t),
+		getChain: func(chainID uint64) (*client.ChainClient, error) {
+			return chains[chainID], nil
+		},
+		posted:    make(map[[32]byte]posted),
+		gasPrices: newGasPrices(cfg.Window),
+		now:       func() time.Time { return time.Unix(1700000000, 0) },
+	}
+}
+
+func TestBidGasPrice(t *testing.T) {
+	w := newTestWatcher(t, config.MarketConfig{Window: 3, OutbidPercentile: 50, OutbidPremium: 0.1}, nil)
+
+	// Without competitors the node suggestion is kept
+	require.Equal(t, big.NewInt(100), w.BidGasPrice(testDestChainID, big.NewInt(100)))
+
+	// The window only keeps the last 3 prices: 300, 400, 200
+	for _, price := range []int64{1000, 300, 400, 200} {
+		w.gasPrices.add(testDestChainID, big.NewInt(price))
+	}
+
+	require.Equal(t, big.NewInt(330), w.BidGasPrice(testDestChainID, big.NewInt(100)))
+	require.Equal(t, big.NewInt(500), w.BidGasPrice(testDestChainID, big.NewInt(500)))
+	require.Equal(t, big.NewInt(100), w.BidGasPrice(testSourceChainID, big.NewInt(100)))
+
+	w.config.OutbidPercentile = 0
+	require.Equal(t, big.NewInt(100), w.BidGasPrice(testDestChainID, big.NewInt(100)))
+}
+
+func TestHandleFulfilled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	sourceClient := mocks.NewMockEthClient(ctrl)
+	sourceClient.EXPECT().
+		HeaderByNumber(gomock.Any(), big.NewInt(100)).
+		Return(&types.Header{Time: 1700000000}, nil).
+		AnyTimes()
+
+	destClient := mocks.NewMockEthClient(ctrl)
+	destClient.EXPECT().
+		HeaderByNumber(gomock.Any(), big.NewInt(200)).
+		Return(&types.Header{Time: 1700000012}, nil).
+		AnyTimes()
+	destClient.EXPECT().
+		TransactionReceipt(gomock.Any(), gomock.Any()).
+		Return(&types.Receipt{EffectiveGasPrice: big.NewInt(7)}, nil).
+		AnyTimes()
+
+	chains := map[uint64]*client.ChainClient{
+		testSourceChainID: {Client: sourceClient},
+		testDestChainID:   {Client: destClient},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "market.jsonl")
+	file, err := os.Create(outputPath)
+	require.NoError(t, err)
+
+	w := newTestWatcher(t, config.MarketConfig{Window: 10, OutbidPercentile: 100}, chains)
+	w.file = file
+
+	w.ObservePosted([32]byte{1}, testSourceChainID, 100)
+
+	fulfilled := func(messageID [32]byte, fulfiller common.Address) *rrc_7755_inbox.RRC7755InboxCallFulfilled {
+		return &rrc_7755_inbox.RRC7755InboxCallFulfilled{
+			MessageId:   messageID,
+			FulfilledBy: fulfiller,
+			Raw:         types.Log{BlockNumber: 200},
+		}
+	}
+
+	require.NoError(t, w.handleFulfilled(ctx, testDestChainID, fulfilled([32]byte{1}, testCompetitor)))
+	// Our own fulfillments are recorded but not bid against
+	require.NoError(t, w.handleFulfilled(ctx, testDestChainID, fulfilled([32]byte{2}, testWallet)))
+	require.NoError(t, w.Close())
+
+	require.Equal(t, big.NewInt(7), w.BidGasPrice(testDestChainID, big.NewInt(1)))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var records []Fulfillment
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var f Fulfillment
+		require.NoError(t, decoder.Decode(&f))
+		records = append(records, f)
+	}
+	require.Len(t, records, 2)
+
+	require.True(t, records[0].Joined)
+	require.Equal(t, uint64(testSourceChainID), records[0].SourceChain)
+	require.Equal(t, 12*time.Second, records[0].Latency)
+	require.Equal(t, testCompetitor, records[0].Fulfiller)
+	require.Equal(t, big.NewInt(7), records[0].GasPrice)
+
+	require.True(t, records[1].Ours)
+	require.False(t, records[1].Joined)
+}
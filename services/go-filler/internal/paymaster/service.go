@@ -0,0 +1,366 @@
+package paymaster
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/paymaster"
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_inbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+const defaultPollInterval = 30 * time.Second
+
+// NativeAsset is the token address the paymaster uses for eth
+var NativeAsset = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// Contract is the subset of the Paymaster binding used to manage the fulfiller balances
+type Contract interface {
+	GetGasBalance(opts *bind.CallOpts, account common.Address) (*big.Int, error)
+	GetMagicSpendBalance(opts *bind.CallOpts, account common.Address, token common.Address) (*big.Int, error)
+	EntryPointDeposit(opts *bind.TransactOpts, amount *big.Int) (*types.Transaction, error)
+	MagicSpendDeposit(opts *bind.TransactOpts, token common.Address, amount *big.Int) (*types.Transaction, error)
+	WithdrawTo(opts *bind.TransactOpts, token common.Address, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error)
+	EntryPointWithdrawTo(opts *bind.TransactOpts, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error)
+}
+
+// Balances are the fulfiller balances held by a paymaster
+type Balances struct {
+	Gas        *big.Int
+	MagicSpend *big.Int
+}
+
+// Service keeps the fulfiller gas and magic spend balances of every destination chain paymaster between the
+// configured thresholds. It mirrors the GasSponsorService and MagicSpendService of the TS fulfiller. When balance
+// management is disabled it only reads the balances.
+type Service struct {
+	config    config.PaymasterConfig
+	wallets   config.WalletConfig
+	logger    *zap.Logger
+	clientMgr *client.Manager
+	// dryRun only logs the balance changes, it is set in shadow mode
+	dryRun bool
+
+	// mu serializes balance changes so concurrent UserOps don't top up twice
+	mu sync.Mutex
+	// sendMu is held from reading the nonce of a paymaster transaction to sending it, it is shared with the other
+	// senders of the fulfiller wallet so they don't reuse the nonce
+	sendMu sync.Locker
+	// paymasters caches the paymaster deployed by each inbox
+	paymasters map[common.Address]common.Address
+
+	bindContract func(address common.Address, chain *client.ChainClient) (Contract, error)
+	waitMined    func(ctx context.Context, chain *client.ChainClient, tx *types.Transaction) error
+}
+
+// NewService returns the paymaster service of the fulfiller wallet, sendMu being the lock its other senders hold while
+// they pick a nonce and send
+func NewService(clientMgr *client.Manager, cfg *config.Config, sendMu sync.Locker, logger *zap.Logger) *Service {
+	return &Service{
+		config:     cfg.Paymaster,
+		wallets:    cfg.Wallets,
+		logger:     logger,
+		clientMgr:  clientMgr,
+		sendMu:     sendMu,
+		dryRun:     cfg.Shadow.Enabled,
+		paymasters: make(map[common.Address]common.Address),
+		bindContract: func(address common.Address, chain *client.ChainClient) (Contract, error) {
+			return paymaster.NewPaymaster(address, chain.Client)
+		},
+		waitMined: func(ctx context.Context, chain *client.ChainClient, tx *types.Transaction) error {
+			receipt, err := bind.WaitMined(ctx, chain.Client, tx)
+			if err != nil {
+				return err
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+			}
+			return nil
+		},
+	}
+}
+
+func (s *Service) ServiceName() string {
+	return "PaymasterService"
+}
+
+// Run rebalances the paymaster of every destination chain at the configured poll interval
+func (s *Service) Run(ctx context.Context) error {
+	interval := s.config.PollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, chain := range s.clientMgr.GetAllClients() {
+			if !chain.Config.IsDestination() {
+				continue
+			}
+
+			if err := s.Rebalance(ctx, chain); err != nil {
+				s.logger.Error("Rebalancing paymaster", zap.Uint64("chain_id", chain.Config.ChainID), zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// EnsureBalances reads the paymaster balances before a UserOp is sent and tops them up when they fell below their
+// minimum. Top-ups are mined before returning so the UserOp can rely on them.
+func (s *Service) EnsureBalances(ctx context.Context, chain *client.ChainClient) (Balances, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contract, err := s.contract(ctx, chain)
+	if err != nil {
+		return Balances{}, err
+	}
+
+	balances, err := s.balances(ctx, contract)
+	if err != nil {
+		return Balances{}, err
+	}
+
+	if err := s.topUp(ctx, chain, contract, balances); err != nil {
+		return Balances{}, err
+	}
+
+	return s.balances(ctx, contract)
+}
+
+// MagicSpendBalance returns the fulfiller magic spend balance of token in the chain paymaster
+func (s *Service) MagicSpendBalance(ctx context.Context, chain *client.ChainClient, token common.Address) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contract, err := s.contract(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := contract.GetMagicSpendBalance(&bind.CallOpts{Context: ctx}, s.wallets.GetFromAddress(), token)
+	if err != nil {
+		return nil, fmt.Errorf("getting magic spend balance: %w", err)
+	}
+	return balance, nil
+}
+
+// Rebalance tops up balances below their minimum and withdraws the surplus of balances above their maximum
+func (s *Service) Rebalance(ctx context.Context, chain *client.ChainClient) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contract, err := s.contract(ctx, chain)
+	if err != nil {
+		return err
+	}
+
+	balances, err := s.balances(ctx, contract)
+	if err != nil {
+		return err
+	}
+
+	if err := s.topUp(ctx, chain, contract, balances); err != nil {
+		return err
+	}
+
+	return s.withdrawSurplus(ctx, chain, contract, balances)
+}
+
+// Address returns the paymaster deployed by the chain inbox, the only paymaster our fulfillments may use
+func (s *Service) Address(ctx context.Context, chain *client.ChainClient) (common.Address, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.address(ctx, chain)
+}
+
+func (s *Service) address(ctx context.Context, chain *client.ChainClient) (common.Address, error) {
+	if address, ok := s.paymasters[chain.Config.InboxAddress]; ok {
+		return address, nil
+	}
+
+	inbox, err := rrc_7755_inbox.NewRRC7755InboxCaller(chain.Config.InboxAddress, chain.Client)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("binding inbox on chain %d: %w", chain.Config.ChainID, err)
+	}
+
+	address, err := inbox.PAYMASTER(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return common.Address{}, fmt.Errorf("getting paymaster address on chain %d: %w", chain.Config.ChainID, err)
+	}
+	s.paymasters[chain.Config.InboxAddress] = address
+
+	return address, nil
+}
+
+// contract binds the paymaster deployed by the chain inbox
+func (s *Service) contract(ctx context.Context, chain *client.ChainClient) (Contract, error) {
+	address, err := s.address(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := s.bindContract(address, chain)
+	if err != nil {
+		return nil, fmt.Errorf("binding paymaster on chain %d: %w", chain.Config.ChainID, err)
+	}
+	return contract, nil
+}
+
+func (s *Service) balances(ctx context.Context, contract Contract) (Balances, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	fulfiller := s.wallets.GetFromAddress()
+
+	gas, err := contract.GetGasBalance(opts, fulfiller)
+	if err != nil {
+		return Balances{}, fmt.Errorf("getting gas balance: %w", err)
+	}
+
+	magicSpend, err := contract.GetMagicSpendBalance(opts, fulfiller, NativeAsset)
+	if err != nil {
+		return Balances{}, fmt.Errorf("getting magic spend balance: %w", err)
+	}
+
+	return Balances{Gas: gas, MagicSpend: magicSpend}, nil
+}
+
+func (s *Service) topUp(ctx context.Context, chain *client.ChainClient, contract Contract, balances Balances) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	if amount := topUpAmount(balances.Gas, s.config.Gas); amount != nil {
+		err := s.transact(ctx, chain, "entryPointDeposit", balances.Gas, amount, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			opts.Value = amount
+			return contract.EntryPointDeposit(opts, amount)
+		})
+		if err != nil {
+			return fmt.Errorf("topping up gas balance: %w", err)
+		}
+	}
+
+	if amount := topUpAmount(balances.MagicSpend, s.config.MagicSpend); amount != nil {
+		err := s.transact(ctx, chain, "magicSpendDeposit", balances.MagicSpend, amount, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			opts.Value = amount
+			return contract.MagicSpendDeposit(opts, NativeAsset, amount)
+		})
+		if err != nil {
+			return fmt.Errorf("topping up magic spend balance: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) withdrawSurplus(ctx context.Context, chain *client.ChainClient, contract Contract, balances Balances) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	recipient := s.wallets.GetRecipientAddress()
+	if s.wallets.RecipientAddress == "" {
+		recipient = s.wallets.GetFromAddress()
+	}
+
+	if amount := surplusAmount(balances.Gas, s.config.Gas); amount != nil {
+		err := s.transact(ctx, chain, "entryPointWithdrawTo", balances.Gas, amount, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.EntryPointWithdrawTo(opts, recipient, amount)
+		})
+		if err != nil {
+			return fmt.Errorf("withdrawing gas surplus: %w", err)
+		}
+	}
+
+	if amount := surplusAmount(balances.MagicSpend, s.config.MagicSpend); amount != nil {
+		err := s.transact(ctx, chain, "withdrawTo", balances.MagicSpend, amount, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.WithdrawTo(opts, NativeAsset, recipient, amount)
+		})
+		if err != nil {
+			return fmt.Errorf("withdrawing magic spend surplus: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// transact sends a paymaster transaction from the fulfiller wallet and waits for it to be mined
+func (s *Service) transact(
+	ctx context.Context,
+	chain *client.ChainClient,
+	method string,
+	balance *big.Int,
+	amount *big.Int,
+	send func(opts *bind.TransactOpts) (*types.Transaction, error),
+) error {
+	logger := s.logger.With(
+		zap.Uint64("chain_id", chain.Config.ChainID),
+		zap.String("method", method),
+		zap.Stringer("balance", balance),
+		zap.Stringer("amount", amount),
+	)
+
+	if s.dryRun {
+		logger.Info("Shadow mode, skipping paymaster transaction")
+		return nil
+	}
+
+	privateKey, err := s.wallets.GetPrivateKey()
+	if err != nil {
+		return err
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(chain.Config.ChainID))
+	if err != nil {
+		return fmt.Errorf("creating transactor: %w", err)
+	}
+	opts.Context = ctx
+
+	// The transactor reads the pending nonce when sending, which must not race with the fulfillments
+	s.sendMu.Lock()
+	tx, err := send(opts)
+	s.sendMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("sending %s: %w", method, err)
+	}
+	logger.Info("Sent paymaster transaction", zap.String("tx_hash", tx.Hash().Hex()))
+
+	if err := s.waitMined(ctx, chain, tx); err != nil {
+		return fmt.Errorf("waiting for %s: %w", method, err)
+	}
+	logger.Info("Paymaster transaction mined", zap.String("tx_hash", tx.Hash().Hex()))
+
+	return nil
+}
+
+// topUpAmount returns the amount restoring balance to its target, or nil when balance is not below its minimum
+func topUpAmount(balance *big.Int, thresholds config.BalanceThresholds) *big.Int {
+	if thresholds.MinBalance == nil || balance.Cmp(thresholds.MinBalance) >= 0 {
+		return nil
+	}
+	return new(big.Int).Sub(thresholds.TargetBalance, balance)
+}
+
+// surplusAmount returns the amount above the target to withdraw, or nil when balance is not above its maximum
+func surplusAmount(balance *big.Int, thresholds config.BalanceThresholds) *big.Int {
+	if thresholds.MaxBalance == nil || balance.Cmp(thresholds.MaxBalance) <= 0 {
+		return nil
+	}
+	return new(big.Int).Sub(balance, thresholds.TargetBalance)
+}
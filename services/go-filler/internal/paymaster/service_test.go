@@ -0,0 +1,179 @@
+package paymaster
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+const (
+	testPrivateKey  = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	testFromAddress = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	testRecipient   = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+)
+
+type paymasterCall struct {
+	method string
+	value  *big.Int
+	to     common.Address
+	amount *big.Int
+}
+
+// sendLock is a send lock recording whether it is held
+type sendLock struct {
+	held bool
+}
+
+func (l *sendLock) Lock() {
+	l.held = true
+}
+
+func (l *sendLock) Unlock() {
+	l.held = false
+}
+
+// fakeContract tracks the fulfiller balances and applies the deposits and withdrawals sent to it
+type fakeContract struct {
+	gas        *big.Int
+	magicSpend *big.Int
+	calls      []paymasterCall
+
+	// sendMu is the send lock of the service, unlocked counts the transactions sent without holding it
+	sendMu   *sendLock
+	unlocked int
+}
+
+func (f *fakeContract) record(call paymasterCall) {
+	if f.sendMu != nil && !f.sendMu.held {
+		f.unlocked++
+	}
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeContract) GetGasBalance(opts *bind.CallOpts, account common.Address) (*big.Int, error) {
+	return new(big.Int).Set(f.gas), nil
+}
+
+func (f *fakeContract) GetMagicSpendBalance(opts *bind.CallOpts, account common.Address, token common.Address) (*big.Int, error) {
+	return new(big.Int).Set(f.magicSpend), nil
+}
+
+func (f *fakeContract) EntryPointDeposit(opts *bind.TransactOpts, amount *big.Int) (*types.Transaction, error) {
+	f.record(paymasterCall{method: "entryPointDeposit", value: opts.Value, amount: amount})
+	f.gas.Add(f.gas, amount)
+	return types.NewTx(&types.LegacyTx{}), nil
+}
+
+func (f *fakeContract) MagicSpendDeposit(opts *bind.TransactOpts, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	f.record(paymasterCall{method: "magicSpendDeposit", value: opts.Value, amount: amount})
+	f.magicSpend.Add(f.magicSpend, amount)
+	return types.NewTx(&types.LegacyTx{}), nil
+}
+
+func (f *fakeContract) WithdrawTo(opts *bind.TransactOpts, token common.Address, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	f.record(paymasterCall{method: "withdrawTo", to: withdrawAddress, amount: amount})
+	f.magicSpend.Sub(f.magicSpend, amount)
+	return types.NewTx(&types.LegacyTx{}), nil
+}
+
+func (f *fakeContract) EntryPointWithdrawTo(opts *bind.TransactOpts, withdrawAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	f.record(paymasterCall{method: "entryPointWithdrawTo", to: withdrawAddress, amount: amount})
+	f.gas.Sub(f.gas, amount)
+	return types.NewTx(&types.LegacyTx{}), nil
+}
+
+func newTestService(t *testing.T, contract *fakeContract, dryRun bool) (*Service, *client.ChainClient) {
+	inbox := common.HexToAddress("0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb")
+	chain := &client.ChainClient{Config: config.ChainConfig{ChainID: 421614, InboxAddress: inbox}}
+
+	cfg := &config.Config{
+		Wallets: config.WalletConfig{
+			FromAddress:      testFromAddress,
+			PrivateKey:       testPrivateKey,
+			RecipientAddress: testRecipient,
+		},
+		Shadow: config.ShadowConfig{Enabled: dryRun},
+		Paymaster: config.PaymasterConfig{
+			Enabled: true,
+			Gas: config.BalanceThresholds{
+				MinBalance:    big.NewInt(100),
+				TargetBalance: big.NewInt(200),
+				MaxBalance:    big.NewInt(500),
+			},
+			MagicSpend: config.BalanceThresholds{
+				MinBalance:    big.NewInt(1000),
+				TargetBalance: big.NewInt(2000),
+			},
+		},
+	}
+
+	contract.sendMu = &sendLock{}
+	clientMgr := &client.Manager{Chains: map[uint64]*client.ChainClient{chain.Config.ChainID: chain}}
+	s := NewService(clientMgr, cfg, contract.sendMu, zaptest.NewLogger(t))
+	s.paymasters[inbox] = common.HexToAddress("0x1234")
+	s.bindContract = func(address common.Address, chain *client.ChainClient) (Contract, error) {
+		return contract, nil
+	}
+	s.waitMined = func(ctx context.Context, chain *client.ChainClient, tx *types.Transaction) error {
+		return nil
+	}
+
+	return s, chain
+}
+
+func TestEnsureBalancesTopsUp(t *testing.T) {
+	contract := &fakeContract{gas: big.NewInt(50), magicSpend: big.NewInt(500)}
+	s, chain := newTestService(t, contract, false)
+
+	balances, err := s.EnsureBalances(context.Background(), chain)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(200), balances.Gas)
+	require.Equal(t, big.NewInt(2000), balances.MagicSpend)
+
+	require.Equal(t, []paymasterCall{
+		{method: "entryPointDeposit", value: big.NewInt(150), amount: big.NewInt(150)},
+		{method: "magicSpendDeposit", value: big.NewInt(1500), amount: big.NewInt(1500)},
+	}, contract.calls)
+	// Every top-up is sent under the nonce lock shared with the fulfillments
+	require.Zero(t, contract.unlocked)
+}
+
+func TestEnsureBalancesKeepsSurplus(t *testing.T) {
+	contract := &fakeContract{gas: big.NewInt(800), magicSpend: big.NewInt(1000)}
+	s, chain := newTestService(t, contract, false)
+
+	balances, err := s.EnsureBalances(context.Background(), chain)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(800), balances.Gas)
+	require.Empty(t, contract.calls)
+}
+
+func TestRebalanceWithdrawsSurplus(t *testing.T) {
+	contract := &fakeContract{gas: big.NewInt(800), magicSpend: big.NewInt(1500)}
+	s, chain := newTestService(t, contract, false)
+
+	require.NoError(t, s.Rebalance(context.Background(), chain))
+	require.Equal(t, []paymasterCall{
+		{method: "entryPointWithdrawTo", to: common.HexToAddress(testRecipient), amount: big.NewInt(600)},
+	}, contract.calls)
+	require.Equal(t, big.NewInt(200), contract.gas)
+}
+
+func TestDryRunSkipsTransactions(t *testing.T) {
+	contract := &fakeContract{gas: big.NewInt(0), magicSpend: big.NewInt(0)}
+	s, chain := newTestService(t, contract, true)
+
+	balances, err := s.EnsureBalances(context.Background(), chain)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(0), balances.Gas)
+	require.Empty(t, contract.calls)
+}
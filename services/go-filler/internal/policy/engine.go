@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+// Rule names, logged when a rule fires
+const (
+	RuleMaxCalls       = "max-calls"
+	RuleRequesterDeny  = "requester-deny"
+	RuleRequesterAllow = "requester-allow"
+	RuleTargetDeny     = "target-deny"
+	RuleTargetAllow    = "target-allow"
+	RuleSelectorDeny   = "selector-deny"
+	RuleSelectorAllow  = "selector-allow"
+)
+
+const selectorSize = 4
+
+// ErrDenied is returned for requests on which a rule fired
+var ErrDenied = errors.New("denied by policy")
+
+// DeniedError names the rules that fired on a request, it matches ErrDenied
+type DeniedError struct {
+	Rules []string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("%s, rules: %s", ErrDenied, strings.Join(e.Rules, ", "))
+}
+
+func (e *DeniedError) Is(target error) bool {
+	return target == ErrDenied
+}
+
+// Call is a call of a request payload
+type Call struct {
+	To   [32]byte
+	Data []byte
+}
+
+// Request is what the policy is evaluated on
+type Request struct {
+	MessageID [32]byte
+	Requester [32]byte
+	Calls     []Call
+}
+
+type list[T comparable] struct {
+	allow map[T]struct{}
+	deny  map[T]struct{}
+}
+
+// fires returns the allow or deny rule the value breaks, if any
+func (l list[T]) fires(value T, allowRule string, denyRule string) (string, bool) {
+	if _, ok := l.deny[value]; ok {
+		return denyRule, true
+	}
+	if _, ok := l.allow[value]; len(l.allow) > 0 && !ok {
+		return allowRule, true
+	}
+	return "", false
+}
+
+// Engine evaluates the configured allow and deny lists and call limit on requests
+type Engine struct {
+	logger     *zap.Logger
+	maxCalls   int
+	requesters list[[32]byte]
+	targets    list[[32]byte]
+	selectors  list[[selectorSize]byte]
+}
+
+func NewEngine(cfg config.PolicyConfig, logger *zap.Logger) (*Engine, error) {
+	requesters, err := newList(cfg.Requesters, toBytes32)
+	if err != nil {
+		return nil, fmt.Errorf("parsing requesters: %w", err)
+	}
+	targets, err := newList(cfg.Targets, toBytes32)
+	if err != nil {
+		return nil, fmt.Errorf("parsing targets: %w", err)
+	}
+	selectors, err := newList(cfg.Selectors, toSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing selectors: %w", err)
+	}
+
+	return &Engine{
+		logger:     logger,
+		maxCalls:   cfg.MaxCalls,
+		requesters: requesters,
+		targets:    targets,
+		selectors:  selectors,
+	}, nil
+}
+
+// Evaluate runs every rule on the request, logs the ones that fired and returns a DeniedError naming them
+func (e *Engine) Evaluate(req *Request) error {
+	var fired []string
+	fire := func(rule string, fields ...zap.Field) {
+		fired = append(fired, rule)
+		e.logger.Info("Policy rule fired",
+			append([]zap.Field{
+				zap.String("rule", rule),
+				zap.String("message_id", common.Hash(req.MessageID).Hex()),
+			}, fields...)...,
+		)
+	}
+
+	if e.maxCalls > 0 && len(req.Calls) > e.maxCalls {
+		fire(RuleMaxCalls, zap.Int("calls", len(req.Calls)), zap.Int("max_calls", e.maxCalls))
+	}
+
+	if rule, ok := e.requesters.fires(req.Requester, RuleRequesterAllow, RuleRequesterDeny); ok {
+		fire(rule, zap.String("requester", common.Hash(req.Requester).Hex()))
+	}
+
+	for i, call := range req.Calls {
+		if rule, ok := e.targets.fires(call.To, RuleTargetAllow, RuleTargetDeny); ok {
+			fire(rule, zap.Int("call", i), zap.String("target", common.BytesToAddress(call.To[:]).Hex()))
+		}
+
+		// Calls without a selector, plain transfers, are only subject to an allow list of targets
+		if len(call.Data) < selectorSize {
+			continue
+		}
+		selector := [selectorSize]byte(call.Data[:selectorSize])
+		if rule, ok := e.selectors.fires(selector, RuleSelectorAllow, RuleSelectorDeny); ok {
+			fire(rule, zap.Int("call", i), zap.String("selector", hexutil.Encode(selector[:])))
+		}
+	}
+
+	if len(fired) > 0 {
+		return &DeniedError{Rules: fired}
+	}
+	return nil
+}
+
+func newList[T comparable](cfg config.ListConfig, parse func(string) (T, error)) (list[T], error) {
+	l := list[T]{allow: make(map[T]struct{}), deny: make(map[T]struct{})}
+	for _, entries := range []struct {
+		values []string
+		set    map[T]struct{}
+	}{{cfg.Allow, l.allow}, {cfg.Deny, l.deny}} {
+		for _, entry := range entries.values {
+			value, err := parse(entry)
+			if err != nil {
+				return list[T]{}, err
+			}
+			entries.set[value] = struct{}{}
+		}
+	}
+	return l, nil
+}
+
+// toBytes32 parses a bytes32 value or an address, left padded to 32 bytes like in the requests
+func toBytes32(entry string) ([32]byte, error) {
+	value, err := hexutil.Decode(entry)
+	if err != nil || (len(value) != common.AddressLength && len(value) != common.HashLength) {
+		return [32]byte{}, fmt.Errorf("invalid entry %q", entry)
+	}
+	return common.BytesToHash(value), nil
+}
+
+func toSelector(entry string) ([selectorSize]byte, error) {
+	value, err := hexutil.Decode(entry)
+	if err != nil || len(value) != selectorSize {
+		return [selectorSize]byte{}, fmt.Errorf("invalid entry %q", entry)
+	}
+	return [selectorSize]byte(value), nil
+}
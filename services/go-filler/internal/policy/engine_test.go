@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+var (
+	requester = common.HexToAddress("0x8C1a617BdB47342F9C17Ac8750E0b070c372C721")
+	token     = common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	mixer     = common.HexToAddress("0x722122dF12D4e14e13Ac3b6895a86e84145b6967")
+	approve   = hexutil.MustDecode("0x095ea7b3")
+	transfer  = hexutil.MustDecode("0xa9059cbb")
+)
+
+func call(to common.Address, selector []byte) Call {
+	return Call{To: common.BytesToHash(to.Bytes()), Data: append(append([]byte{}, selector...), make([]byte, 64)...)}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.PolicyConfig
+		req       *Request
+		wantRules []string
+	}{
+		{
+			name: "no rules",
+			req:  &Request{Calls: []Call{call(token, transfer)}},
+		},
+		{
+			name:      "max calls",
+			cfg:       config.PolicyConfig{MaxCalls: 1},
+			req:       &Request{Calls: []Call{call(token, transfer), call(token, transfer)}},
+			wantRules: []string{RuleMaxCalls},
+		},
+		{
+			name:      "denied requester as address",
+			cfg:       config.PolicyConfig{Requesters: config.ListConfig{Deny: []string{requester.Hex()}}},
+			req:       &Request{Requester: common.BytesToHash(requester.Bytes())},
+			wantRules: []string{RuleRequesterDeny},
+		},
+		{
+			name:      "requester not allowed",
+			cfg:       config.PolicyConfig{Requesters: config.ListConfig{Allow: []string{common.BytesToHash(requester.Bytes()).Hex()}}},
+			req:       &Request{Requester: [32]byte{1}},
+			wantRules: []string{RuleRequesterAllow},
+		},
+		{
+			name: "denied target and selector",
+			cfg: config.PolicyConfig{
+				Targets:   config.ListConfig{Deny: []string{mixer.Hex()}},
+				Selectors: config.ListConfig{Deny: []string{hexutil.Encode(approve)}},
+			},
+			req:       &Request{Calls: []Call{call(token, transfer), call(mixer, transfer), call(token, approve)}},
+			wantRules: []string{RuleTargetDeny, RuleSelectorDeny},
+		},
+		{
+			name: "allowed selectors ignore plain transfers",
+			cfg: config.PolicyConfig{
+				Targets:   config.ListConfig{Allow: []string{token.Hex()}},
+				Selectors: config.ListConfig{Allow: []string{hexutil.Encode(transfer)}},
+			},
+			req:       &Request{Calls: []Call{call(token, transfer), {To: common.BytesToHash(mixer.Bytes())}}},
+			wantRules: []string{RuleTargetAllow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine(tt.cfg, zaptest.NewLogger(t))
+			require.NoError(t, err)
+
+			err = engine.Evaluate(tt.req)
+			if len(tt.wantRules) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrDenied)
+			var denied *DeniedError
+			require.ErrorAs(t, err, &denied)
+			require.Equal(t, tt.wantRules, denied.Rules)
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidEntries(t *testing.T) {
+	_, err := NewEngine(config.PolicyConfig{Targets: config.ListConfig{Allow: []string{"0x1234"}}}, zaptest.NewLogger(t))
+	require.ErrorContains(t, err, `parsing targets: invalid entry "0x1234"`)
+}
@@ -4,7 +4,8 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/base-org/RRC-7755-poc/internal/prover/storage_prover"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"go.uber.org/zap"
 )
@@ -12,10 +13,10 @@ import (
 // ArbitrumStateProofResult contains all the Arbitrum-specific state proof components
 type ArbitrumStateProofResult struct {
 	EncodedBlockArray         []byte
-	AfterState                AssertionState
-	PrevAssertionHash         [32]byte
-	SequencerBatchAcc         [32]byte
-	DstL2StateRootProofParams storage_prover.StorageProofParams
+	AfterState                proofs.AssertionState
+	PrevAssertionHash         common.Hash
+	SequencerBatchAcc         common.Hash
+	DstL2StateRootProofParams proofs.AccountProofParams
 }
 
 // ArbitrumStateProver handles generation of Arbitrum-specific state proofs
@@ -43,16 +44,16 @@ func (p *ArbitrumStateProver) GenerateArbitrumStateProof(
 	// For now, return mock values that satisfy the structure
 	return &ArbitrumStateProofResult{
 		EncodedBlockArray: []byte{},
-		AfterState: AssertionState{
-			GlobalState: GlobalState{
-				Bytes32Vals: [2][32]byte{},
+		AfterState: proofs.AssertionState{
+			GlobalState: proofs.GlobalState{
+				Bytes32Vals: [2]common.Hash{},
 				U64Vals:     [2]uint64{},
 			},
-			MachineStatus:  FINISHED,
-			EndHistoryRoot: [32]byte{},
+			MachineStatus:  proofs.FINISHED,
+			EndHistoryRoot: common.Hash{},
 		},
-		PrevAssertionHash:         [32]byte{},
-		SequencerBatchAcc:         [32]byte{},
-		DstL2StateRootProofParams: storage_prover.StorageProofParams{},
+		PrevAssertionHash:         common.Hash{},
+		SequencerBatchAcc:         common.Hash{},
+		DstL2StateRootProofParams: proofs.AccountProofParams{},
 	}, nil
 }
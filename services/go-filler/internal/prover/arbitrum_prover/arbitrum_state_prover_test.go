@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
@@ -59,12 +60,12 @@ func (s *ArbitrumStateProverTestSuite) TestGenerateArbitrumStateProof_Success()
 	require.Len(s.T(), result.AfterState.GlobalState.U64Vals, 2)
 
 	// Validate machine status
-	require.Equal(s.T(), MachineStatus(FINISHED), result.AfterState.MachineStatus)
+	require.Equal(s.T(), proofs.FINISHED, result.AfterState.MachineStatus)
 
 	// Validate all components are initialized (even if zero)
 	require.NotNil(s.T(), result.DstL2StateRootProofParams)
 	require.NotNil(s.T(), result.EncodedBlockArray)
-	require.Equal(s.T(), [32]byte{}, result.AfterState.EndHistoryRoot)
+	require.Equal(s.T(), common.Hash{}, result.AfterState.EndHistoryRoot)
 }
 
 func (s *ArbitrumStateProverTestSuite) TestGenerateArbitrumStateProof_NilBlock() {
@@ -95,9 +96,9 @@ func (s *ArbitrumStateProverTestSuite) TestGenerateArbitrumStateProof_MockValues
 
 	// Test specific mock values
 	require.Empty(s.T(), result.EncodedBlockArray)
-	require.Equal(s.T(), MachineStatus(FINISHED), result.AfterState.MachineStatus)
-	require.Equal(s.T(), [32]byte{}, result.PrevAssertionHash)
-	require.Equal(s.T(), [32]byte{}, result.SequencerBatchAcc)
+	require.Equal(s.T(), proofs.FINISHED, result.AfterState.MachineStatus)
+	require.Equal(s.T(), common.Hash{}, result.PrevAssertionHash)
+	require.Equal(s.T(), common.Hash{}, result.SequencerBatchAcc)
 
 	// Verify GlobalState structure
 	require.Len(s.T(), result.AfterState.GlobalState.Bytes32Vals, 2)
@@ -3,10 +3,15 @@ package arbitrum_prover
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/base-org/RRC-7755-poc/internal/prover/l1_state_prover"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
 	"github.com/base-org/RRC-7755-poc/internal/prover/storage_prover"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 	"go.uber.org/zap"
 )
 
@@ -14,84 +19,29 @@ import (
 // the mapping of requestHash -> fulfillmentInfo
 const slotConstant = "0x40f2eef6aad3cb0e74d3b59b45d3d5f2d5fc8dc382e739617b693cdd4bc30c00"
 
-// Parameters needed for a full nested cross-L2 storage proof with Arbitrum as the destination chain
-type RRC7755Proof struct {
-	// These 4 following fields work together with DstL2StateRootProof to certify the L2 state (step 2 in overview.md)
-
-	// The RLP-encoded array of block headers of Arbitrum's L2 block corresponding to the above RBlock
-	// Hashing this bytes string should produce the blockhash
-	EncodedBlockArray []byte
-
-	// The state of the assertion node after the sequencer machine has finished
-	AfterState AssertionState
-
-	// The hash of the previous assertion
-	PrevAssertionHash [32]byte
-
-	// The accumulator of the sequencer batch
-	SequencerBatchAcc [32]byte
-
-	// Contains L1 block information & the associated proof (step 1 in overview.md)
-	// Parameters needed to validate the authenticity of Ethereum's execution client's state root
-	StateProofParams l1_state_prover.L1StateProof
-
-	// Contains L2 block information & the associated proof (step 2 in overview.md)
-	// Parameters needed to validate the authenticity of the l2Oracle for the destination L2 chain on Eth mainnet
-	DstL2StateRootProofParams storage_prover.StorageProofParams
-
-	// Contains proof that the inbox contract indeed contains an entry about a successful fulfillment (step 3 in overview.md)
-	// Parameters needed to validate the authenticity of a specified storage location on the destination L2
-	DstL2AccountProofParams storage_prover.StorageProofParams
-}
-
-// / @notice The status of the sequencer machine
-type MachineStatus int
-
-const (
-	RUNNING MachineStatus = iota
-	FINISHED
-	ERRORED
-)
-
-// The global state of arbitrum when the AssertionNode was created
-type GlobalState struct {
-	// An array containing the blockhash of the L2 block and the sendRoot
-	Bytes32Vals [2][32]byte
-	// An array containing the inbox position and the position in message of the assertion
-	U64Vals [2]uint64
-}
-
-// The state of the assertion node
-// AssertionState represents the state of an Arbitrum assertion
-type AssertionState struct {
-	// The global state of arbitrum when the AssertionNode was created
-	GlobalState GlobalState
-	// The status of the sequencer machine
-	MachineStatus MachineStatus
-	// The end history root of the assertion
-	EndHistoryRoot [32]byte
-}
-
 // RRC7755ArbitrumProver handles the generation of RRC7755 proofs for Arbitrum
 type RRC7755ArbitrumProver struct {
 	logger              *zap.Logger
 	l1StateProver       *l1_state_prover.L1StateProver
 	inboxStorageProver  *storage_prover.InboxStorageProver
 	arbitrumStateProver *ArbitrumStateProver
+	inbox               common.Address
 }
 
-// NewRRC7755ArbitrumProver creates a new RRC7755ArbitrumProver instance
+// NewRRC7755ArbitrumProver creates a new RRC7755ArbitrumProver instance proving the fulfillments of the inbox
 func NewRRC7755ArbitrumProver(
 	logger *zap.Logger,
 	l1Client l1_state_prover.L1Client,
 	l2Client storage_prover.L2Client,
+	inbox common.Address,
 	isDevnet bool,
+	rpcPolicy retry.Policy,
 ) *RRC7755ArbitrumProver {
 	// Create L1 state prover
-	l1StateProver := l1_state_prover.NewL1StateProver(logger, l1Client, isDevnet)
+	l1StateProver := l1_state_prover.NewL1StateProver(logger, l1Client, isDevnet, rpcPolicy)
 
 	// Create inbox storage prover
-	inboxStorageProver := storage_prover.NewInboxStorageProver(logger, l2Client)
+	inboxStorageProver := storage_prover.NewInboxStorageProver(logger, l2Client, rpcPolicy)
 
 	// Create Arbitrum state prover
 	arbitrumStateProver := NewArbitrumStateProver(logger)
@@ -101,17 +51,52 @@ func NewRRC7755ArbitrumProver(
 		l1StateProver:       l1StateProver,
 		inboxStorageProver:  inboxStorageProver,
 		arbitrumStateProver: arbitrumStateProver,
+		inbox:               inbox,
 	}
 }
 
-// GenerateProof generates a complete RRC7755 proof for Arbitrum
+// SharedProof is the part of the proof shared by every request proven against the same L1 anchor block: the L1 state
+// proof and the Arbitrum state proof (steps 1 and 2 in overview.md)
+type SharedProof struct {
+	AnchorBlock      uint64
+	StateProofParams proofs.StateProofParams
+	ArbitrumState    ArbitrumStateProofResult
+}
+
+// GenerateProof generates a complete RRC7755 proof for Arbitrum of the request fulfilled in the L2 block
+// fulfillmentBlock. It fails with proofs.ErrProofNotAvailable until an assertion past that block is confirmed.
 func (p *RRC7755ArbitrumProver) GenerateProof(
 	ctx context.Context,
-	contractAddr common.Address,
-	requestHash common.Hash,
-) (*RRC7755Proof, error) {
+	messageID common.Hash,
+	fulfillmentBlock *big.Int,
+) (proofs.Proof, error) {
+	shared, err := p.GenerateSharedProof(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	inboxStorageProof, l2Header, err := p.generateInboxProof(ctx, shared, p.inbox, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if fulfillmentBlock != nil && l2Header.Number.Cmp(fulfillmentBlock) < 0 {
+		return nil, fmt.Errorf("%w: proven L2 block %s is before the fulfillment block %s",
+			proofs.ErrProofNotAvailable, l2Header.Number, fulfillmentBlock)
+	}
+
+	return AssembleProof(shared, inboxStorageProof), nil
+}
+
+// AnchorBlock returns the latest L1 block, the one new proofs are generated against
+func (p *RRC7755ArbitrumProver) AnchorBlock(ctx context.Context) (uint64, error) {
+	return p.l1StateProver.LatestBlockNumber(ctx)
+}
+
+// GenerateSharedProof generates the L1 and Arbitrum state proofs against the L1 block anchorBlock, the latest one when
+// nil
+func (p *RRC7755ArbitrumProver) GenerateSharedProof(ctx context.Context, anchorBlock *big.Int) (*SharedProof, error) {
 	// Step 1: Generate L1 state proof (maps to overview.md step 1)
-	l1StateProof, l1Block, err := p.l1StateProver.GenerateL1StateProof(ctx)
+	l1StateProof, l1Block, err := p.l1StateProver.GenerateL1StateProofAt(ctx, anchorBlock)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate L1 state proof: %w", err)
 	}
@@ -122,27 +107,66 @@ func (p *RRC7755ArbitrumProver) GenerateProof(
 		return nil, fmt.Errorf("failed to generate arbitrum state proof: %w", err)
 	}
 
-	// Step 3: Generate inbox storage proof (maps to overview.md step 3)
-	inboxStorageProof, err := p.inboxStorageProver.GetStorageProofForMapKey(
+	return &SharedProof{
+		AnchorBlock:      l1Block.NumberU64(),
+		StateProofParams: *l1StateProof,
+		ArbitrumState:    *arbitrumStateProof,
+	}, nil
+}
+
+// L2Block is the Arbitrum block the assertion of the shared proof commits to, whose hash is the first bytes32 value of
+// its global state. It is the latest block for the mock assertion of devnet proofs.
+func (s *SharedProof) L2Block() rpc.BlockNumberOrHash {
+	blockHash := s.ArbitrumState.AfterState.GlobalState.Bytes32Vals[0]
+	if blockHash == (common.Hash{}) {
+		return rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	}
+	return rpc.BlockNumberOrHashWithHash(blockHash, false)
+}
+
+// GenerateInboxProof generates the proof of the fulfillment info of a request in the inbox storage (step 3 in
+// overview.md) at the L2 block of the shared proof
+func (p *RRC7755ArbitrumProver) GenerateInboxProof(
+	ctx context.Context,
+	shared *SharedProof,
+	contractAddr common.Address,
+	requestHash common.Hash,
+) (*proofs.AccountProofParams, error) {
+	inboxStorageProof, _, err := p.generateInboxProof(ctx, shared, contractAddr, requestHash)
+	return inboxStorageProof, err
+}
+
+func (p *RRC7755ArbitrumProver) generateInboxProof(
+	ctx context.Context,
+	shared *SharedProof,
+	contractAddr common.Address,
+	requestHash common.Hash,
+) (*proofs.AccountProofParams, *types.Header, error) {
+	inboxStorageProof, l2Header, err := p.inboxStorageProver.GetStorageProofForMapKey(
 		ctx,
 		contractAddr,
 		common.HexToHash(slotConstant),
 		requestHash,
+		shared.L2Block(),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate inbox storage proof: %w", err)
+		return nil, nil, fmt.Errorf("failed to generate inbox storage proof: %w", err)
 	}
+	p.logger.Debug("Generated inbox storage proof",
+		zap.String("requestHash", requestHash.Hex()),
+		zap.Stringer("l2Block", l2Header.Number))
+	return inboxStorageProof, l2Header, nil
+}
 
-	// Combine all proofs into RRC7755Proof
-	proof := &RRC7755Proof{
-		EncodedBlockArray:         arbitrumStateProof.EncodedBlockArray,
-		AfterState:                arbitrumStateProof.AfterState,
-		PrevAssertionHash:         arbitrumStateProof.PrevAssertionHash,
-		SequencerBatchAcc:         arbitrumStateProof.SequencerBatchAcc,
-		StateProofParams:          *l1StateProof,
+// AssembleProof combines the shared proof and the inbox storage proof of a request into its proof
+func AssembleProof(shared *SharedProof, inboxStorageProof *proofs.AccountProofParams) *proofs.ArbitrumProof {
+	return &proofs.ArbitrumProof{
+		EncodedBlockArray:         shared.ArbitrumState.EncodedBlockArray,
+		AfterState:                shared.ArbitrumState.AfterState,
+		PrevAssertionHash:         shared.ArbitrumState.PrevAssertionHash,
+		SequencerBatchAcc:         shared.ArbitrumState.SequencerBatchAcc,
+		StateProofParams:          shared.StateProofParams,
 		DstL2AccountProofParams:   *inboxStorageProof,
-		DstL2StateRootProofParams: arbitrumStateProof.DstL2StateRootProofParams,
+		DstL2StateRootProofParams: shared.ArbitrumState.DstL2StateRootProofParams,
 	}
-
-	return proof, nil
 }
@@ -8,8 +8,10 @@ import (
 
 	"github.com/base-org/RRC-7755-poc/internal/prover/mocks"
 	"github.com/base-org/RRC-7755-poc/internal/prover/storage_prover"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -62,7 +64,9 @@ func (s *RRC7755ArbitrumProverTestSuite) SetupTest() {
 		s.logger,
 		s.l1Client,
 		s.l2Client,
+		common.HexToAddress("0x1234"),
 		true, // isDevnet
+		retry.Policy{Attempts: 1},
 	)
 }
 
@@ -84,7 +88,6 @@ func (s *RRC7755ArbitrumProverTestSuite) TestGenerateProof_Success() {
 
 func (s *RRC7755ArbitrumProverTestSuite) TestGenerateProof_L1StateProofError() {
 	// Setup
-	contractAddr := common.HexToAddress("0x1234")
 	requestHash := common.HexToHash("0x5678")
 	expectedErr := fmt.Errorf("l1 state proof error")
 
@@ -92,7 +95,7 @@ func (s *RRC7755ArbitrumProverTestSuite) TestGenerateProof_L1StateProofError() {
 	s.l1Client.EXPECT().BlockByNumber(s.ctx, (*big.Int)(nil)).Return(nil, expectedErr)
 
 	// Execute
-	result, err := s.prover.GenerateProof(s.ctx, contractAddr, requestHash)
+	result, err := s.prover.GenerateProof(s.ctx, requestHash, nil)
 
 	// Verify
 	require.Error(s.T(), err)
@@ -102,20 +105,21 @@ func (s *RRC7755ArbitrumProverTestSuite) TestGenerateProof_L1StateProofError() {
 
 func (s *RRC7755ArbitrumProverTestSuite) TestGenerateProof_StorageProofError() {
 	// Setup
-	contractAddr := common.HexToAddress("0x1234")
 	requestHash := common.HexToHash("0x5678")
 	expectedErr := fmt.Errorf("storage proof error")
 
 	// Setup mock expectations
 	s.l1Client.EXPECT().BlockByNumber(s.ctx, (*big.Int)(nil)).Return(s.mockBlock, nil)
-	s.ethRPCClient.EXPECT().Call(
+	s.ethRPCClient.EXPECT().CallContext(
 		gomock.Any(),
-		"eth_getProof",
 		gomock.Any(),
+		"eth_getBlockByNumber",
+		rpc.LatestBlockNumber,
+		false,
 	).Return(expectedErr)
 
 	// Execute
-	result, err := s.prover.GenerateProof(s.ctx, contractAddr, requestHash)
+	result, err := s.prover.GenerateProof(s.ctx, requestHash, nil)
 
 	// Verify
 	require.Error(s.T(), err)
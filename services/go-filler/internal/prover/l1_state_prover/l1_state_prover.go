@@ -2,15 +2,20 @@ package l1_state_prover
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"go.uber.org/zap"
 )
 
-// L1Client defines the interface for interacting with L1
+// L1Client defines the interface for interacting with L1, whose calls are bound to the context they are made with
 type L1Client interface {
 	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
 }
@@ -20,52 +25,64 @@ type L1StateProver struct {
 	logger   *zap.Logger
 	l1Client L1Client
 	isDevnet bool
+	policy   retry.Policy
 }
 
-// NewL1StateProver creates a new L1StateProver instance
-func NewL1StateProver(logger *zap.Logger, l1Client L1Client, isDevnet bool) *L1StateProver {
+// NewL1StateProver creates a new L1StateProver instance whose L1 calls are retried with the policy
+func NewL1StateProver(logger *zap.Logger, l1Client L1Client, isDevnet bool, policy retry.Policy) *L1StateProver {
 	return &L1StateProver{
 		logger:   logger,
 		l1Client: l1Client,
 		isDevnet: isDevnet,
+		policy:   policy,
 	}
 }
 
-// ArbitrumProof represents the proof data for Arbitrum's L1 state
-type L1StateProof struct {
-	BeaconRoot         string
-	BeaconTimestamp    uint64
-	ExecutionStateRoot string
-	StateRootProof     []string
+// LatestBlockNumber returns the number of the latest L1 block, the anchor of new proofs
+func (p *L1StateProver) LatestBlockNumber(ctx context.Context) (uint64, error) {
+	l1Block, err := p.blockByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return l1Block.NumberU64(), nil
 }
 
+// GenerateL1StateProof generates the state proof of the latest L1 block
 func (p *L1StateProver) GenerateL1StateProof(
 	ctx context.Context,
-) (*L1StateProof, *types.Block, error) {
+) (*proofs.StateProofParams, *types.Block, error) {
+	return p.GenerateL1StateProofAt(ctx, nil)
+}
+
+// GenerateL1StateProofAt generates the state proof of the L1 block with the given number, the latest one when nil
+func (p *L1StateProver) GenerateL1StateProofAt(
+	ctx context.Context,
+	number *big.Int,
+) (*proofs.StateProofParams, *types.Block, error) {
 	if p.isDevnet {
-		l1Block, err := p.l1Client.BlockByNumber(ctx, nil)
+		l1Block, err := p.blockByNumber(ctx, number)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get L1 block: %w", err)
+			return nil, nil, err
 		}
 		l1BlockNumber := l1Block.NumberU64()
 		executionStateRoot := l1Block.Root()
 
 		// In devnet, we use mock state root proof
-		stateRootProof := []string{"mock_proof"}
-		beaconRoot := crypto.Keccak256Hash(executionStateRoot.Bytes()).Hex()
+		stateRootProof := []common.Hash{{}}
+		beaconRoot := crypto.Keccak256Hash(executionStateRoot.Bytes())
 		beaconTimestamp := l1Block.Time()
 
 		p.logger.Info("Generated L1 state proof for devnet",
 			zap.Uint64("blockNumber", l1BlockNumber),
 			zap.String("executionStateRoot", executionStateRoot.Hex()),
-			zap.String("beaconRoot", beaconRoot),
+			zap.String("beaconRoot", beaconRoot.Hex()),
 			zap.Uint64("timestamp", beaconTimestamp))
 
-		return &L1StateProof{
-			BeaconRoot:         beaconRoot,
-			BeaconTimestamp:    beaconTimestamp,
-			ExecutionStateRoot: executionStateRoot.Hex(),
-			StateRootProof:     stateRootProof,
+		return &proofs.StateProofParams{
+			BeaconRoot:            beaconRoot,
+			BeaconOracleTimestamp: new(big.Int).SetUint64(beaconTimestamp),
+			ExecutionStateRoot:    executionStateRoot,
+			StateRootProof:        stateRootProof,
 		}, l1Block, nil
 	} else {
 		// In production, get these from your beacon chain client
@@ -73,3 +90,20 @@ func (p *L1StateProver) GenerateL1StateProof(
 		return nil, nil, fmt.Errorf("production beacon chain proof generation not implemented")
 	}
 }
+
+// blockByNumber returns the L1 block with the given number, proofs.ErrProofNotAvailable when it was not produced yet
+func (p *L1StateProver) blockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	var l1Block *types.Block
+	err := retry.Do(ctx, p.policy, func(ctx context.Context) error {
+		var err error
+		l1Block, err = p.l1Client.BlockByNumber(ctx, number)
+		if errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("%w: %w", proofs.ErrProofNotAvailable, err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L1 block: %w", err)
+	}
+	return l1Block, nil
+}
@@ -2,10 +2,14 @@ package l1_state_prover
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
 
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/mock"
@@ -60,7 +64,7 @@ func (s *L1StateProverTestSuite) TestDevnetModeGeneratesMockProof() {
 	s.mockL1Client.On("BlockByNumber", mock.Anything, (*big.Int)(nil)).Return(s.mockBlock, nil)
 
 	// Create L1StateProver instance
-	prover := NewL1StateProver(s.logger, s.mockL1Client, true)
+	prover := NewL1StateProver(s.logger, s.mockL1Client, true, retry.Policy{Attempts: 1})
 
 	// Generate proof
 	proof, resultBlock, err := prover.GenerateL1StateProof(context.Background())
@@ -69,14 +73,14 @@ func (s *L1StateProverTestSuite) TestDevnetModeGeneratesMockProof() {
 	s.require.NoError(err)
 	s.require.NotNil(proof)
 	s.require.Equal(s.mockBlock, resultBlock)
-	s.require.Equal(s.mockBlock.Root().Hex(), proof.ExecutionStateRoot)
-	s.require.Equal(s.mockBlock.Time(), proof.BeaconTimestamp)
+	s.require.Equal(s.mockBlock.Root(), proof.ExecutionStateRoot)
+	s.require.Equal(s.mockBlock.Time(), proof.BeaconOracleTimestamp.Uint64())
 	s.require.Len(proof.StateRootProof, 1) // Mock proof should have one element
 }
 
 func (s *L1StateProverTestSuite) TestProductionModeReturnsError() {
 	// Create L1StateProver instance with production mode
-	prover := NewL1StateProver(s.logger, s.mockL1Client, false)
+	prover := NewL1StateProver(s.logger, s.mockL1Client, false, retry.Policy{Attempts: 1})
 
 	// Generate proof
 	proof, block, err := prover.GenerateL1StateProof(context.Background())
@@ -91,6 +95,26 @@ func (s *L1StateProverTestSuite) TestProductionModeReturnsError() {
 	s.mockL1Client.AssertNotCalled(s.T(), "BlockByNumber")
 }
 
+func (s *L1StateProverTestSuite) TestTransientErrorsAreRetried() {
+	s.mockL1Client.On("BlockByNumber", mock.Anything, big.NewInt(12345)).Return((*types.Block)(nil), errors.New("connection reset")).Once()
+	s.mockL1Client.On("BlockByNumber", mock.Anything, big.NewInt(12345)).Return(s.mockBlock, nil).Once()
+
+	prover := NewL1StateProver(s.logger, s.mockL1Client, true, retry.Policy{Attempts: 2})
+	proof, _, err := prover.GenerateL1StateProofAt(context.Background(), big.NewInt(12345))
+
+	s.require.NoError(err)
+	s.require.Equal(s.mockBlock.Root(), proof.ExecutionStateRoot)
+}
+
+func (s *L1StateProverTestSuite) TestFutureBlockIsNotAvailable() {
+	s.mockL1Client.On("BlockByNumber", mock.Anything, big.NewInt(12346)).Return((*types.Block)(nil), ethereum.NotFound)
+
+	prover := NewL1StateProver(s.logger, s.mockL1Client, true, retry.Policy{Attempts: 1})
+	_, _, err := prover.GenerateL1StateProofAt(context.Background(), big.NewInt(12346))
+
+	s.require.ErrorIs(err, proofs.ErrProofNotAvailable)
+}
+
 func TestL1StateProverSuite(t *testing.T) {
 	suite.Run(t, new(L1StateProverTestSuite))
 }
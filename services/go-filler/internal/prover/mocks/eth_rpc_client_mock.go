@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -33,21 +34,21 @@ func (m *MockEthRPCClient) EXPECT() *MockEthRPCClientMockRecorder {
 	return m.recorder
 }
 
-// Call mocks base method.
-func (m *MockEthRPCClient) Call(arg0 interface{}, arg1 string, arg2 ...interface{}) error {
+// CallContext mocks base method.
+func (m *MockEthRPCClient) CallContext(arg0 context.Context, arg1 interface{}, arg2 string, arg3 ...interface{}) error {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{arg0, arg1}
-	for _, a := range arg2 {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "Call", varargs...)
+	ret := m.ctrl.Call(m, "CallContext", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Call indicates an expected call of Call.
-func (mr *MockEthRPCClientMockRecorder) Call(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+// CallContext indicates an expected call of CallContext.
+func (mr *MockEthRPCClientMockRecorder) CallContext(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]interface{}{arg0, arg1}, arg2...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Call", reflect.TypeOf((*MockEthRPCClient)(nil).Call), varargs...)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallContext", reflect.TypeOf((*MockEthRPCClient)(nil).CallContext), varargs...)
 }
@@ -0,0 +1,92 @@
+package proof_orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/base-org/RRC-7755-poc/internal/prover/arbitrum_prover"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// cacheEntry holds the proofs generated against an anchor block of a destination chain
+type cacheEntry struct {
+	Shared      *arbitrum_prover.SharedProof               `json:"shared"`
+	InboxProofs map[common.Hash]*proofs.AccountProofParams `json:"inbox_proofs"`
+}
+
+// cache keeps the entry of the last anchor block of every destination chain in memory and, when dir is set, the entry
+// of every anchor block on disk in dir/<destination chain>/<anchor block>.json
+type cache struct {
+	dir     string
+	entries map[uint64]*cacheEntry
+}
+
+func newCache(dir string) (*cache, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating proof cache: %w", err)
+		}
+	}
+	return &cache{dir: dir, entries: make(map[uint64]*cacheEntry)}, nil
+}
+
+// load returns the entry of the anchor block, without a shared proof when nothing was generated against it yet
+func (c *cache) load(destination uint64, anchorBlock uint64) (*cacheEntry, error) {
+	if entry, ok := c.entries[destination]; ok && entry.Shared != nil && entry.Shared.AnchorBlock == anchorBlock {
+		return entry, nil
+	}
+
+	entry := &cacheEntry{InboxProofs: make(map[common.Hash]*proofs.AccountProofParams)}
+	if c.dir != "" {
+		content, err := os.ReadFile(c.path(destination, anchorBlock))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("reading proof cache: %w", err)
+		default:
+			if err := json.Unmarshal(content, entry); err != nil {
+				return nil, fmt.Errorf("decoding proof cache: %w", err)
+			}
+			if entry.InboxProofs == nil {
+				entry.InboxProofs = make(map[common.Hash]*proofs.AccountProofParams)
+			}
+		}
+	}
+
+	c.entries[destination] = entry
+	return entry, nil
+}
+
+// save writes the entry to disk, through a temporary file so that a crash never leaves a truncated entry
+func (c *cache) save(destination uint64, entry *cacheEntry) error {
+	if c.dir == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding proof cache: %w", err)
+	}
+
+	path := c.path(destination, entry.Shared.AnchorBlock)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating proof cache: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("writing proof cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing proof cache: %w", err)
+	}
+	return nil
+}
+
+func (c *cache) path(destination uint64, anchorBlock uint64) string {
+	return filepath.Join(c.dir, strconv.FormatUint(destination, 10), strconv.FormatUint(anchorBlock, 10)+".json")
+}
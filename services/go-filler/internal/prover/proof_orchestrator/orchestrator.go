@@ -0,0 +1,168 @@
+package proof_orchestrator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/base-org/RRC-7755-poc/internal/prover/arbitrum_prover"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// Prover generates the proofs of a destination chain in two parts: the part shared by every request proven against
+// the same L1 anchor block and the inbox storage proof of each request
+type Prover interface {
+	AnchorBlock(ctx context.Context) (uint64, error)
+	GenerateSharedProof(ctx context.Context, anchorBlock *big.Int) (*arbitrum_prover.SharedProof, error)
+	GenerateInboxProof(
+		ctx context.Context,
+		shared *arbitrum_prover.SharedProof,
+		contractAddr common.Address,
+		requestHash common.Hash,
+	) (*proofs.AccountProofParams, error)
+}
+
+// Destination is the prover and inbox of a destination chain
+type Destination struct {
+	Prover Prover
+	Inbox  common.Address
+}
+
+// Request is a fulfilled request to prove
+type Request struct {
+	MessageID        common.Hash
+	DestinationChain uint64
+}
+
+// Result is the proof of a request, or the error that kept it from being generated
+type Result struct {
+	Request
+	AnchorBlock uint64
+	Proof       *proofs.ArbitrumProof
+	Err         error
+}
+
+// Orchestrator generates the proofs of batches of requests. Requests are grouped by destination chain and anchor
+// block, the shared part of the proof is generated once per group and only the inbox storage proof for each request.
+// Generated proofs are cached by anchor block.
+type Orchestrator struct {
+	logger       *zap.Logger
+	destinations map[uint64]Destination
+
+	mu    sync.Mutex
+	cache *cache
+}
+
+// NewOrchestrator creates an Orchestrator caching proofs in cacheDir, in memory only when it is empty
+func NewOrchestrator(logger *zap.Logger, destinations map[uint64]Destination, cacheDir string) (*Orchestrator, error) {
+	cache, err := newCache(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Orchestrator{logger: logger, destinations: destinations, cache: cache}, nil
+}
+
+// Prove returns a result for every request, in the order of the requests
+func (o *Orchestrator) Prove(ctx context.Context, requests []Request) []Result {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	results := make([]Result, len(requests))
+	groups := make(map[uint64][]int)
+	for i, req := range requests {
+		results[i].Request = req
+		groups[req.DestinationChain] = append(groups[req.DestinationChain], i)
+	}
+
+	destinations := make([]uint64, 0, len(groups))
+	for destination := range groups {
+		destinations = append(destinations, destination)
+	}
+	sort.Slice(destinations, func(i, j int) bool { return destinations[i] < destinations[j] })
+
+	for _, destination := range destinations {
+		o.proveGroup(ctx, destination, groups[destination], results)
+	}
+
+	return results
+}
+
+// proveGroup proves the requests at indexes of a destination chain against its current anchor block
+func (o *Orchestrator) proveGroup(ctx context.Context, destination uint64, indexes []int, results []Result) {
+	fail := func(err error) {
+		for _, i := range indexes {
+			results[i].Err = err
+		}
+	}
+
+	dst, ok := o.destinations[destination]
+	if !ok {
+		fail(fmt.Errorf("no prover for destination chain %d", destination))
+		return
+	}
+
+	anchorBlock, err := dst.Prover.AnchorBlock(ctx)
+	if err != nil {
+		fail(fmt.Errorf("getting anchor block: %w", err))
+		return
+	}
+
+	entry, err := o.cache.load(destination, anchorBlock)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	if entry.Shared == nil {
+		shared, err := dst.Prover.GenerateSharedProof(ctx, new(big.Int).SetUint64(anchorBlock))
+		if err != nil {
+			fail(fmt.Errorf("generating shared proof: %w", err))
+			return
+		}
+		entry.Shared = shared
+		o.logger.Info("Generated shared proof",
+			zap.Uint64("destination_chain", destination),
+			zap.Uint64("anchor_block", anchorBlock),
+		)
+	} else {
+		o.logger.Info("Reusing shared proof",
+			zap.Uint64("destination_chain", destination),
+			zap.Uint64("anchor_block", anchorBlock),
+		)
+	}
+
+	generated := 0
+	for _, i := range indexes {
+		messageID := results[i].MessageID
+		results[i].AnchorBlock = anchorBlock
+
+		inboxProof, ok := entry.InboxProofs[messageID]
+		if !ok {
+			inboxProof, err = dst.Prover.GenerateInboxProof(ctx, entry.Shared, dst.Inbox, messageID)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			entry.InboxProofs[messageID] = inboxProof
+			generated++
+		}
+
+		results[i].Proof = arbitrum_prover.AssembleProof(entry.Shared, inboxProof)
+	}
+
+	o.logger.Info("Proved requests",
+		zap.Uint64("destination_chain", destination),
+		zap.Uint64("anchor_block", anchorBlock),
+		zap.Int("requests", len(indexes)),
+		zap.Int("generated_inbox_proofs", generated),
+	)
+
+	if err := o.cache.save(destination, entry); err != nil {
+		o.logger.Error("Saving proof cache", zap.Error(err))
+	}
+}
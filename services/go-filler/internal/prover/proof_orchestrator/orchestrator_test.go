@@ -0,0 +1,136 @@
+package proof_orchestrator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/base-org/RRC-7755-poc/internal/prover/arbitrum_prover"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeProver counts the proofs it generates
+type fakeProver struct {
+	anchorBlock  uint64
+	sharedCalls  int
+	inboxCalls   int
+	failMessages map[common.Hash]bool
+}
+
+func (p *fakeProver) AnchorBlock(ctx context.Context) (uint64, error) {
+	return p.anchorBlock, nil
+}
+
+func (p *fakeProver) GenerateSharedProof(ctx context.Context, anchorBlock *big.Int) (*arbitrum_prover.SharedProof, error) {
+	p.sharedCalls++
+	return &arbitrum_prover.SharedProof{
+		AnchorBlock:      anchorBlock.Uint64(),
+		StateProofParams: proofs.StateProofParams{BeaconOracleTimestamp: anchorBlock},
+		ArbitrumState:    arbitrum_prover.ArbitrumStateProofResult{EncodedBlockArray: []byte{0x01}},
+	}, nil
+}
+
+func (p *fakeProver) GenerateInboxProof(
+	ctx context.Context,
+	shared *arbitrum_prover.SharedProof,
+	contractAddr common.Address,
+	requestHash common.Hash,
+) (*proofs.AccountProofParams, error) {
+	p.inboxCalls++
+	if p.failMessages[requestHash] {
+		return nil, errors.New("not fulfilled")
+	}
+	return &proofs.AccountProofParams{StorageKey: requestHash.Bytes(), StorageValue: contractAddr.Bytes()}, nil
+}
+
+var inbox = common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+func TestProveSharesProofPerAnchorBlock(t *testing.T) {
+	arbitrum := &fakeProver{anchorBlock: 100}
+	other := &fakeProver{anchorBlock: 200}
+	o, err := NewOrchestrator(zaptest.NewLogger(t), map[uint64]Destination{
+		1: {Prover: arbitrum, Inbox: inbox},
+		2: {Prover: other, Inbox: inbox},
+	}, "")
+	require.NoError(t, err)
+
+	requests := []Request{
+		{MessageID: common.HexToHash("0x01"), DestinationChain: 1},
+		{MessageID: common.HexToHash("0x02"), DestinationChain: 2},
+		{MessageID: common.HexToHash("0x03"), DestinationChain: 1},
+	}
+	results := o.Prove(context.Background(), requests)
+
+	require.Len(t, results, 3)
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		require.Equal(t, requests[i], result.Request)
+		require.Equal(t, requests[i].MessageID.Bytes(), result.Proof.DstL2AccountProofParams.StorageKey)
+	}
+	require.Equal(t, uint64(100), results[0].AnchorBlock)
+	require.Equal(t, uint64(200), results[1].AnchorBlock)
+	require.Equal(t, big.NewInt(100), results[2].Proof.StateProofParams.BeaconOracleTimestamp)
+	require.Equal(t, 1, arbitrum.sharedCalls)
+	require.Equal(t, 2, arbitrum.inboxCalls)
+	require.Equal(t, 1, other.sharedCalls)
+
+	// Same anchor block: nothing is generated again
+	results = o.Prove(context.Background(), requests[:1])
+	require.NoError(t, results[0].Err)
+	require.Equal(t, 1, arbitrum.sharedCalls)
+	require.Equal(t, 2, arbitrum.inboxCalls)
+
+	// New anchor block: the shared proof is generated again
+	arbitrum.anchorBlock = 101
+	results = o.Prove(context.Background(), requests[:1])
+	require.NoError(t, results[0].Err)
+	require.Equal(t, uint64(101), results[0].AnchorBlock)
+	require.Equal(t, 2, arbitrum.sharedCalls)
+	require.Equal(t, 3, arbitrum.inboxCalls)
+}
+
+func TestProveReusesDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	requests := []Request{
+		{MessageID: common.HexToHash("0x01"), DestinationChain: 1},
+		{MessageID: common.HexToHash("0x02"), DestinationChain: 1},
+	}
+
+	first := &fakeProver{anchorBlock: 100}
+	o, err := NewOrchestrator(zaptest.NewLogger(t), map[uint64]Destination{1: {Prover: first, Inbox: inbox}}, dir)
+	require.NoError(t, err)
+	want := o.Prove(context.Background(), requests)
+	require.NoError(t, want[0].Err)
+	require.NoError(t, want[1].Err)
+
+	second := &fakeProver{anchorBlock: 100}
+	o, err = NewOrchestrator(zaptest.NewLogger(t), map[uint64]Destination{1: {Prover: second, Inbox: inbox}}, dir)
+	require.NoError(t, err)
+	got := o.Prove(context.Background(), requests)
+
+	require.Equal(t, want, got)
+	require.Zero(t, second.sharedCalls)
+	require.Zero(t, second.inboxCalls)
+}
+
+func TestProveErrors(t *testing.T) {
+	prover := &fakeProver{anchorBlock: 100, failMessages: map[common.Hash]bool{common.HexToHash("0x02"): true}}
+	o, err := NewOrchestrator(zaptest.NewLogger(t), map[uint64]Destination{1: {Prover: prover, Inbox: inbox}}, "")
+	require.NoError(t, err)
+
+	results := o.Prove(context.Background(), []Request{
+		{MessageID: common.HexToHash("0x01"), DestinationChain: 1},
+		{MessageID: common.HexToHash("0x02"), DestinationChain: 1},
+		{MessageID: common.HexToHash("0x03"), DestinationChain: 3},
+	})
+
+	require.NoError(t, results[0].Err)
+	require.NotNil(t, results[0].Proof)
+	require.EqualError(t, results[1].Err, "not fulfilled")
+	require.Nil(t, results[1].Proof)
+	require.EqualError(t, results[2].Err, "no prover for destination chain 3")
+}
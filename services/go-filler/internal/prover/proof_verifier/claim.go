@@ -0,0 +1,172 @@
+package proof_verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// inboxStorageLocation is the storage location of the fulfillment info mapping in RRC7755Inbox
+var inboxStorageLocation = common.HexToHash("0x40f2eef6aad3cb0e74d3b59b45d3d5f2d5fc8dc382e739617b693cdd4bc30c00")
+
+// Selectors of the attributes the outboxes read when validating a proof
+var (
+	l2OracleAttributeSelector           = []byte{0x7f, 0xf7, 0x24, 0x5a}
+	l2OracleStorageKeyAttributeSelector = []byte{0x0f, 0x78, 0x63, 0x69}
+	shoyuBashiAttributeSelector         = []byte{0xda, 0x07, 0xe1, 0x5d}
+	delayAttributeSelector              = []byte{0x84, 0xf5, 0x50, 0xe0}
+	inboxAttributeSelector              = []byte{0xbd, 0x36, 0x23, 0x74}
+)
+
+// FulfillmentInfo is the fulfillment of a request stored by the inbox (RRC7755Inbox.FulfillmentInfo)
+type FulfillmentInfo struct {
+	Fulfiller common.Address
+	Timestamp *big.Int
+}
+
+// Claim is a reward claim of a fulfilled request
+type Claim struct {
+	// ProverType is the prover of the outbox the request was posted to, one of config.ProverTypes
+	ProverType       string
+	MessageID        common.Hash
+	DestinationChain *big.Int
+	// Inbox is the receiver of the request, or the inbox attribute of the UserOp for UserOp requests
+	Inbox common.Address
+	// Attributes are the attributes of the request, or of the UserOp for UserOp requests
+	Attributes [][]byte
+	Caller     common.Address
+	Proof      []byte
+}
+
+// VerifyClaim checks the proof of a claim like the outbox does in claimReward and returns the proven fulfillment
+func (v *Verifier) VerifyClaim(ctx context.Context, claim Claim) (*FulfillmentInfo, error) {
+	storageKey := InboxStorageKey(claim.MessageID)
+
+	var (
+		storageValue []byte
+		l2Timestamp  *big.Int
+	)
+	switch claim.ProverType {
+	case config.ProverArbitrum:
+		l2Oracle, err := locateAttribute(claim.Attributes, l2OracleAttributeSelector)
+		if err != nil {
+			return nil, err
+		}
+		var proof proofs.ArbitrumProof
+		if err := proof.Decode(claim.Proof); err != nil {
+			return nil, err
+		}
+		storageValue, err = v.VerifyArbitrum(ctx, &proof, ArbitrumTarget{
+			L1Address:    common.BytesToAddress(l2Oracle[:]),
+			L2Address:    claim.Inbox,
+			L2StorageKey: storageKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	case config.ProverOPStack:
+		l2Oracle, err := locateAttribute(claim.Attributes, l2OracleAttributeSelector)
+		if err != nil {
+			return nil, err
+		}
+		l2OracleStorageKey, err := locateAttribute(claim.Attributes, l2OracleStorageKeyAttributeSelector)
+		if err != nil {
+			return nil, err
+		}
+		var proof proofs.OPStackProof
+		if err := proof.Decode(claim.Proof); err != nil {
+			return nil, err
+		}
+		storageValue, err = v.VerifyOPStack(ctx, &proof, OPStackTarget{
+			L1Address:    common.BytesToAddress(l2Oracle[:]),
+			L1StorageKey: l2OracleStorageKey[:],
+			L2Address:    claim.Inbox,
+			L2StorageKey: storageKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	case config.ProverHashi:
+		shoyuBashi, err := locateAttribute(claim.Attributes, shoyuBashiAttributeSelector)
+		if err != nil {
+			return nil, err
+		}
+		var proof proofs.HashiProof
+		if err := proof.Decode(claim.Proof); err != nil {
+			return nil, err
+		}
+		l2Timestamp, storageValue, err = v.VerifyHashi(ctx, &proof, HashiTarget{
+			Addr:               claim.Inbox,
+			StorageKey:         storageKey,
+			DestinationChainID: claim.DestinationChain,
+			ShoyuBashi:         common.BytesToAddress(shoyuBashi[:]),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedProverType, claim.ProverType)
+	}
+
+	info := DecodeFulfillmentInfo(storageValue)
+	if info.Fulfiller != claim.Caller {
+		return nil, fmt.Errorf("%w: fulfilled by %s, claimed by %s", ErrInvalidCaller, info.Fulfiller, claim.Caller)
+	}
+
+	if l2Timestamp != nil {
+		delay, err := locateAttribute(claim.Attributes, delayAttributeSelector)
+		if err != nil {
+			return nil, err
+		}
+		claimable := new(big.Int).Add(info.Timestamp, new(big.Int).SetBytes(delay[:]))
+		if claimable.Cmp(l2Timestamp) > 0 {
+			return nil, fmt.Errorf("%w: claimable after %s, proven block at %s", ErrFinalityDelayInProgress, claimable, l2Timestamp)
+		}
+	}
+
+	return info, nil
+}
+
+// UserOpInbox returns the inbox a UserOp request is fulfilled through, from the inbox attribute of its paymaster data
+// like the outbox _getRewardAndInbox, the receiver of the request being the EntryPoint
+func UserOpInbox(attributes [][]byte) (common.Address, error) {
+	inbox, err := locateAttribute(attributes, inboxAttributeSelector)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(inbox[:]), nil
+}
+
+// InboxStorageKey is the inbox storage slot of the fulfillment info of a request
+func InboxStorageKey(messageID common.Hash) []byte {
+	return crypto.Keccak256(messageID.Bytes(), inboxStorageLocation.Bytes())
+}
+
+// DecodeFulfillmentInfo decodes the inbox storage value of a fulfillment like the outbox _decodeFulfillmentInfo:
+// the fulfiller is in the upper 160 bits and the timestamp in the lower 96 bits
+func DecodeFulfillmentInfo(storageValue []byte) *FulfillmentInfo {
+	word := bytes32(storageValue)
+	return &FulfillmentInfo{
+		Fulfiller: common.BytesToAddress(word[:common.AddressLength]),
+		Timestamp: new(big.Int).SetBytes(word[common.AddressLength:]),
+	}
+}
+
+// locateAttribute returns the first word of the attribute with the selector
+func locateAttribute(attributes [][]byte, selector []byte) (common.Hash, error) {
+	for _, attribute := range attributes {
+		if len(attribute) >= len(selector)+common.HashLength && bytes.HasPrefix(attribute, selector) {
+			return common.BytesToHash(attribute[len(selector) : len(selector)+common.HashLength]), nil
+		}
+	}
+	return common.Hash{}, fmt.Errorf("%w %#x", ErrMissingRequiredAttribute, selector)
+}
@@ -0,0 +1,147 @@
+package proof_verifier
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// stateRootGIndex is the generalized index of the execution state root in the beacon block tree
+	stateRootGIndex = 6434
+	// minBlockFields is the number of fields a block header has at least
+	minBlockFields = 12
+)
+
+// VerifyStateRoot checks the SSZ branch proving the execution state root against the beacon root, like
+// SSZ.verifyProof
+func VerifyStateRoot(params *proofs.StateProofParams) error {
+	leaf := params.ExecutionStateRoot
+	index := uint64(stateRootGIndex)
+	for _, sibling := range params.StateRootProof {
+		isRight := index&1 == 1
+		index >>= 1
+		if index == 0 {
+			return fmt.Errorf("%w: branch has extra item", ErrInvalidStateRootProof)
+		}
+
+		h := sha256.New()
+		if isRight {
+			h.Write(sibling[:])
+			h.Write(leaf[:])
+		} else {
+			h.Write(leaf[:])
+			h.Write(sibling[:])
+		}
+		leaf = common.BytesToHash(h.Sum(nil))
+	}
+	if index != 1 {
+		return fmt.Errorf("%w: branch has missing item", ErrInvalidStateRootProof)
+	}
+	if leaf != params.BeaconRoot {
+		return ErrInvalidStateRootProof
+	}
+	return nil
+}
+
+// VerifyAccountStorage checks the account proof against the state root and the storage proof against the storage
+// root of the account, like StateValidator.validateAccountStorage
+func VerifyAccountStorage(account common.Address, stateRoot common.Hash, params *proofs.AccountProofParams) error {
+	storageRoot, err := AccountStorageRoot(account, stateRoot, params.AccountProof)
+	if err != nil {
+		return err
+	}
+
+	rlpValue, err := verifyTrieProof(storageRoot, params.StorageKey, params.StorageProof)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidStorageProof, err)
+	}
+	var value []byte
+	if err := rlp.DecodeBytes(rlpValue, &value); err != nil {
+		return fmt.Errorf("%w: decoding value: %w", ErrInvalidStorageProof, err)
+	}
+
+	if !bytes.Equal(value, params.StorageValue) {
+		return fmt.Errorf("%w: proven %#x, expected %#x", ErrStorageValueMismatch, value, params.StorageValue)
+	}
+	return nil
+}
+
+// AccountStorageRoot returns the storage root of the account proven by the account proof against the state root
+func AccountStorageRoot(account common.Address, stateRoot common.Hash, accountProof [][]byte) (common.Hash, error) {
+	encodedAccount, err := verifyTrieProof(stateRoot, account.Bytes(), accountProof)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: %w", ErrInvalidAccountProof, err)
+	}
+	return extractStorageRoot(encodedAccount)
+}
+
+// verifyTrieProof returns the value of the key in the secure trie with the root, like SecureMerkleTrie.get
+func verifyTrieProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	db := memorydb.New()
+	for _, node := range proof {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := trie.VerifyProof(root, crypto.Keccak256(key), db)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+// extractStorageRoot returns the storage root of an RLP encoded account
+func extractStorageRoot(encodedAccount []byte) (common.Hash, error) {
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(encodedAccount, &fields); err != nil || len(fields) != 4 {
+		return common.Hash{}, ErrInvalidAccountRLP
+	}
+
+	var storageRoot []byte
+	if err := rlp.DecodeBytes(fields[2], &storageRoot); err != nil {
+		return common.Hash{}, ErrInvalidAccountRLP
+	}
+	return bytes32(storageRoot), nil
+}
+
+// blockHeaderFields returns the state root, number and timestamp of an RLP encoded block header, like
+// BlockHeaders.extractStateRootBlockNumberAndTimestamp
+func blockHeaderFields(header []byte) (common.Hash, *big.Int, *big.Int, error) {
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(header, &fields); err != nil || len(fields) < minBlockFields {
+		return common.Hash{}, nil, nil, ErrInvalidBlockHeaders
+	}
+
+	var stateRoot, number, timestamp []byte
+	for _, field := range []struct {
+		raw rlp.RawValue
+		out *[]byte
+	}{{fields[3], &stateRoot}, {fields[8], &number}, {fields[11], &timestamp}} {
+		if err := rlp.DecodeBytes(field.raw, field.out); err != nil || len(*field.out) > 32 {
+			return common.Hash{}, nil, nil, ErrInvalidBlockHeaders
+		}
+	}
+
+	return bytes32(stateRoot), new(big.Int).SetBytes(number), new(big.Int).SetBytes(timestamp), nil
+}
+
+// bytes32 converts b like Solidity's bytes32(b): b is left aligned and truncated to 32 bytes
+func bytes32(b []byte) common.Hash {
+	var h common.Hash
+	copy(h[:], b)
+	return h
+}
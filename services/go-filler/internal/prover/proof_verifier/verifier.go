@@ -0,0 +1,247 @@
+package proof_verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Errors matching the reverts of the prover contracts
+var (
+	ErrBeaconRootMismatch       = errors.New("beacon root does not match the beacon roots oracle")
+	ErrInvalidStateRootProof    = errors.New("invalid execution state root proof")
+	ErrInvalidAccountRLP        = errors.New("invalid account RLP")
+	ErrInvalidAccountProof      = errors.New("invalid account proof")
+	ErrInvalidStorageProof      = errors.New("invalid storage proof")
+	ErrStorageValueMismatch     = errors.New("storage value does not match the proof")
+	ErrNodeNotConfirmed         = errors.New("assertion node not confirmed")
+	ErrInvalidBlockHeaders      = errors.New("invalid block headers")
+	ErrInvalidL2StateRoot       = errors.New("invalid L2 state root")
+	ErrInvalidBlockHeader       = errors.New("block header does not match the Hashi block hash")
+	ErrInvalidCaller            = errors.New("fulfiller is not the caller")
+	ErrFinalityDelayInProgress  = errors.New("finality delay in progress")
+	ErrUnsupportedProverType    = errors.New("unsupported prover type")
+	ErrMissingRequiredAttribute = errors.New("missing required attribute")
+)
+
+// BeaconRootsOracle is the EIP-4788 contract that beacon roots are read from on the source chain
+var BeaconRootsOracle = common.HexToAddress("0x000F3df6D732807Ef1319fB7B8bB8522d0Beac02")
+
+const (
+	// arbitrumAssertionsSlot is the slot of the assertions mapping in the Arbitrum rollup contract
+	arbitrumAssertionsSlot = 0x75
+	// arbitrumConfirmed is the AssertionStatus of a confirmed assertion node
+	arbitrumConfirmed = 2
+)
+
+// getThresholdHashSelector is the selector of ShoyuBashi.getThresholdHash(uint256,uint256)
+var getThresholdHashSelector = crypto.Keccak256([]byte("getThresholdHash(uint256,uint256)"))[:4]
+
+// ArbitrumTarget is what an Arbitrum proof is checked against (ArbitrumProver.Target)
+type ArbitrumTarget struct {
+	// L1Address is the Arbitrum rollup contract
+	L1Address    common.Address
+	L2Address    common.Address
+	L2StorageKey []byte
+}
+
+// OPStackTarget is what an OP Stack proof is checked against (OPStackProver.Target)
+type OPStackTarget struct {
+	// L1Address is the contract storing the output roots of the destination chain
+	L1Address    common.Address
+	L1StorageKey []byte
+	L2Address    common.Address
+	L2StorageKey []byte
+}
+
+// HashiTarget is what a Hashi proof is checked against (HashiProver.Target)
+type HashiTarget struct {
+	Addr               common.Address
+	StorageKey         []byte
+	DestinationChainID *big.Int
+	ShoyuBashi         common.Address
+}
+
+// Verifier checks proofs like the prover contracts do on the source chain, so that proofs they would reject are never
+// submitted. Beacon roots and Hashi block hashes are read from the source chain.
+type Verifier struct {
+	source ethereum.ContractCaller
+}
+
+func NewVerifier(source ethereum.ContractCaller) *Verifier {
+	return &Verifier{source: source}
+}
+
+// VerifyArbitrum checks an Arbitrum proof like ArbitrumProver.validate and returns the proven inbox storage value
+func (v *Verifier) VerifyArbitrum(ctx context.Context, proof *proofs.ArbitrumProof, target ArbitrumTarget) ([]byte, error) {
+	accountProof := proof.DstL2AccountProofParams
+	accountProof.StorageKey = target.L2StorageKey
+
+	stateRootProof := proof.DstL2StateRootProofParams
+	stateRootProof.StorageKey = arbitrumAssertionStorageKey(proof)
+
+	if err := v.verifyState(ctx, target.L1Address, &proof.StateProofParams, &stateRootProof); err != nil {
+		return nil, fmt.Errorf("verifying L1 state: %w", err)
+	}
+
+	if len(stateRootProof.StorageValue) > 32 {
+		return nil, fmt.Errorf("%w: storage value longer than 32 bytes", ErrNodeNotConfirmed)
+	}
+	// The status is in bits 200-255 of the assertion storage value
+	status := new(big.Int).Rsh(new(big.Int).SetBytes(stateRootProof.StorageValue), 200)
+	if status.Cmp(big.NewInt(arbitrumConfirmed)) != 0 {
+		return nil, ErrNodeNotConfirmed
+	}
+
+	if proof.AfterState.GlobalState.Bytes32Vals[0] != crypto.Keccak256Hash(proof.EncodedBlockArray) {
+		return nil, ErrInvalidBlockHeaders
+	}
+	l2StateRoot, _, _, err := blockHeaderFields(proof.EncodedBlockArray)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyAccountStorage(target.L2Address, l2StateRoot, &accountProof); err != nil {
+		return nil, fmt.Errorf("verifying L2 storage: %w", err)
+	}
+	return accountProof.StorageValue, nil
+}
+
+// VerifyOPStack checks an OP Stack proof like OPStackProver.validate and returns the proven inbox storage value
+func (v *Verifier) VerifyOPStack(ctx context.Context, proof *proofs.OPStackProof, target OPStackTarget) ([]byte, error) {
+	accountProof := proof.DstL2AccountProofParams
+	accountProof.StorageKey = target.L2StorageKey
+
+	stateRootProof := proof.DstL2StateRootProofParams
+	stateRootProof.StorageKey = target.L1StorageKey
+
+	if err := v.verifyState(ctx, target.L1Address, &proof.StateProofParams, &stateRootProof); err != nil {
+		return nil, fmt.Errorf("verifying L1 state: %w", err)
+	}
+
+	l2StateRoot, _, _, err := blockHeaderFields(proof.EncodedBlockArray)
+	if err != nil {
+		return nil, err
+	}
+	l2BlockHash := crypto.Keccak256Hash(proof.EncodedBlockArray)
+
+	// Output root v0: keccak256(version, state root, message passer storage root, block hash)
+	outputRoot := crypto.Keccak256Hash(
+		common.Hash{}.Bytes(),
+		l2StateRoot.Bytes(),
+		proof.L2MessagePasserStorageRoot.Bytes(),
+		l2BlockHash.Bytes(),
+	)
+	if bytes32(stateRootProof.StorageValue) != outputRoot {
+		return nil, ErrInvalidL2StateRoot
+	}
+
+	if err := VerifyAccountStorage(target.L2Address, l2StateRoot, &accountProof); err != nil {
+		return nil, fmt.Errorf("verifying L2 storage: %w", err)
+	}
+	return accountProof.StorageValue, nil
+}
+
+// VerifyHashi checks a Hashi proof like HashiProver.validate and returns the timestamp of the proven block and the
+// proven inbox storage value
+func (v *Verifier) VerifyHashi(ctx context.Context, proof *proofs.HashiProof, target HashiTarget) (*big.Int, []byte, error) {
+	accountProof := proof.DstAccountProofParams
+	accountProof.StorageKey = target.StorageKey
+
+	stateRoot, number, timestamp, err := blockHeaderFields(proof.RlpEncodedBlockHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blockHash, err := v.thresholdHash(ctx, target.ShoyuBashi, target.DestinationChainID, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blockHash != crypto.Keccak256Hash(proof.RlpEncodedBlockHeader) {
+		return nil, nil, ErrInvalidBlockHeader
+	}
+
+	if err := VerifyAccountStorage(target.Addr, stateRoot, &accountProof); err != nil {
+		return nil, nil, fmt.Errorf("verifying storage: %w", err)
+	}
+	return timestamp, accountProof.StorageValue, nil
+}
+
+// verifyState checks the beacon root, the execution state root and the account storage, like
+// StateValidator.validateState
+func (v *Verifier) verifyState(
+	ctx context.Context,
+	account common.Address,
+	stateProof *proofs.StateProofParams,
+	accountProof *proofs.AccountProofParams,
+) error {
+	if err := v.checkBeaconRoot(ctx, stateProof); err != nil {
+		return err
+	}
+	if err := VerifyStateRoot(stateProof); err != nil {
+		return err
+	}
+	return VerifyAccountStorage(account, stateProof.ExecutionStateRoot, accountProof)
+}
+
+func (v *Verifier) checkBeaconRoot(ctx context.Context, stateProof *proofs.StateProofParams) error {
+	if stateProof.BeaconOracleTimestamp == nil {
+		return fmt.Errorf("%w: missing beacon oracle timestamp", ErrBeaconRootMismatch)
+	}
+
+	result, err := v.source.CallContract(ctx, ethereum.CallMsg{
+		To:   &BeaconRootsOracle,
+		Data: common.BigToHash(stateProof.BeaconOracleTimestamp).Bytes(),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("calling beacon roots oracle: %w", err)
+	}
+	if len(result) != common.HashLength {
+		return fmt.Errorf("calling beacon roots oracle: unexpected result %#x", result)
+	}
+
+	if root := common.BytesToHash(result); root != stateProof.BeaconRoot {
+		return fmt.Errorf("%w: expected %s, oracle has %s", ErrBeaconRootMismatch, stateProof.BeaconRoot, root)
+	}
+	return nil
+}
+
+func (v *Verifier) thresholdHash(ctx context.Context, shoyuBashi common.Address, domain, id *big.Int) (common.Hash, error) {
+	data := append(append(append([]byte{}, getThresholdHashSelector...),
+		common.BigToHash(domain).Bytes()...),
+		common.BigToHash(id).Bytes()...)
+
+	result, err := v.source.CallContract(ctx, ethereum.CallMsg{To: &shoyuBashi, Data: data}, nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("calling ShoyuBashi: %w", err)
+	}
+	if len(result) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("calling ShoyuBashi: unexpected result %#x", result)
+	}
+	return common.BytesToHash(result), nil
+}
+
+// arbitrumAssertionStorageKey is the slot of the assertion node the proof is for in the rollup contract
+func arbitrumAssertionStorageKey(proof *proofs.ArbitrumProof) []byte {
+	afterState := proof.AfterState
+	afterStateHash := crypto.Keccak256Hash(
+		afterState.GlobalState.Bytes32Vals[0].Bytes(),
+		afterState.GlobalState.Bytes32Vals[1].Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(afterState.GlobalState.U64Vals[0])).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(afterState.GlobalState.U64Vals[1])).Bytes(),
+		common.BigToHash(big.NewInt(int64(afterState.MachineStatus))).Bytes(),
+		afterState.EndHistoryRoot.Bytes(),
+	)
+	assertionHash := crypto.Keccak256Hash(
+		proof.PrevAssertionHash.Bytes(),
+		afterStateHash.Bytes(),
+		proof.SequencerBatchAcc.Bytes(),
+	)
+	return crypto.Keccak256(assertionHash.Bytes(), common.BigToHash(big.NewInt(arbitrumAssertionsSlot)).Bytes())
+}
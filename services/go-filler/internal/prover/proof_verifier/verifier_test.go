@@ -0,0 +1,257 @@
+package proof_verifier
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures are the Sepolia proofs of the prover contract tests, with the targets those tests use
+
+var (
+	arbitrumRollup = common.HexToAddress("0x042B2E6C5E99d4c521bd49beeD5E99651D9B0Cf4")
+	arbitrumInbox  = common.HexToAddress("0xdac62f96404AB882F5a61CFCaFb0C470a19FC514")
+	opStackOracle  = common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	opStackInbox   = common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	hashiInbox     = common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	shoyuBashi     = common.HexToAddress("0x5555555555555555555555555555555555555555")
+)
+
+// fakeSourceChain answers the beacon roots oracle and ShoyuBashi calls
+type fakeSourceChain struct {
+	beaconRoots map[uint64]common.Hash
+	blockHashes map[uint64]common.Hash
+}
+
+func (c *fakeSourceChain) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	switch *call.To {
+	case BeaconRootsOracle:
+		root := c.beaconRoots[new(big.Int).SetBytes(call.Data).Uint64()]
+		return root.Bytes(), nil
+	case shoyuBashi:
+		hash := c.blockHashes[new(big.Int).SetBytes(call.Data[4+32:]).Uint64()]
+		return hash.Bytes(), nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func readProof(t *testing.T, name string, proof interface{ Decode([]byte) error }) {
+	t.Helper()
+
+	encoded, err := os.ReadFile(filepath.Join("..", "proofs", "testdata", name+".hex"))
+	require.NoError(t, err)
+	require.NoError(t, proof.Decode(common.FromHex(strings.TrimSpace(string(encoded)))))
+}
+
+func sourceChainFor(stateProof *proofs.StateProofParams) *fakeSourceChain {
+	return &fakeSourceChain{
+		beaconRoots: map[uint64]common.Hash{stateProof.BeaconOracleTimestamp.Uint64(): stateProof.BeaconRoot},
+	}
+}
+
+func TestVerifyArbitrum(t *testing.T) {
+	var valid proofs.ArbitrumProof
+	readProof(t, "ArbitrumSepoliaProof", &valid)
+	target := ArbitrumTarget{
+		L1Address:    arbitrumRollup,
+		L2Address:    arbitrumInbox,
+		L2StorageKey: valid.DstL2AccountProofParams.StorageKey,
+	}
+
+	value, err := NewVerifier(sourceChainFor(&valid.StateProofParams)).VerifyArbitrum(context.Background(), &valid, target)
+	require.NoError(t, err)
+	require.Equal(t, valid.DstL2AccountProofParams.StorageValue, value)
+
+	tests := []struct {
+		name    string
+		modify  func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain)
+		wantErr error
+	}{
+		{
+			name: "beacon root not in oracle",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				p.StateProofParams.BeaconOracleTimestamp = big.NewInt(1)
+			},
+			wantErr: ErrBeaconRootMismatch,
+		},
+		{
+			name: "wrong state root branch",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				p.StateProofParams.StateRootProof[0][0] ^= 0x01
+			},
+			wantErr: ErrInvalidStateRootProof,
+		},
+		{
+			name: "short state root branch",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				p.StateProofParams.StateRootProof = p.StateProofParams.StateRootProof[1:]
+			},
+			wantErr: ErrInvalidStateRootProof,
+		},
+		{
+			name: "wrong rollup",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				target.L1Address = arbitrumInbox
+			},
+			wantErr: ErrInvalidAccountProof,
+		},
+		{
+			name: "wrong assertion",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				p.PrevAssertionHash[0] ^= 0x01
+			},
+			wantErr: ErrInvalidStorageProof,
+		},
+		{
+			name: "wrong block headers",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				p.EncodedBlockArray = append(bytes.Clone(p.EncodedBlockArray), 0x00)
+			},
+			wantErr: ErrInvalidBlockHeaders,
+		},
+		{
+			name: "wrong inbox storage key",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				target.L2StorageKey = InboxStorageKey(common.HexToHash("0x01"))
+			},
+			wantErr: ErrInvalidStorageProof,
+		},
+		{
+			name: "wrong inbox storage value",
+			modify: func(p *proofs.ArbitrumProof, target *ArbitrumTarget, source *fakeSourceChain) {
+				value := bytes.Clone(p.DstL2AccountProofParams.StorageValue)
+				value[len(value)-1] ^= 0x01
+				p.DstL2AccountProofParams.StorageValue = value
+			},
+			wantErr: ErrStorageValueMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var proof proofs.ArbitrumProof
+			readProof(t, "ArbitrumSepoliaProof", &proof)
+			target := target
+			source := sourceChainFor(&proof.StateProofParams)
+			tt.modify(&proof, &target, source)
+
+			_, err := NewVerifier(source).VerifyArbitrum(context.Background(), &proof, target)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifyOPStack(t *testing.T) {
+	var proof proofs.OPStackProof
+	readProof(t, "OPSepoliaProof", &proof)
+	target := OPStackTarget{
+		L1Address:    opStackOracle,
+		L1StorageKey: proof.DstL2StateRootProofParams.StorageKey,
+		L2Address:    opStackInbox,
+		L2StorageKey: proof.DstL2AccountProofParams.StorageKey,
+	}
+	verifier := NewVerifier(sourceChainFor(&proof.StateProofParams))
+
+	value, err := verifier.VerifyOPStack(context.Background(), &proof, target)
+	require.NoError(t, err)
+	require.Equal(t, proof.DstL2AccountProofParams.StorageValue, value)
+
+	proof.L2MessagePasserStorageRoot[0] ^= 0x01
+	_, err = verifier.VerifyOPStack(context.Background(), &proof, target)
+	require.ErrorIs(t, err, ErrInvalidL2StateRoot)
+}
+
+func TestVerifyHashi(t *testing.T) {
+	var proof proofs.HashiProof
+	readProof(t, "HashiProverProof", &proof)
+	_, number, headerTimestamp, err := blockHeaderFields(proof.RlpEncodedBlockHeader)
+	require.NoError(t, err)
+	target := HashiTarget{
+		Addr:               hashiInbox,
+		StorageKey:         proof.DstAccountProofParams.StorageKey,
+		DestinationChainID: big.NewInt(111111),
+		ShoyuBashi:         shoyuBashi,
+	}
+
+	source := &fakeSourceChain{
+		blockHashes: map[uint64]common.Hash{number.Uint64(): crypto.Keccak256Hash(proof.RlpEncodedBlockHeader)},
+	}
+	timestamp, value, err := NewVerifier(source).VerifyHashi(context.Background(), &proof, target)
+	require.NoError(t, err)
+	require.Equal(t, headerTimestamp, timestamp)
+	require.Equal(t, proof.DstAccountProofParams.StorageValue, value)
+
+	source.blockHashes[number.Uint64()] = common.HexToHash("0x01")
+	_, _, err = NewVerifier(source).VerifyHashi(context.Background(), &proof, target)
+	require.ErrorIs(t, err, ErrInvalidBlockHeader)
+}
+
+func TestVerifyClaim(t *testing.T) {
+	var proof proofs.HashiProof
+	readProof(t, "HashiProverProof", &proof)
+	encoded, err := proof.Encode()
+	require.NoError(t, err)
+	_, number, _, err := blockHeaderFields(proof.RlpEncodedBlockHeader)
+	require.NoError(t, err)
+	source := &fakeSourceChain{
+		blockHashes: map[uint64]common.Hash{number.Uint64(): crypto.Keccak256Hash(proof.RlpEncodedBlockHeader)},
+	}
+	fulfillment := DecodeFulfillmentInfo(proof.DstAccountProofParams.StorageValue)
+
+	claim := Claim{
+		ProverType:       "hashi",
+		MessageID:        common.HexToHash("0x01"),
+		DestinationChain: big.NewInt(111111),
+		Inbox:            hashiInbox,
+		Attributes: [][]byte{
+			append(bytes.Clone(shoyuBashiAttributeSelector), common.BytesToHash(shoyuBashi.Bytes()).Bytes()...),
+		},
+		Caller: fulfillment.Fulfiller,
+		Proof:  encoded,
+	}
+
+	// The proof is for another request
+	_, err = NewVerifier(source).VerifyClaim(context.Background(), claim)
+	require.ErrorIs(t, err, ErrInvalidStorageProof)
+
+	// A UserOp request is received by the EntryPoint, its inbox is the one of its inbox attribute
+	userOpClaim := claim
+	userOpClaim.Attributes = append(claim.Attributes,
+		append(bytes.Clone(inboxAttributeSelector), common.BytesToHash(hashiInbox.Bytes()).Bytes()...))
+	userOpClaim.Inbox, err = UserOpInbox(userOpClaim.Attributes)
+	require.NoError(t, err)
+	require.Equal(t, hashiInbox, userOpClaim.Inbox)
+	_, err = NewVerifier(source).VerifyClaim(context.Background(), userOpClaim)
+	require.ErrorIs(t, err, ErrInvalidStorageProof)
+
+	userOpClaim.Inbox = common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032")
+	_, err = NewVerifier(source).VerifyClaim(context.Background(), userOpClaim)
+	require.ErrorIs(t, err, ErrInvalidAccountProof)
+
+	_, err = UserOpInbox(claim.Attributes)
+	require.ErrorIs(t, err, ErrMissingRequiredAttribute)
+
+	claim.Attributes = nil
+	_, err = NewVerifier(source).VerifyClaim(context.Background(), claim)
+	require.ErrorIs(t, err, ErrMissingRequiredAttribute)
+
+	claim.ProverType = "zk"
+	_, err = NewVerifier(source).VerifyClaim(context.Background(), claim)
+	require.ErrorIs(t, err, ErrUnsupportedProverType)
+}
+
+func TestDecodeFulfillmentInfo(t *testing.T) {
+	info := DecodeFulfillmentInfo(common.FromHex("0xe4a3711462d371a7736f26b5f83150f907c4e8ef000000000000000067d2a8ee"))
+
+	require.Equal(t, common.HexToAddress("0xe4a3711462d371a7736f26b5f83150f907c4e8ef"), info.Fulfiller)
+	require.Equal(t, big.NewInt(0x67d2a8ee), info.Timestamp)
+}
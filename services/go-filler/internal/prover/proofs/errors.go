@@ -0,0 +1,7 @@
+package proofs
+
+import "errors"
+
+// ErrProofNotAvailable is returned when a proof cannot be generated yet, because the block to prove is not known to
+// the node. Unlike other errors, generating the proof again later can succeed.
+var ErrProofNotAvailable = errors.New("proof not available yet")
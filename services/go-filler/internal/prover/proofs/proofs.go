@@ -0,0 +1,210 @@
+package proofs
+
+import (
+	"fmt"
+	"math/big"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Statuses of the Arbitrum sequencer machine
+const (
+	RUNNING uint8 = iota
+	FINISHED
+	ERRORED
+)
+
+// Proof is the proof of a fulfillment passed to claimReward, ArbitrumProof, OPStackProof or HashiProof
+type Proof interface {
+	Encode() ([]byte, error)
+	Decode(data []byte) error
+}
+
+// AccountProofParams are the parameters needed to validate a storage location of an account against a state root
+// (StateValidator.AccountProofParameters)
+type AccountProofParams struct {
+	StorageKey   []byte
+	StorageValue []byte
+	AccountProof [][]byte
+	StorageProof [][]byte
+}
+
+// StateProofParams are the parameters needed to validate the authenticity of Ethereum's execution client's state root
+// (StateValidator.StateProofParameters)
+type StateProofParams struct {
+	BeaconRoot            common.Hash
+	BeaconOracleTimestamp *big.Int
+	ExecutionStateRoot    common.Hash
+	StateRootProof        []common.Hash
+}
+
+// GlobalState is the global state of Arbitrum when the assertion node was created
+type GlobalState struct {
+	// The blockhash of the L2 block and the sendRoot
+	Bytes32Vals [2]common.Hash
+	// The inbox position and the position in message of the assertion
+	U64Vals [2]uint64
+}
+
+// AssertionState is the state of an Arbitrum assertion node
+type AssertionState struct {
+	GlobalState GlobalState
+	// The status of the sequencer machine
+	MachineStatus uint8
+	// The end history root of the assertion
+	EndHistoryRoot common.Hash
+}
+
+// ArbitrumProof is the nested cross-L2 storage proof with Arbitrum as the destination chain
+// (ArbitrumProver.RRC7755Proof)
+type ArbitrumProof struct {
+	// These 4 following fields work together with DstL2StateRootProofParams to certify the L2 state (step 2 in
+	// arbitrum_prover/overview.md)
+
+	// The RLP-encoded array of block headers of Arbitrum's L2 block corresponding to the assertion
+	// Hashing this bytes string should produce the blockhash
+	EncodedBlockArray []byte
+	// The state of the assertion node after the sequencer machine has finished
+	AfterState AssertionState
+	// The hash of the previous assertion
+	PrevAssertionHash common.Hash
+	// The accumulator of the sequencer batch
+	SequencerBatchAcc common.Hash
+
+	// Contains L1 block information & the associated proof (step 1)
+	StateProofParams StateProofParams
+	// Proof of the assertion in the Arbitrum rollup contract on L1 (step 2)
+	DstL2StateRootProofParams AccountProofParams
+	// Proof that the inbox contract contains an entry about a successful fulfillment (step 3)
+	DstL2AccountProofParams AccountProofParams
+}
+
+// OPStackProof is the nested cross-L2 storage proof with an OP Stack chain as the destination chain
+// (OPStackProver.RRC7755Proof)
+type OPStackProof struct {
+	// The storage root of Optimism's MessagePasser contract, used to compute the output root
+	L2MessagePasserStorageRoot common.Hash
+	// The RLP-encoded array of block headers of the destination L2 block
+	EncodedBlockArray []byte
+	// Contains L1 block information & the associated proof
+	StateProofParams StateProofParams
+	// Proof of the output root in the anchor state registry on L1
+	DstL2StateRootProofParams AccountProofParams
+	// Proof that the inbox contract contains an entry about a successful fulfillment
+	DstL2AccountProofParams AccountProofParams
+}
+
+// HashiProof is the storage proof of the destination chain against a block header relayed by Hashi
+// (HashiProver.RRC7755Proof)
+type HashiProof struct {
+	// The RLP-encoded block header of the destination chain block
+	RlpEncodedBlockHeader []byte
+	// Proof that the inbox contract contains an entry about a successful fulfillment
+	DstAccountProofParams AccountProofParams
+}
+
+var (
+	accountProofParamsComponents = []ethabi.ArgumentMarshaling{
+		{Name: "storageKey", Type: "bytes"},
+		{Name: "storageValue", Type: "bytes"},
+		{Name: "accountProof", Type: "bytes[]"},
+		{Name: "storageProof", Type: "bytes[]"},
+	}
+
+	stateProofParamsComponents = []ethabi.ArgumentMarshaling{
+		{Name: "beaconRoot", Type: "bytes32"},
+		{Name: "beaconOracleTimestamp", Type: "uint256"},
+		{Name: "executionStateRoot", Type: "bytes32"},
+		{Name: "stateRootProof", Type: "bytes32[]"},
+	}
+
+	arbitrumProofArguments = proofArguments([]ethabi.ArgumentMarshaling{
+		{Name: "encodedBlockArray", Type: "bytes"},
+		{Name: "afterState", Type: "tuple", Components: []ethabi.ArgumentMarshaling{
+			{Name: "globalState", Type: "tuple", Components: []ethabi.ArgumentMarshaling{
+				{Name: "bytes32Vals", Type: "bytes32[2]"},
+				{Name: "u64Vals", Type: "uint64[2]"},
+			}},
+			{Name: "machineStatus", Type: "uint8"},
+			{Name: "endHistoryRoot", Type: "bytes32"},
+		}},
+		{Name: "prevAssertionHash", Type: "bytes32"},
+		{Name: "sequencerBatchAcc", Type: "bytes32"},
+		{Name: "stateProofParams", Type: "tuple", Components: stateProofParamsComponents},
+		{Name: "dstL2StateRootProofParams", Type: "tuple", Components: accountProofParamsComponents},
+		{Name: "dstL2AccountProofParams", Type: "tuple", Components: accountProofParamsComponents},
+	})
+
+	opStackProofArguments = proofArguments([]ethabi.ArgumentMarshaling{
+		{Name: "l2MessagePasserStorageRoot", Type: "bytes32"},
+		{Name: "encodedBlockArray", Type: "bytes"},
+		{Name: "stateProofParams", Type: "tuple", Components: stateProofParamsComponents},
+		{Name: "dstL2StateRootProofParams", Type: "tuple", Components: accountProofParamsComponents},
+		{Name: "dstL2AccountProofParams", Type: "tuple", Components: accountProofParamsComponents},
+	})
+
+	hashiProofArguments = proofArguments([]ethabi.ArgumentMarshaling{
+		{Name: "rlpEncodedBlockHeader", Type: "bytes"},
+		{Name: "dstAccountProofParams", Type: "tuple", Components: accountProofParamsComponents},
+	})
+)
+
+// proofArguments are the arguments of abi.encode(proof), the bytes proof argument of the outbox claimReward
+func proofArguments(components []ethabi.ArgumentMarshaling) ethabi.Arguments {
+	proofType, err := ethabi.NewType("tuple", "", components)
+	if err != nil {
+		panic(err)
+	}
+	return ethabi.Arguments{{Name: "proof", Type: proofType}}
+}
+
+// Encode returns the ABI encoding of the proof, as expected by ArbitrumProver
+func (p *ArbitrumProof) Encode() ([]byte, error) {
+	return encode(arbitrumProofArguments, p)
+}
+
+// Decode decodes an ABI encoded ArbitrumProver proof
+func (p *ArbitrumProof) Decode(data []byte) error {
+	return decode(arbitrumProofArguments, data, p)
+}
+
+// Encode returns the ABI encoding of the proof, as expected by OPStackProver
+func (p *OPStackProof) Encode() ([]byte, error) {
+	return encode(opStackProofArguments, p)
+}
+
+// Decode decodes an ABI encoded OPStackProver proof
+func (p *OPStackProof) Decode(data []byte) error {
+	return decode(opStackProofArguments, data, p)
+}
+
+// Encode returns the ABI encoding of the proof, as expected by HashiProver
+func (p *HashiProof) Encode() ([]byte, error) {
+	return encode(hashiProofArguments, p)
+}
+
+// Decode decodes an ABI encoded HashiProver proof
+func (p *HashiProof) Decode(data []byte) error {
+	return decode(hashiProofArguments, data, p)
+}
+
+func encode(arguments ethabi.Arguments, proof any) ([]byte, error) {
+	data, err := arguments.Pack(proof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ABI encode proof: %w", err)
+	}
+	return data, nil
+}
+
+func decode(arguments ethabi.Arguments, data []byte, proof any) error {
+	values, err := arguments.Unpack(data)
+	if err != nil {
+		return fmt.Errorf("failed to ABI decode proof: %w", err)
+	}
+	// Copy fills a struct field by argument, proof is the only one
+	if err := arguments.Copy(&struct{ Proof any }{proof}, values); err != nil {
+		return fmt.Errorf("failed to ABI decode proof: %w", err)
+	}
+	return nil
+}
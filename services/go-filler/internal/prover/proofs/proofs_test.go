@@ -0,0 +1,178 @@
+package proofs
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/stretchr/testify/require"
+)
+
+// The testdata JSON files are the proofs the TS fulfiller generates (contracts/test/data) and the .hex files their
+// encoding by the TS fulfiller's encodeProof, see fulfiller/scripts/encodeProofFixtures.ts
+
+type accountProofParamsJSON struct {
+	StorageKey   hexutil.Bytes   `json:"storageKey"`
+	StorageValue hexutil.Bytes   `json:"storageValue"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageProof []hexutil.Bytes `json:"storageProof"`
+}
+
+type stateProofParamsJSON struct {
+	BeaconRoot            common.Hash           `json:"beaconRoot"`
+	BeaconOracleTimestamp *math.HexOrDecimal256 `json:"beaconOracleTimestamp"`
+	ExecutionStateRoot    common.Hash           `json:"executionStateRoot"`
+	StateRootProof        []common.Hash         `json:"stateRootProof"`
+}
+
+type arbitrumProofJSON struct {
+	EncodedBlockArray hexutil.Bytes `json:"encodedBlockArray"`
+	AfterState        struct {
+		GlobalState struct {
+			Bytes32Vals [2]common.Hash         `json:"bytes32Vals"`
+			U64Vals     [2]math.HexOrDecimal64 `json:"u64Vals"`
+		} `json:"globalState"`
+		MachineStatus  uint8       `json:"machineStatus"`
+		EndHistoryRoot common.Hash `json:"endHistoryRoot"`
+	} `json:"afterState"`
+	PrevAssertionHash         common.Hash            `json:"prevAssertionHash"`
+	SequencerBatchAcc         common.Hash            `json:"sequencerBatchAcc"`
+	StateProofParams          stateProofParamsJSON   `json:"stateProofParams"`
+	DstL2StateRootProofParams accountProofParamsJSON `json:"dstL2StateRootProofParams"`
+	DstL2AccountProofParams   accountProofParamsJSON `json:"dstL2AccountProofParams"`
+}
+
+type opStackProofJSON struct {
+	L2MessagePasserStorageRoot common.Hash            `json:"l2MessagePasserStorageRoot"`
+	EncodedBlockArray          hexutil.Bytes          `json:"encodedBlockArray"`
+	StateProofParams           stateProofParamsJSON   `json:"stateProofParams"`
+	DstL2StateRootProofParams  accountProofParamsJSON `json:"dstL2StateRootProofParams"`
+	DstL2AccountProofParams    accountProofParamsJSON `json:"dstL2AccountProofParams"`
+}
+
+type hashiProofJSON struct {
+	RlpEncodedBlockHeader hexutil.Bytes          `json:"rlpEncodedBlockHeader"`
+	DstAccountProofParams accountProofParamsJSON `json:"dstAccountProofParams"`
+}
+
+func (p accountProofParamsJSON) typed() AccountProofParams {
+	return AccountProofParams{
+		StorageKey:   p.StorageKey,
+		StorageValue: p.StorageValue,
+		AccountProof: byteSlices(p.AccountProof),
+		StorageProof: byteSlices(p.StorageProof),
+	}
+}
+
+func (p stateProofParamsJSON) typed() StateProofParams {
+	return StateProofParams{
+		BeaconRoot:            p.BeaconRoot,
+		BeaconOracleTimestamp: (*big.Int)(p.BeaconOracleTimestamp),
+		ExecutionStateRoot:    p.ExecutionStateRoot,
+		StateRootProof:        p.StateRootProof,
+	}
+}
+
+func byteSlices(items []hexutil.Bytes) [][]byte {
+	out := make([][]byte, len(items))
+	for i, item := range items {
+		out[i] = item
+	}
+	return out
+}
+
+func readFixture(t *testing.T, name string, proof any) []byte {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, proof))
+
+	encoded, err := os.ReadFile(filepath.Join("testdata", name+".hex"))
+	require.NoError(t, err)
+	return common.FromHex(strings.TrimSpace(string(encoded)))
+}
+
+func TestArbitrumProof(t *testing.T) {
+	var fixture arbitrumProofJSON
+	encoded := readFixture(t, "ArbitrumSepoliaProof", &fixture)
+
+	proof := &ArbitrumProof{
+		EncodedBlockArray: fixture.EncodedBlockArray,
+		AfterState: AssertionState{
+			GlobalState: GlobalState{
+				Bytes32Vals: fixture.AfterState.GlobalState.Bytes32Vals,
+				U64Vals: [2]uint64{
+					uint64(fixture.AfterState.GlobalState.U64Vals[0]),
+					uint64(fixture.AfterState.GlobalState.U64Vals[1]),
+				},
+			},
+			MachineStatus:  fixture.AfterState.MachineStatus,
+			EndHistoryRoot: fixture.AfterState.EndHistoryRoot,
+		},
+		PrevAssertionHash:         fixture.PrevAssertionHash,
+		SequencerBatchAcc:         fixture.SequencerBatchAcc,
+		StateProofParams:          fixture.StateProofParams.typed(),
+		DstL2StateRootProofParams: fixture.DstL2StateRootProofParams.typed(),
+		DstL2AccountProofParams:   fixture.DstL2AccountProofParams.typed(),
+	}
+	require.Equal(t, FINISHED, proof.AfterState.MachineStatus)
+
+	got, err := proof.Encode()
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(encoded), hexutil.Encode(got))
+
+	var decoded ArbitrumProof
+	require.NoError(t, decoded.Decode(encoded))
+	require.Equal(t, proof, &decoded)
+}
+
+func TestOPStackProof(t *testing.T) {
+	var fixture opStackProofJSON
+	encoded := readFixture(t, "OPSepoliaProof", &fixture)
+
+	proof := &OPStackProof{
+		L2MessagePasserStorageRoot: fixture.L2MessagePasserStorageRoot,
+		EncodedBlockArray:          fixture.EncodedBlockArray,
+		StateProofParams:           fixture.StateProofParams.typed(),
+		DstL2StateRootProofParams:  fixture.DstL2StateRootProofParams.typed(),
+		DstL2AccountProofParams:    fixture.DstL2AccountProofParams.typed(),
+	}
+
+	got, err := proof.Encode()
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(encoded), hexutil.Encode(got))
+
+	var decoded OPStackProof
+	require.NoError(t, decoded.Decode(encoded))
+	require.Equal(t, proof, &decoded)
+}
+
+func TestHashiProof(t *testing.T) {
+	var fixture hashiProofJSON
+	encoded := readFixture(t, "HashiProverProof", &fixture)
+
+	proof := &HashiProof{
+		RlpEncodedBlockHeader: fixture.RlpEncodedBlockHeader,
+		DstAccountProofParams: fixture.DstAccountProofParams.typed(),
+	}
+
+	got, err := proof.Encode()
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(encoded), hexutil.Encode(got))
+
+	var decoded HashiProof
+	require.NoError(t, decoded.Decode(encoded))
+	require.Equal(t, proof, &decoded)
+}
+
+func TestDecodeInvalidProof(t *testing.T) {
+	var proof ArbitrumProof
+	require.ErrorContains(t, proof.Decode([]byte{0x01}), "failed to ABI decode proof")
+}
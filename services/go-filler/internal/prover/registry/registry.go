@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/prover/arbitrum_prover"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/base-org/RRC-7755-poc/internal/prover/storage_prover"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNoProver              = errors.New("no prover-type configured")
+	ErrUnsupportedProverType = errors.New("unsupported prover type")
+)
+
+// Prover generates the proof of a request fulfilled on its destination chain in the block fulfillmentBlock, the proof
+// passed to claimReward on the source chain
+type Prover interface {
+	GenerateProof(ctx context.Context, messageID common.Hash, fulfillmentBlock *big.Int) (proofs.Proof, error)
+}
+
+// builder creates the prover of the destination chain dst, l1 being nil for prover types that do not require it
+type builder func(logger *zap.Logger, dst, l1 *client.ChainClient, policy retry.Policy) (Prover, error)
+
+// builders are the prover types implemented in the filler, config.ProvableTypes
+var builders = map[string]builder{
+	config.ProverArbitrum: newArbitrumProver,
+}
+
+// Registry holds the prover of every destination chain configuring a prover-type
+type Registry struct {
+	provers map[uint64]Prover
+}
+
+// NewRegistry creates the prover of every destination chain with the clients of the chain and of its L1. It fails if
+// a chain configures a prover type that is not implemented.
+func NewRegistry(logger *zap.Logger, cfg *config.Config, clients *client.Manager) (*Registry, error) {
+	r := &Registry{provers: make(map[uint64]Prover)}
+	policy := retryPolicy(cfg.Proof.RPC)
+
+	for chainID, dst := range clients.GetAllClients() {
+		proverType := dst.Config.ProverType
+		if proverType == "" {
+			continue
+		}
+
+		build, ok := builders[proverType]
+		if !ok {
+			return nil, fmt.Errorf("chain %d: %w %q", chainID, ErrUnsupportedProverType, proverType)
+		}
+
+		var l1 *client.ChainClient
+		if config.RequiresL1(proverType) {
+			var err error
+			l1, err = clients.GetChainClient(dst.Config.L1ChainID)
+			if err != nil {
+				return nil, fmt.Errorf("L1 of chain %d: %w", chainID, err)
+			}
+		}
+
+		prover, err := build(logger.With(zap.Uint64("destination_chain", chainID)), dst, l1, policy)
+		if err != nil {
+			return nil, fmt.Errorf("creating %s prover of chain %d: %w", proverType, chainID, err)
+		}
+		r.provers[chainID] = prover
+	}
+
+	return r, nil
+}
+
+// Prover returns the prover of the destination chain
+func (r *Registry) Prover(chainID uint64) (Prover, error) {
+	if prover, ok := r.provers[chainID]; ok {
+		return prover, nil
+	}
+	return nil, fmt.Errorf("chain %d: %w", chainID, ErrNoProver)
+}
+
+func newArbitrumProver(logger *zap.Logger, dst, l1 *client.ChainClient, policy retry.Policy) (Prover, error) {
+	rpcClient, err := dst.RPCClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return arbitrum_prover.NewRRC7755ArbitrumProver(
+		logger,
+		l1.Client,
+		storage_prover.RPCL2Client{Client: rpcClient},
+		dst.Config.InboxAddress,
+		l1.Config.Devnet,
+		policy,
+	), nil
+}
+
+func retryPolicy(c config.RetryConfig) retry.Policy {
+	return retry.Policy{
+		Attempts:       c.Attempts,
+		Timeout:        c.Timeout,
+		InitialBackoff: c.InitialBackoff,
+		MaxBackoff:     c.MaxBackoff,
+	}
+}
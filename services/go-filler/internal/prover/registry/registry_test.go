@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/prover/arbitrum_prover"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	l1ChainID       = 11155111
+	arbitrumChainID = 421614
+	opStackChainID  = 84532
+	sourceChainID   = 10
+)
+
+func testClient(t *testing.T, chainCfg config.ChainConfig) *client.ChainClient {
+	rpcClient := rpc.DialInProc(rpc.NewServer())
+	t.Cleanup(rpcClient.Close)
+	return &client.ChainClient{Client: ethclient.NewClient(rpcClient), Config: chainCfg}
+}
+
+func testManager(t *testing.T, chains ...config.ChainConfig) *client.Manager {
+	m := &client.Manager{Chains: make(map[uint64]*client.ChainClient)}
+	for _, chainCfg := range chains {
+		m.Chains[chainCfg.ChainID] = testClient(t, chainCfg)
+	}
+	return m
+}
+
+func destination(chainID uint64, proverType string) config.ChainConfig {
+	return config.ChainConfig{
+		ChainID:      chainID,
+		InboxAddress: common.HexToAddress("0xdc50fdbe95e876f31ea5d4aa01040b095e612ebb"),
+		ProverType:   proverType,
+		L1ChainID:    l1ChainID,
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	clients := testManager(t,
+		config.ChainConfig{ChainID: l1ChainID, Devnet: true},
+		config.ChainConfig{ChainID: sourceChainID},
+		destination(arbitrumChainID, config.ProverArbitrum),
+	)
+
+	registry, err := NewRegistry(zap.NewNop(), &config.Config{}, clients)
+	require.NoError(t, err)
+
+	prover, err := registry.Prover(arbitrumChainID)
+	require.NoError(t, err)
+	require.IsType(t, &arbitrum_prover.RRC7755ArbitrumProver{}, prover)
+
+	_, err = registry.Prover(sourceChainID)
+	require.ErrorIs(t, err, ErrNoProver)
+}
+
+func TestRegistryMissingL1(t *testing.T) {
+	clients := testManager(t, destination(arbitrumChainID, config.ProverArbitrum))
+
+	_, err := NewRegistry(zap.NewNop(), &config.Config{}, clients)
+	require.ErrorContains(t, err, "L1 of chain 421614")
+}
+
+func TestRegistryUnsupportedProverType(t *testing.T) {
+	clients := testManager(t,
+		config.ChainConfig{ChainID: l1ChainID, Devnet: true},
+		destination(opStackChainID, config.ProverOPStack),
+	)
+
+	_, err := NewRegistry(zap.NewNop(), &config.Config{}, clients)
+	require.ErrorIs(t, err, ErrUnsupportedProverType)
+	require.ErrorContains(t, err, "chain 84532")
+}
+
+// The prover types accepted by the config validation must all be implemented
+func TestBuildersCoverProvableTypes(t *testing.T) {
+	for _, proverType := range config.ProvableTypes {
+		require.Contains(t, builders, proverType)
+	}
+	require.Len(t, builders, len(config.ProvableTypes))
+}
@@ -8,16 +8,6 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
-// StorageProofParams contains parameters for storage proofs
-// Moved from inbox_storage_proofs.go to common.go
-
-type StorageProofParams struct {
-	StorageKey   string
-	StorageValue string
-	AccountProof string
-	StorageProof string
-}
-
 // StorageProof contains the key, value, and proof for storage
 // Moved from inbox_storage_proofs.go to common.go
 
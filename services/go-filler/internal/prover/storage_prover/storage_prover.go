@@ -3,12 +3,26 @@ package storage_prover
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 
+	"github.com/base-org/RRC-7755-poc/internal/prover/proof_verifier"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 	"go.uber.org/zap"
 )
 
+// JSON-RPC error codes of requests the node will never serve
+const (
+	invalidRequestCode = -32600
+	methodNotFoundCode = -32601
+	invalidParamsCode  = -32602
+)
+
 // Define types to match Ethereum JSON-RPC responses for eth_getProof
 type AccountResult struct {
 	Address      string         `json:"address"`
@@ -23,25 +37,36 @@ type AccountResult struct {
 // EthRPCClient defines the interface for Ethereum RPC operations
 // This is a subset of rpc.Client interface that we need for storage proofs
 type EthRPCClient interface {
-	Call(result interface{}, method string, args ...interface{}) error
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
 }
 
-// L2Client defines the interface for interacting with L2
+// L2Client defines the interface for interacting with L2, whose RPC calls are bound to the context they are made with
 type L2Client interface {
 	RPCClient() EthRPCClient
 }
 
+// RPCL2Client is the L2Client of an RPC client
+type RPCL2Client struct {
+	Client EthRPCClient
+}
+
+func (c RPCL2Client) RPCClient() EthRPCClient {
+	return c.Client
+}
+
 // InboxStorageProver handles storage proof operations for the inbox contract
 type InboxStorageProver struct {
 	logger   *zap.Logger
 	l2Client L2Client
+	policy   retry.Policy
 }
 
-// NewInboxStorageProver creates a new InboxStorageProver instance
-func NewInboxStorageProver(logger *zap.Logger, l2Client L2Client) *InboxStorageProver {
+// NewInboxStorageProver creates a new InboxStorageProver instance whose RPC calls are retried with the policy
+func NewInboxStorageProver(logger *zap.Logger, l2Client L2Client, policy retry.Policy) *InboxStorageProver {
 	return &InboxStorageProver{
 		logger:   logger,
 		l2Client: l2Client,
+		policy:   policy,
 	}
 }
 
@@ -57,34 +82,44 @@ func (i *InboxStorageProver) GetStorageProofForMapKey(
 	contractAddr common.Address,
 	mapStorageSlot common.Hash,
 	mapKey common.Hash,
-) (*StorageProofParams, error) {
+	block rpc.BlockNumberOrHash,
+) (*proofs.AccountProofParams, *types.Header, error) {
 	storageKey, err := CalculateStorageSlot(mapKey, mapStorageSlot)
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate storage slot: %w", err)
+		return nil, nil, fmt.Errorf("failed to calculate storage slot: %w", err)
 	}
-	return i.GetStorageProofForKey(ctx, contractAddr, storageKey)
+	return i.GetStorageProofForKey(ctx, contractAddr, storageKey, block)
 }
 
-// GetStorageProof retrieves proof for a given storage slot at a contract address
+// GetStorageProofForKey retrieves proof for a given storage slot at a contract address in the given block, along with
+// the header of the block. The proof is checked against the state root of the header.
 func (i *InboxStorageProver) GetStorageProofForKey(
 	ctx context.Context,
 	contractAddr common.Address,
 	storageSlot common.Hash,
-) (*StorageProofParams, error) {
+	block rpc.BlockNumberOrHash,
+) (*proofs.AccountProofParams, *types.Header, error) {
+	// The block is resolved first so that a proof at "latest" is still bound to a single block
+	header, err := i.getHeader(ctx, block)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Use our defined types
 	proof := new(AccountResult)
 
 	i.logger.Info("Getting storage proof",
 		zap.String("contract", contractAddr.Hex()),
-		zap.String("storageSlot", storageSlot.Hex()))
+		zap.String("storageSlot", storageSlot.Hex()),
+		zap.Stringer("block", header.Number))
 
-	err := i.l2Client.RPCClient().Call(proof, "eth_getProof", contractAddr, []string{storageSlot.Hex()}, "latest")
+	err = i.call(ctx, proof, "eth_getProof", contractAddr, []string{storageSlot.Hex()}, hexutil.EncodeBig(header.Number))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get proof from RPC: %w", err)
+		return nil, nil, fmt.Errorf("failed to get proof from RPC: %w", err)
 	}
 
 	if len(proof.StorageProof) == 0 {
-		return nil, fmt.Errorf("no storage proof returned")
+		return nil, nil, fmt.Errorf("no storage proof returned")
 	}
 
 	// Log raw proof data for debugging
@@ -101,7 +136,7 @@ func (i *InboxStorageProver) GetStorageProofForKey(
 	for i, proofStr := range proof.AccountProof {
 		decodedProof, err := safeHexDecode(proofStr)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode account proof item %d: %w", i, err)
+			return nil, nil, fmt.Errorf("failed to decode account proof item %d: %w", i, err)
 		}
 		accountProof[i] = decodedProof
 	}
@@ -110,7 +145,7 @@ func (i *InboxStorageProver) GetStorageProofForKey(
 	for i, proofStr := range proof.StorageProof[0].Proof {
 		decodedProof, err := safeHexDecode(proofStr)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode storage proof item %d: %w", i, err)
+			return nil, nil, fmt.Errorf("failed to decode storage proof item %d: %w", i, err)
 		}
 		storageProofBytes[i] = decodedProof
 	}
@@ -118,21 +153,85 @@ func (i *InboxStorageProver) GetStorageProofForKey(
 	// Get storage value from proof
 	storageValue, err := getStorageValueFromProof(proof)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get storage value from proof: %w", err)
+		return nil, nil, fmt.Errorf("failed to get storage value from proof: %w", err)
+	}
+
+	if err := checkStorageHash(contractAddr, header, proof.StorageHash, accountProof); err != nil {
+		return nil, nil, err
 	}
 
 	i.logger.Info("Storage proof",
 		zap.String("storageSlot", storageSlot.Hex()),
 		zap.String("storageValue", storageValue.Hex()),
+		zap.Stringer("block", header.Number),
 		zap.Int("accountProofLength", len(accountProof)),
 		zap.Int("storageProofLength", len(storageProofBytes)))
 
-	return &StorageProofParams{
-		StorageKey:   storageSlot.Hex(),
-		StorageValue: storageValue.Hex(),
-		AccountProof: formatBytes(accountProof),
-		StorageProof: formatBytes(storageProofBytes),
-	}, nil
+	return &proofs.AccountProofParams{
+		StorageKey: storageSlot.Bytes(),
+		// The storage trie holds values without their leading zeros, which is what the provers compare with
+		StorageValue: common.TrimLeftZeroes(storageValue.Bytes()),
+		AccountProof: accountProof,
+		StorageProof: storageProofBytes,
+	}, header, nil
+}
+
+// getHeader returns the header of the block, proofs.ErrProofNotAvailable when the node does not have it yet
+func (i *InboxStorageProver) getHeader(ctx context.Context, block rpc.BlockNumberOrHash) (*types.Header, error) {
+	var header *types.Header
+	err := retry.Do(ctx, i.policy, func(ctx context.Context) error {
+		var err error
+		if hash, ok := block.Hash(); ok {
+			err = i.l2Client.RPCClient().CallContext(ctx, &header, "eth_getBlockByHash", hash, false)
+		} else {
+			number, _ := block.Number()
+			err = i.l2Client.RPCClient().CallContext(ctx, &header, "eth_getBlockByNumber", number, false)
+		}
+		if err != nil {
+			return classifyRPCError(err)
+		}
+		if header == nil {
+			return proofs.ErrProofNotAvailable
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", block.String(), err)
+	}
+	return header, nil
+}
+
+// call makes an RPC call with the retry policy of the prover
+func (i *InboxStorageProver) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return retry.Do(ctx, i.policy, func(ctx context.Context) error {
+		return classifyRPCError(i.l2Client.RPCClient().CallContext(ctx, result, method, args...))
+	})
+}
+
+// classifyRPCError marks the errors of requests the node rejected as permanent, the other ones (timeouts, connection
+// and server errors) can be retried
+func classifyRPCError(err error) error {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		case invalidRequestCode, methodNotFoundCode, invalidParamsCode:
+			return retry.Permanent(err)
+		}
+	}
+	return err
+}
+
+// checkStorageHash checks that storageHash is the storage root of the contract in the state of the block, proven by
+// the account proof
+func checkStorageHash(contractAddr common.Address, header *types.Header, storageHash string, accountProof [][]byte) error {
+	storageRoot, err := proof_verifier.AccountStorageRoot(contractAddr, header.Root, accountProof)
+	if err != nil {
+		return fmt.Errorf("failed to verify account proof against block %s: %w", header.Number, err)
+	}
+	if storageRoot != common.HexToHash(storageHash) {
+		return fmt.Errorf("storage hash %s does not match the storage root %s of block %s", storageHash, storageRoot.Hex(), header.Number)
+	}
+	return nil
 }
 
 // getStorageValueFromProof extracts and decodes the storage value from the proof result
@@ -163,16 +262,3 @@ func safeHexDecode(hexStr string) ([]byte, error) {
 
 	return hex.DecodeString(hexStr)
 }
-
-func formatBytes(data [][]byte) string {
-	if len(data) == 0 {
-		return "[]"
-	}
-
-	hexStrings := make([]string, len(data))
-	for i, b := range data {
-		hexStrings[i] = hex.EncodeToString(b)
-	}
-
-	return fmt.Sprintf("%v", hexStrings)
-}
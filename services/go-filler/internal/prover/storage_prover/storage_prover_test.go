@@ -0,0 +1,354 @@
+package storage_prover
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/base-org/RRC-7755-poc/internal/prover/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/prover/proofs"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+type InboxStorageProverTestSuite struct {
+	suite.Suite
+	ctrl    *gomock.Controller
+	mockRPC *mocks.MockEthRPCClient
+	logger  *zap.Logger
+	prover  *InboxStorageProver
+	ctx     context.Context
+
+	// Sample data for tests
+	sampleContractAddr   common.Address
+	sampleRequestHash    common.Hash
+	sampleStorageKey     common.Hash
+	sampleStorageSlot    common.Hash
+	sampleAccountResult  AccountResult
+	expectedStorageProof []string
+
+	// Proof of the inbox storage of the Hashi prover contract tests, consistent with its block header
+	provenHeader        *types.Header
+	provenAddr          common.Address
+	provenStorageKey    common.Hash
+	provenAccountResult AccountResult
+}
+
+func TestInboxStorageProverSuite(t *testing.T) {
+	suite.Run(t, new(InboxStorageProverTestSuite))
+}
+
+func (s *InboxStorageProverTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockRPC = mocks.NewMockEthRPCClient(s.ctrl)
+	s.logger = zaptest.NewLogger(s.T())
+	s.prover = NewInboxStorageProver(s.logger, &mockL2Client{rpcClient: s.mockRPC}, retry.Policy{Attempts: 1})
+	s.ctx = context.Background()
+
+	// Initialize sample data
+	s.sampleContractAddr = common.HexToAddress("0xcf278dd7069e9fc7aed00e18274dfd7102e95351")
+	s.sampleRequestHash = common.HexToHash("0x86a798714c57faaa50bc649a07dc45013c4931d4c630d5a316f5975f02704586")
+	s.sampleStorageKey = common.HexToHash("0xc8d3d25a93f51f19c8ccc231e6bd32c5e3cde64a1e819275035a770b9bf96282")
+	s.sampleStorageSlot = common.HexToHash("0x40f2eef6aad3cb0e74d3b59b45d3d5f2d5fc8dc382e739617b693cdd4bc30c00")
+
+	// Initialize expected storage proof
+	s.expectedStorageProof = []string{
+		"0xf8d180a064ac856028686eb1a14c793150690c06ae10b86d483b636862594bd14e3242bc80808080a0890d06a4933bf655e2696075c1b42f18ddcfbd8225b0f7f9e2c8a71e38caaf3980a05ba662047a4ea50714bfa845351186e4ffb6c2d94bd68bc6f2e35603a0ee293aa0f21792d7f0547ac8d78a7b8e7ee46eb787ac28dc14e4ef418b18bb8bc7e8db37a0143547079b13cf81c1b9247992b2b0a13b4a807e50c8737f178ffe013b95d3f8a0a8add1b7b21c59e037532fe175e091073f5132168834ab0ef4da80d7a1e7722d8080808080",
+		"0xf85180808080808080808080a0498d9c2850b4dbabc8eadc537c019d8379d475faea01bc350851a89241e61290a005b0aceefb21748231965fb1716c97403ace46d84254253d0322f8733946cb2a8080808080",
+		"0xf843a02026cdbb4fbb9d4c6d6f1b4e8fe4562ddb26fb5fba0bf20e125707b210f3ab5fa1a0e4a3711462d371a7736f26b5f83150f907c4e8ef000000000000000067d2a8ee",
+	}
+
+	// Initialize sample account result
+	s.sampleAccountResult = AccountResult{
+		Address:     s.sampleContractAddr.Hex(),
+		Balance:     "0x51f7d",
+		CodeHash:    "0x2249ab3489d21e26c55cc3236eaa9ea25cec0dcefdc86b7e424b6fa859a6c599",
+		Nonce:       "0x1",
+		StorageHash: "0xea368b18f5ce23c11ef5409bd612e9389dd34d97bd581981bf99ab8dbda41883",
+		AccountProof: []string{
+			"0xf90211a01643f30c6975912b99246fe648654aefeb0ef560685615c13758ce99bc7a5acf",
+			// ... other account proofs ...
+		},
+		StorageProof: []StorageProof{
+			{
+				Key:   s.sampleStorageKey.Hex(),
+				Value: "0xe4a3711462d371a7736f26b5f83150f907c4e8ef000000000000000067d2a8ee",
+				Proof: s.expectedStorageProof,
+			},
+		},
+	}
+}
+
+func (s *InboxStorageProverTestSuite) SetupSuite() {
+	encoded, err := os.ReadFile("../proofs/testdata/HashiProverProof.hex")
+	s.Require().NoError(err)
+	var proof proofs.HashiProof
+	s.Require().NoError(proof.Decode(common.FromHex(strings.TrimSpace(string(encoded)))))
+
+	s.provenHeader = new(types.Header)
+	s.Require().NoError(rlp.DecodeBytes(proof.RlpEncodedBlockHeader, s.provenHeader))
+	s.provenAddr = common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	s.provenStorageKey = common.BytesToHash(proof.DstAccountProofParams.StorageKey)
+
+	params := proof.DstAccountProofParams
+	s.provenAccountResult = AccountResult{
+		Address: s.provenAddr.Hex(),
+		StorageProof: []StorageProof{{
+			Key:   s.provenStorageKey.Hex(),
+			Value: hexutil.Encode(params.StorageValue),
+		}},
+	}
+	for _, node := range params.AccountProof {
+		s.provenAccountResult.AccountProof = append(s.provenAccountResult.AccountProof, hexutil.Encode(node))
+	}
+	for _, node := range params.StorageProof {
+		s.provenAccountResult.StorageProof[0].Proof = append(s.provenAccountResult.StorageProof[0].Proof, hexutil.Encode(node))
+	}
+	// The storage hash is the root of the storage trie, the first node of the storage proof
+	s.provenAccountResult.StorageHash = crypto.Keccak256Hash(params.StorageProof[0]).Hex()
+}
+
+// expectHeader expects the header of the block to be fetched and returns header
+func (s *InboxStorageProverTestSuite) expectHeader(method string, block interface{}, header *types.Header) {
+	s.mockRPC.EXPECT().
+		CallContext(gomock.Any(), gomock.Any(), method, block, false).
+		DoAndReturn(func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			*result.(**types.Header) = header
+			return nil
+		})
+}
+
+// expectProof expects the proof to be fetched at the block number and returns proof
+func (s *InboxStorageProverTestSuite) expectProof(number *big.Int, proof AccountResult) {
+	s.mockRPC.EXPECT().
+		CallContext(gomock.Any(), gomock.Any(), "eth_getProof", gomock.Any(), gomock.Any(), hexutil.EncodeBig(number)).
+		DoAndReturn(func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			*result.(*AccountResult) = proof
+			return nil
+		})
+}
+
+func (s *InboxStorageProverTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+type mockL2Client struct {
+	rpcClient EthRPCClient
+}
+
+func (m *mockL2Client) RPCClient() EthRPCClient {
+	return m.rpcClient
+}
+
+type jsonRPCError struct {
+	code int
+}
+
+func (e *jsonRPCError) Error() string {
+	return "rejected"
+}
+
+func (e *jsonRPCError) ErrorCode() int {
+	return e.code
+}
+
+func (s *InboxStorageProverTestSuite) TestCalculateStorageSlot() {
+	// Test case from Solidity execution:
+	// bytes32 messageId = 0x86a798714c57faaa50bc649a07dc45013c4931d4c630d5a316f5975f02704586;
+	// bytes32 slot = 0x40f2eef6aad3cb0e74d3b59b45d3d5f2d5fc8dc382e739617b693cdd4bc30c00;
+	// bytes32 derivedSlot = keccak256(abi.encode(messageId, slot));
+	// Expected result: 0xc8d3d25a93f51f19c8ccc231e6bd32c5e3cde64a1e819275035a770b9bf96282
+
+	messageId := common.HexToHash("0x86a798714c57faaa50bc649a07dc45013c4931d4c630d5a316f5975f02704586")
+	slot := common.HexToHash("0x40f2eef6aad3cb0e74d3b59b45d3d5f2d5fc8dc382e739617b693cdd4bc30c00")
+	expectedDerivedSlot := common.HexToHash("0xc8d3d25a93f51f19c8ccc231e6bd32c5e3cde64a1e819275035a770b9bf96282")
+
+	calculatedSlot, err := CalculateStorageSlot(messageId, slot)
+	s.NoError(err, "Storage slot calculation should not return an error")
+	s.Equal(expectedDerivedSlot, calculatedSlot, "Calculated slot should match expected result from Solidity")
+}
+
+func (s *InboxStorageProverTestSuite) TestGetStorageProof() {
+	s.Run("successful proof retrieval", func() {
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, s.provenHeader)
+		s.expectProof(s.provenHeader.Number, s.provenAccountResult)
+
+		result, header, err := s.prover.GetStorageProofForKey(s.ctx, s.provenAddr, s.provenStorageKey, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+		s.NoError(err)
+		s.NotNil(result)
+		s.Equal(s.provenHeader, header)
+
+		// Verify the decoded results
+		s.Equal(s.provenStorageKey.Bytes(), result.StorageKey)
+		s.Equal(common.FromHex(s.provenAccountResult.StorageProof[0].Value), result.StorageValue)
+
+		// Verify the decoded proofs
+		s.Len(result.AccountProof, len(s.provenAccountResult.AccountProof))
+		s.Equal(common.FromHex(s.provenAccountResult.AccountProof[0]), result.AccountProof[0])
+		s.Len(result.StorageProof, len(s.provenAccountResult.StorageProof[0].Proof))
+		s.Equal(common.FromHex(s.provenAccountResult.StorageProof[0].Proof[0]), result.StorageProof[0])
+	})
+
+	s.Run("proof at block hash", func() {
+		hash := common.HexToHash("0x1234")
+		s.expectHeader("eth_getBlockByHash", hash, s.provenHeader)
+		s.expectProof(s.provenHeader.Number, s.provenAccountResult)
+
+		_, header, err := s.prover.GetStorageProofForKey(s.ctx, s.provenAddr, s.provenStorageKey, rpc.BlockNumberOrHashWithHash(hash, false))
+		s.NoError(err)
+		s.Equal(s.provenHeader, header)
+	})
+
+	s.Run("block not found", func() {
+		s.expectHeader("eth_getBlockByNumber", rpc.BlockNumber(1), nil)
+
+		result, _, err := s.prover.GetStorageProofForKey(s.ctx, s.provenAddr, s.provenStorageKey, rpc.BlockNumberOrHashWithNumber(1))
+		s.Nil(result)
+		s.ErrorIs(err, proofs.ErrProofNotAvailable)
+	})
+
+	s.Run("transient errors are retried", func() {
+		s.prover.policy = retry.Policy{Attempts: 2}
+		defer func() { s.prover.policy = retry.Policy{Attempts: 1} }()
+
+		s.mockRPC.EXPECT().
+			CallContext(gomock.Any(), gomock.Any(), "eth_getBlockByNumber", rpc.LatestBlockNumber, false).
+			Return(assert.AnError)
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, s.provenHeader)
+		s.mockRPC.EXPECT().
+			CallContext(gomock.Any(), gomock.Any(), "eth_getProof", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+		s.expectProof(s.provenHeader.Number, s.provenAccountResult)
+
+		_, _, err := s.prover.GetStorageProofForKey(s.ctx, s.provenAddr, s.provenStorageKey, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+		s.NoError(err)
+	})
+
+	s.Run("rejected requests are not retried", func() {
+		s.prover.policy = retry.Policy{Attempts: 2}
+		defer func() { s.prover.policy = retry.Policy{Attempts: 1} }()
+
+		s.mockRPC.EXPECT().
+			CallContext(gomock.Any(), gomock.Any(), "eth_getBlockByNumber", rpc.LatestBlockNumber, false).
+			Return(&jsonRPCError{code: methodNotFoundCode})
+
+		_, _, err := s.prover.GetStorageProofForKey(s.ctx, s.provenAddr, s.provenStorageKey, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+		s.True(retry.IsPermanent(err))
+	})
+
+	s.Run("RPC call fails", func() {
+		contractAddr := common.HexToAddress("0x...")
+		requestHash := common.HexToHash("0x...")
+
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, s.provenHeader)
+		s.mockRPC.EXPECT().
+			CallContext(gomock.Any(), gomock.Any(), "eth_getProof", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(assert.AnError)
+
+		result, _, err := s.prover.GetStorageProofForKey(s.ctx, contractAddr, requestHash, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+		s.Error(err)
+		s.Nil(result)
+		s.ErrorContains(err, "failed to get proof from RPC")
+	})
+}
+
+func (s *InboxStorageProverTestSuite) TestGetStorageProofForKey() {
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	s.Run("storage hash of another block", func() {
+		proof := s.provenAccountResult
+		proof.StorageHash = s.sampleAccountResult.StorageHash
+
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, s.provenHeader)
+		s.expectProof(s.provenHeader.Number, proof)
+
+		result, _, err := s.prover.GetStorageProofForKey(s.ctx, s.provenAddr, s.provenStorageKey, latest)
+		s.Nil(result)
+		s.ErrorContains(err, "does not match the storage root")
+	})
+
+	s.Run("account proof of another block", func() {
+		header := types.CopyHeader(s.provenHeader)
+		header.Root = common.HexToHash("0x1234")
+
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, header)
+		s.expectProof(header.Number, s.provenAccountResult)
+
+		result, _, err := s.prover.GetStorageProofForKey(s.ctx, s.provenAddr, s.provenStorageKey, latest)
+		s.Nil(result)
+		s.ErrorContains(err, "failed to verify account proof")
+	})
+
+	s.Run("empty storage proof", func() {
+		contractAddr := common.HexToAddress("0x...")
+		storageKey := common.HexToHash("0x...")
+
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, s.provenHeader)
+		s.expectProof(s.provenHeader.Number, AccountResult{
+			Address:      contractAddr.Hex(),
+			StorageHash:  "0x0",
+			StorageProof: []StorageProof{},
+		})
+
+		result, _, err := s.prover.GetStorageProofForKey(s.ctx, contractAddr, storageKey, latest)
+		s.Error(err)
+		s.Nil(result)
+		s.ErrorContains(err, "no storage proof returned")
+	})
+
+	s.Run("invalid hex in storage proof", func() {
+		contractAddr := common.HexToAddress("0x...")
+		storageKey := common.HexToHash("0x...")
+
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, s.provenHeader)
+		s.expectProof(s.provenHeader.Number, AccountResult{
+			Address:      contractAddr.Hex(),
+			StorageHash:  "0x0",
+			AccountProof: []string{"0x1234"},
+			StorageProof: []StorageProof{{
+				Key:   "0x0",
+				Value: "0x0",
+				Proof: []string{"invalid hex"},
+			}},
+		})
+
+		result, _, err := s.prover.GetStorageProofForKey(s.ctx, contractAddr, storageKey, latest)
+		s.Error(err)
+		s.Nil(result)
+		s.ErrorContains(err, "failed to decode storage proof")
+	})
+
+	s.Run("invalid storage value", func() {
+		contractAddr := common.HexToAddress("0x...")
+		storageKey := common.HexToHash("0x...")
+
+		s.expectHeader("eth_getBlockByNumber", rpc.LatestBlockNumber, s.provenHeader)
+		s.expectProof(s.provenHeader.Number, AccountResult{
+			Address:      contractAddr.Hex(),
+			StorageHash:  "0x0",
+			AccountProof: []string{"0x1234"},
+			StorageProof: []StorageProof{{
+				Key:   "0x0",
+				Value: "invalid hex",
+				Proof: []string{"0x1234"},
+			}},
+		})
+
+		result, _, err := s.prover.GetStorageProofForKey(s.ctx, contractAddr, storageKey, latest)
+		s.Error(err)
+		s.Nil(result)
+		s.ErrorContains(err, "failed to decode storage value")
+	})
+}
@@ -0,0 +1,130 @@
+package reconcile
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap/zapcore"
+)
+
+// Revenue is an amount per reward asset, rewards in different assets can't be added up
+type Revenue map[common.Address]*big.Int
+
+func (r Revenue) add(asset common.Address, amount *big.Int) {
+	if _, ok := r[asset]; !ok {
+		r[asset] = new(big.Int)
+	}
+	r[asset].Add(r[asset], amount)
+}
+
+// assets returns the reward assets sorted by address
+func (r Revenue) assets() []common.Address {
+	assets := make([]common.Address, 0, len(r))
+	for asset := range r {
+		assets = append(assets, asset)
+	}
+	sort.Slice(assets, func(i, j int) bool { return bytes.Compare(assets[i][:], assets[j][:]) < 0 })
+	return assets
+}
+
+// String lists the amount of every asset, "0" when there is no revenue
+func (r Revenue) String() string {
+	if len(r) == 0 {
+		return "0"
+	}
+
+	amounts := make([]string, 0, len(r))
+	for _, asset := range r.assets() {
+		amounts = append(amounts, asset.Hex()+":"+r[asset].String())
+	}
+	return strings.Join(amounts, ",")
+}
+
+func (r Revenue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, asset := range r.assets() {
+		enc.AddString(asset.Hex(), r[asset].String())
+	}
+	return nil
+}
+
+// ChainReport summarizes the claims of the requests posted on a source chain
+type ChainReport struct {
+	SourceChain uint64 `json:"source_chain"`
+	// Claimed is the number of requests claimed by anyone
+	Claimed int `json:"claimed"`
+	// Won are the requests we fulfilled and claimed, Lost the ones we fulfilled but a competitor claimed
+	Won  int `json:"won"`
+	Lost int `json:"lost"`
+	// Skipped are the requests we skipped that a competitor claimed
+	Skipped int `json:"skipped"`
+	// Pending are the requests we fulfilled that are not claimed yet
+	Pending int `json:"pending"`
+	// Revenue sums the realized rewards per reward asset
+	Revenue Revenue `json:"revenue"`
+	// Competitors counts the claims of every other submitter
+	Competitors map[common.Address]int `json:"competitors"`
+}
+
+func newChainReport(chainID uint64) *ChainReport {
+	return &ChainReport{
+		SourceChain: chainID,
+		Revenue:     make(Revenue),
+		Competitors: make(map[common.Address]int),
+	}
+}
+
+// WinRate is the share of the requests we fulfilled that we also claimed
+func (r *ChainReport) WinRate() float64 {
+	if r.Won+r.Lost == 0 {
+		return 0
+	}
+	return float64(r.Won) / float64(r.Won+r.Lost)
+}
+
+func (r *ChainReport) addSettlement(settlement *Settlement, request *Request) {
+	r.Claimed++
+
+	if settlement.Ours {
+		r.Won++
+		if settlement.RewardAsset != nil && settlement.RealizedReward != nil {
+			r.Revenue.add(*settlement.RewardAsset, settlement.RealizedReward)
+		}
+		return
+	}
+
+	r.Competitors[settlement.Submitter]++
+	if request == nil {
+		return
+	}
+
+	switch request.Decision {
+	case DecisionFulfill:
+		r.Lost++
+	case DecisionSkip:
+		r.Skipped++
+	}
+}
+
+func (r *ChainReport) addUnsettled(request *Request) {
+	if request.Decision == DecisionFulfill {
+		r.Pending++
+	}
+}
+
+func (r *ChainReport) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddUint64("source_chain", r.SourceChain)
+	enc.AddInt("claimed", r.Claimed)
+	enc.AddInt("won", r.Won)
+	enc.AddInt("lost", r.Lost)
+	enc.AddInt("skipped", r.Skipped)
+	enc.AddInt("pending", r.Pending)
+	enc.AddFloat64("win_rate", r.WinRate())
+	if err := enc.AddObject("revenue", r.Revenue); err != nil {
+		return err
+	}
+	enc.AddInt("competitors", len(r.Competitors))
+	return nil
+}
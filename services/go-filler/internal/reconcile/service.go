@@ -0,0 +1,404 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/base-org/RRC-7755-poc/internal/retry"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+	"go.uber.org/zap"
+)
+
+const (
+	// Maximum number of blocks requested per eth_getLogs call while catching up
+	blockRange = 1000
+
+	defaultReportInterval = time.Hour
+
+	completedBufferSize = 10
+)
+
+// resubscribePolicy spaces the attempts to restore a claim subscription dropped by the node, it never gives up
+var resubscribePolicy = retry.Policy{
+	Attempts:       math.MaxInt,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+}
+
+// Outbox is the subset of the RRC7755Outbox binding used to follow the claims of an outbox
+type Outbox interface {
+	FilterCrossChainCallCompleted(opts *bind.FilterOpts, messageId [][32]byte) (*rrc_7755_outbox.RRC7755OutboxCrossChainCallCompletedIterator, error)
+	WatchCrossChainCallCompleted(opts *bind.WatchOpts, sink chan<- *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted, messageId [][32]byte) (event.Subscription, error)
+	GetRequesterAndExpiryAndReward(opts *bind.CallOpts, messageId [32]byte, attributes [][]byte) ([32]byte, *big.Int, [32]byte, *big.Int, error)
+}
+
+type completed struct {
+	chainID uint64
+	outbox  Outbox
+	event   *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted
+}
+
+// Service matches the requests seen by the filler with the CrossChainCallCompleted events of the source chain outboxes,
+// recording who claimed each of them and the reward we realized, and periodically logs a report per chain
+type Service struct {
+	config    config.ReconcileConfig
+	wallet    common.Address
+	logger    *zap.Logger
+	clientMgr *client.Manager
+	store     *Store
+
+	now        func() time.Time
+	bindOutbox func(address common.Address, chain *client.ChainClient) (Outbox, error)
+}
+
+func NewService(clientMgr *client.Manager, cfg *config.Config, logger *zap.Logger) (*Service, error) {
+	store, err := OpenStore(cfg.Reconcile.StorePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		config:     cfg.Reconcile,
+		wallet:     cfg.Wallets.GetFromAddress(),
+		logger:     logger,
+		clientMgr:  clientMgr,
+		store:      store,
+		now:        time.Now,
+		bindOutbox: bindOutbox,
+	}, nil
+}
+
+func (s *Service) ServiceName() string {
+	return "Reconciler"
+}
+
+// Close releases the store file
+func (s *Service) Close() error {
+	return s.store.Close()
+}
+
+// TrackRequest records whether the filler fulfilled or skipped a request, skipErr being the reason it was skipped.
+// The attributes of fulfilled requests are kept to read their reward once claimed.
+func (s *Service) TrackRequest(
+	messageID [32]byte,
+	sourceChain uint64,
+	destinationChain uint64,
+	attributes [][]byte,
+	skipErr error,
+) {
+	request := &Request{
+		Timestamp:        s.now().UTC(),
+		MessageID:        messageID,
+		SourceChain:      sourceChain,
+		DestinationChain: destinationChain,
+		Decision:         DecisionFulfill,
+	}
+	if skipErr != nil {
+		request.Decision = DecisionSkip
+		request.Reason = skipErr.Error()
+	} else {
+		request.Attributes = make([]hexutil.Bytes, len(attributes))
+		for i, attribute := range attributes {
+			request.Attributes[i] = attribute
+		}
+	}
+
+	if err := s.store.AddRequest(request); err != nil {
+		s.logger.Error("Recording request decision", zap.Error(err))
+	}
+}
+
+// Reports aggregates everything recorded so far per source chain
+func (s *Service) Reports() []*ChainReport {
+	return s.store.Reports()
+}
+
+// Run follows the new claims of every source chain while catching up on the ones made since its last checkpoint, and
+// logs the reports at the configured interval. Catch-ups run in the background, new claims are read meanwhile.
+func (s *Service) Run(ctx context.Context) error {
+	events := make(chan completed, completedBufferSize)
+
+	// The catch-ups write to the store, they are done before Run returns and the store can be closed
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for _, chain := range s.clientMgr.GetAllClients() {
+		if len(chain.Config.OutboxAddresses) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.follow(ctx, chain, events); err != nil && ctx.Err() == nil {
+				s.logger.Error("Following outbox claims", zap.Uint64("chain_id", chain.Config.ChainID), zap.Error(err))
+			}
+		}()
+	}
+
+	interval := s.config.ReportInterval
+	if interval == 0 {
+		interval = defaultReportInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case c := <-events:
+			if err := s.handleCompleted(ctx, c.chainID, c.outbox, c.event); err != nil {
+				s.logger.Error("Recording claim", zap.Error(err))
+			}
+		case <-ticker.C:
+			s.logReports()
+		case <-ctx.Done():
+			s.logReports()
+			return nil
+		}
+	}
+}
+
+// follow subscribes to the claims of every outbox of the chain before catching up, so no claim falls between the two.
+// Claims seen twice are deduplicated by the store.
+func (s *Service) follow(ctx context.Context, chain *client.ChainClient, events chan<- completed) error {
+	outboxes := make([]Outbox, 0, len(chain.Config.OutboxAddresses))
+	for _, address := range chain.Config.OutboxAddresses {
+		outbox, err := s.bindOutbox(address, chain)
+		if err != nil {
+			return fmt.Errorf("binding outbox %s: %w", address.Hex(), err)
+		}
+		outboxes = append(outboxes, outbox)
+
+		sink := make(chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted, completedBufferSize)
+		sub, err := outbox.WatchCrossChainCallCompleted(&bind.WatchOpts{Context: ctx}, sink, nil)
+		if err != nil {
+			return fmt.Errorf("creating WatchCrossChainCallCompleted subscription: %w", err)
+		}
+
+		go s.forward(ctx, chain, address, outbox, sink, sub, events)
+	}
+
+	return s.catchUp(ctx, chain, outboxes)
+}
+
+// forward sends the claims of an outbox to events until ctx is done. A subscription dropped by the node is restored
+// with backoff, the claims missed meanwhile are caught up on at the next start, only catch-ups move the checkpoint.
+func (s *Service) forward(
+	ctx context.Context,
+	chain *client.ChainClient,
+	address common.Address,
+	outbox Outbox,
+	sink chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted,
+	sub event.Subscription,
+	events chan<- completed,
+) {
+	fields := []zap.Field{zap.Uint64("chain_id", chain.Config.ChainID), zap.String("outbox_address", address.Hex())}
+	for {
+		select {
+		case event := <-sink:
+			select {
+			case events <- completed{chainID: chain.Config.ChainID, outbox: outbox, event: event}:
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				return
+			}
+		case err := <-sub.Err():
+			sub.Unsubscribe()
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.logger.Error("Outbox claim subscription dropped, resubscribing", append(fields, zap.Error(err))...)
+			if sub = s.resubscribe(ctx, outbox, sink, fields); sub == nil {
+				return
+			}
+		case <-ctx.Done():
+			sub.Unsubscribe()
+			return
+		}
+	}
+}
+
+// resubscribe watches the claims of the outbox again after a drop, backing off between the attempts until ctx is done.
+// It returns nil once ctx is done.
+func (s *Service) resubscribe(
+	ctx context.Context,
+	outbox Outbox,
+	sink chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted,
+	fields []zap.Field,
+) event.Subscription {
+	// A node dropping subscriptions right after accepting them is not resubscribed to in a loop
+	timer := time.NewTimer(resubscribePolicy.InitialBackoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-timer.C:
+	}
+
+	var sub event.Subscription
+	err := retry.Do(ctx, resubscribePolicy, func(ctx context.Context) error {
+		var err error
+		if sub, err = outbox.WatchCrossChainCallCompleted(&bind.WatchOpts{Context: ctx}, sink, nil); err != nil {
+			s.logger.Warn("Resubscribing outbox claims", append(fields, zap.Error(err))...)
+		}
+		return err
+	})
+	if err != nil {
+		return nil
+	}
+
+	s.logger.Info("Resubscribed outbox claims", fields...)
+	return sub
+}
+
+// catchUp processes the claims from the block after the chain checkpoint, or from lookback blocks ago on the first run,
+// up to the current head
+func (s *Service) catchUp(ctx context.Context, chain *client.ChainClient, outboxes []Outbox) error {
+	chainID := chain.Config.ChainID
+
+	head, err := chain.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("getting head block: %w", err)
+	}
+	to := head.Number.Uint64()
+
+	var from uint64
+	if checkpoint, ok := s.store.Checkpoint(chainID); ok {
+		from = checkpoint + 1
+	} else if to > s.config.LookbackBlocks {
+		from = to - s.config.LookbackBlocks
+	}
+
+	for start := from; start <= to; start += blockRange {
+		end := min(start+blockRange-1, to)
+
+		count := 0
+		for _, outbox := range outboxes {
+			it, err := outbox.FilterCrossChainCallCompleted(&bind.FilterOpts{Start: start, End: &end, Context: ctx}, nil)
+			if err != nil {
+				return fmt.Errorf("filtering claims on chain %d: %w", chainID, err)
+			}
+
+			for it.Next() {
+				count++
+				if err := s.handleCompleted(ctx, chainID, outbox, it.Event); err != nil {
+					it.Close()
+					return err
+				}
+			}
+			if err := it.Error(); err != nil {
+				it.Close()
+				return fmt.Errorf("iterating claims on chain %d: %w", chainID, err)
+			}
+			it.Close()
+		}
+
+		if err := s.store.SetCheckpoint(chainID, end); err != nil {
+			return err
+		}
+
+		s.logger.Info("Reconciled claims",
+			zap.Uint64("chain_id", chainID),
+			zap.Uint64("from_block", start),
+			zap.Uint64("to_block", end),
+			zap.Int("events", count),
+		)
+	}
+
+	return nil
+}
+
+// handleCompleted records a claim. The realized reward is read from the outbox for our own claims only, it is what
+// the outbox received from the requester as decoded from the attributes of the request.
+func (s *Service) handleCompleted(
+	ctx context.Context,
+	chainID uint64,
+	outbox Outbox,
+	event *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted,
+) error {
+	if s.store.Settled(event.MessageId) {
+		return nil
+	}
+
+	settlement := &Settlement{
+		Timestamp:   s.now().UTC(),
+		MessageID:   event.MessageId,
+		SourceChain: chainID,
+		Block:       event.Raw.BlockNumber,
+		TxHash:      event.Raw.TxHash,
+		Submitter:   event.Submitter,
+		Ours:        event.Submitter == s.wallet,
+	}
+
+	if settlement.Ours {
+		if err := s.readReward(ctx, outbox, event, settlement); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.AddSettlement(settlement); err != nil {
+		return err
+	}
+
+	s.logger.Info("Request claimed",
+		zap.String("message_id", common.Hash(event.MessageId).Hex()),
+		zap.Uint64("chain_id", chainID),
+		zap.String("submitter", event.Submitter.Hex()),
+		zap.Bool("ours", settlement.Ours),
+	)
+
+	return nil
+}
+
+// readReward sets the asset and amount of the reward of our claim, from the attributes tracked with the request
+func (s *Service) readReward(
+	ctx context.Context,
+	outbox Outbox,
+	event *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted,
+	settlement *Settlement,
+) error {
+	request := s.store.Request(event.MessageId)
+	if request == nil || len(request.Attributes) == 0 {
+		s.logger.Warn("Attributes of claimed request not recorded, realized reward unknown",
+			zap.String("message_id", common.Hash(event.MessageId).Hex()),
+		)
+		return nil
+	}
+
+	attributes := make([][]byte, len(request.Attributes))
+	for i, attribute := range request.Attributes {
+		attributes[i] = attribute
+	}
+
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(event.Raw.BlockNumber)}
+	_, _, asset, reward, err := outbox.GetRequesterAndExpiryAndReward(opts, event.MessageId, attributes)
+	if err != nil {
+		return fmt.Errorf("getting realized reward of %s: %w", common.Hash(event.MessageId).Hex(), err)
+	}
+
+	rewardAsset := common.BytesToAddress(asset[:])
+	settlement.RewardAsset = &rewardAsset
+	settlement.RealizedReward = reward
+	return nil
+}
+
+func bindOutbox(address common.Address, chain *client.ChainClient) (Outbox, error) {
+	return rrc_7755_outbox.NewRRC7755Outbox(address, chain.Client)
+}
+
+func (s *Service) logReports() {
+	for _, report := range s.Reports() {
+		s.logger.Info("Reconciliation report", zap.Object("report", report))
+	}
+}
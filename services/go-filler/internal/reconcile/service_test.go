@@ -0,0 +1,284 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/client/mocks"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+const testChainID = 84532
+
+var (
+	testWallet     = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	testCompetitor = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	testOutbox     = common.HexToAddress("0x3542dd26727844524ea7c136c5c38ff8088b30ba")
+	testAsset      = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+	// testAttributes are the attributes of every tracked request, the outbox only holds a reward for them
+	testAttributes = [][]byte{{0xa3, 0x62, 0xe5, 0xdb, 0x01}}
+)
+
+func completedLog(t *testing.T, messageID common.Hash, submitter common.Address, block uint64) types.Log {
+	outboxAbi, err := rrc_7755_outbox.RRC7755OutboxMetaData.GetAbi()
+	require.NoError(t, err)
+
+	event := outboxAbi.Events["CrossChainCallCompleted"]
+	data, err := event.Inputs.NonIndexed().Pack(submitter)
+	require.NoError(t, err)
+
+	return types.Log{
+		Address:     testOutbox,
+		Topics:      []common.Hash{event.ID, messageID},
+		Data:        data,
+		BlockNumber: block,
+	}
+}
+
+// newTestChain returns a source chain at head whose outbox emitted logs and holds reward for every message
+func newTestChain(t *testing.T, head uint64, logs []types.Log, reward *big.Int, queried *[][2]uint64) *client.ChainClient {
+	outboxAbi, err := rrc_7755_outbox.RRC7755OutboxMetaData.GetAbi()
+	require.NoError(t, err)
+
+	ethClient := mocks.NewMockEthClient(gomock.NewController(t))
+	ethClient.EXPECT().
+		HeaderByNumber(gomock.Any(), gomock.Nil()).
+		Return(&types.Header{Number: new(big.Int).SetUint64(head)}, nil).
+		AnyTimes()
+	ethClient.EXPECT().
+		FilterLogs(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+			from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+			*queried = append(*queried, [2]uint64{from, to})
+
+			var matched []types.Log
+			for _, log := range logs {
+				if log.BlockNumber >= from && log.BlockNumber <= to {
+					matched = append(matched, log)
+				}
+			}
+			return matched, nil
+		}).
+		AnyTimes()
+	ethClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+			method := outboxAbi.Methods["getRequesterAndExpiryAndReward"]
+			args, err := method.Inputs.Unpack(msg.Data[4:])
+			require.NoError(t, err)
+			require.Equal(t, testAttributes, args[1])
+
+			return method.Outputs.Pack([32]byte{}, big.NewInt(0), common.BytesToHash(testAsset.Bytes()), reward)
+		}).
+		AnyTimes()
+
+	return &client.ChainClient{
+		Client: ethClient,
+		Config: config.ChainConfig{
+			ChainID:         testChainID,
+			OutboxAddresses: map[string]common.Address{config.ProverOPStack: testOutbox},
+		},
+	}
+}
+
+func newTestService(t *testing.T, path string) *Service {
+	store, err := OpenStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return &Service{
+		config:     config.ReconcileConfig{Enabled: true, StorePath: path, LookbackBlocks: 2000},
+		wallet:     testWallet,
+		logger:     zaptest.NewLogger(t),
+		store:      store,
+		now:        func() time.Time { return time.Unix(1700000000, 0) },
+		bindOutbox: bindOutbox,
+	}
+}
+
+func catchUp(t *testing.T, s *Service, chain *client.ChainClient) {
+	outbox, err := s.bindOutbox(testOutbox, chain)
+	require.NoError(t, err)
+	require.NoError(t, s.catchUp(context.Background(), chain, []Outbox{outbox}))
+}
+
+func TestCatchUpReconcilesClaims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reconcile.jsonl")
+	s := newTestService(t, path)
+
+	s.TrackRequest(common.Hash{1}, testChainID, 421614, testAttributes, nil)
+	s.TrackRequest(common.Hash{2}, testChainID, 421614, testAttributes, nil)
+
+	logs := []types.Log{
+		completedLog(t, common.Hash{1}, testWallet, 600),
+		completedLog(t, common.Hash{2}, testCompetitor, 1600),
+	}
+	var queried [][2]uint64
+	catchUp(t, s, newTestChain(t, 2500, logs, big.NewInt(1000), &queried))
+
+	// The first run looks back from the head, in ranges of at most 1000 blocks
+	require.Equal(t, [][2]uint64{{500, 1499}, {1500, 2499}, {2500, 2500}}, queried)
+
+	reports := s.Reports()
+	require.Len(t, reports, 1)
+	require.Equal(t, 1, reports[0].Won)
+	require.Equal(t, 1, reports[0].Lost)
+	require.Equal(t, Revenue{testAsset: big.NewInt(1000)}, reports[0].Revenue)
+	require.Equal(t, map[common.Address]int{testCompetitor: 1}, reports[0].Competitors)
+
+	// A restart resumes after the checkpoint and doesn't count claims twice
+	require.NoError(t, s.Close())
+	s = newTestService(t, path)
+	queried = nil
+	catchUp(t, s, newTestChain(t, 2600, logs, big.NewInt(1000), &queried))
+
+	require.Equal(t, [][2]uint64{{2501, 2600}}, queried)
+	require.Equal(t, 2, s.Reports()[0].Claimed)
+}
+
+func TestTrackRequestRecordsSkipReason(t *testing.T) {
+	s := newTestService(t, filepath.Join(t.TempDir(), "reconcile.jsonl"))
+
+	s.TrackRequest(common.Hash{1}, testChainID, 421614, testAttributes, context.DeadlineExceeded)
+
+	request := s.store.requests[common.Hash{1}]
+	require.Equal(t, DecisionSkip, request.Decision)
+	require.Equal(t, context.DeadlineExceeded.Error(), request.Reason)
+	require.Empty(t, request.Attributes)
+}
+
+// droppingOutbox drops its first claim subscription, then emits a claim on the next one
+type droppingOutbox struct {
+	Outbox
+	watches atomic.Int32
+}
+
+func (o *droppingOutbox) WatchCrossChainCallCompleted(
+	opts *bind.WatchOpts,
+	sink chan<- *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted,
+	messageId [][32]byte,
+) (event.Subscription, error) {
+	if o.watches.Add(1) == 1 {
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			return errors.New("connection reset")
+		}), nil
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		sink <- &rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted{MessageId: common.Hash{1}}
+		<-quit
+		return nil
+	}), nil
+}
+
+func TestForwardResubscribes(t *testing.T) {
+	policy := resubscribePolicy
+	resubscribePolicy.InitialBackoff = time.Millisecond
+	t.Cleanup(func() { resubscribePolicy = policy })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newTestService(t, filepath.Join(t.TempDir(), "reconcile.jsonl"))
+	chain := &client.ChainClient{Config: config.ChainConfig{ChainID: testChainID}}
+	outbox := &droppingOutbox{}
+
+	sink := make(chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted, completedBufferSize)
+	sub, err := outbox.WatchCrossChainCallCompleted(&bind.WatchOpts{Context: ctx}, sink, nil)
+	require.NoError(t, err)
+
+	events := make(chan completed, completedBufferSize)
+	go s.forward(ctx, chain, testOutbox, outbox, sink, sub, events)
+
+	select {
+	case c := <-events:
+		require.Equal(t, [32]byte(common.Hash{1}), c.event.MessageId)
+		require.Equal(t, int32(2), outbox.watches.Load())
+	case <-time.After(time.Second):
+		t.Fatal("claim not forwarded after the subscription was dropped")
+	}
+}
+
+// liveOutbox emits claims on its subscription as soon as it is watched
+type liveOutbox struct {
+	Outbox
+	claims []common.Hash
+}
+
+func (o *liveOutbox) WatchCrossChainCallCompleted(
+	opts *bind.WatchOpts,
+	sink chan<- *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted,
+	messageId [][32]byte,
+) (event.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, messageID := range o.claims {
+			select {
+			case sink <- &rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted{MessageId: messageID, Submitter: testCompetitor}:
+			case <-quit:
+				return nil
+			}
+		}
+		<-quit
+		return nil
+	}), nil
+}
+
+func TestRunReadsClaimsWhileCatchingUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := newTestService(t, filepath.Join(t.TempDir(), "reconcile.jsonl"))
+
+	// More live claims than the events buffer holds
+	outbox := &liveOutbox{}
+	for i := range 3 * completedBufferSize {
+		outbox.claims = append(outbox.claims, common.BigToHash(big.NewInt(int64(i+1))))
+	}
+	s.bindOutbox = func(address common.Address, chain *client.ChainClient) (Outbox, error) {
+		return outbox, nil
+	}
+
+	// The catch-up only gets the head once every live claim was recorded
+	ethClient := mocks.NewMockEthClient(gomock.NewController(t))
+	ethClient.EXPECT().
+		HeaderByNumber(gomock.Any(), gomock.Nil()).
+		DoAndReturn(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+			require.Eventually(t, func() bool {
+				for _, messageID := range outbox.claims {
+					if !s.store.Settled(messageID) {
+						return false
+					}
+				}
+				return true
+			}, time.Second, 10*time.Millisecond)
+			cancel()
+			return nil, ctx.Err()
+		})
+	s.clientMgr = &client.Manager{Chains: map[uint64]*client.ChainClient{
+		testChainID: {
+			Client: ethClient,
+			Config: config.ChainConfig{
+				ChainID:         testChainID,
+				OutboxAddresses: map[string]common.Address{config.ProverOPStack: testOutbox},
+			},
+		},
+	}}
+
+	require.NoError(t, s.Run(ctx))
+	require.Equal(t, len(outbox.claims), s.Reports()[0].Claimed)
+}
@@ -0,0 +1,234 @@
+package reconcile
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	DecisionFulfill = "fulfill"
+	DecisionSkip    = "skip"
+)
+
+// Request is the decision taken by the filler for a posted request
+type Request struct {
+	Timestamp        time.Time   `json:"timestamp"`
+	MessageID        common.Hash `json:"message_id"`
+	SourceChain      uint64      `json:"source_chain"`
+	DestinationChain uint64      `json:"destination_chain"`
+	Decision         string      `json:"decision"`
+	Reason           string      `json:"reason,omitempty"`
+	// Attributes are the MessagePosted attributes of a fulfilled request, or of its UserOp paymaster data, the outbox
+	// reads the reward from them
+	Attributes []hexutil.Bytes `json:"attributes,omitempty"`
+}
+
+// Settlement is a CrossChainCallCompleted event, the claim of the reward of a request
+type Settlement struct {
+	Timestamp   time.Time      `json:"timestamp"`
+	MessageID   common.Hash    `json:"message_id"`
+	SourceChain uint64         `json:"source_chain"`
+	Block       uint64         `json:"block"`
+	TxHash      common.Hash    `json:"tx_hash"`
+	Submitter   common.Address `json:"submitter"`
+	Ours        bool           `json:"ours"`
+	// RewardAsset and RealizedReward are the asset and amount of the reward we received, only set for our claims
+	RewardAsset    *common.Address `json:"reward_asset,omitempty"`
+	RealizedReward *big.Int        `json:"realized_reward,omitempty"`
+}
+
+// Checkpoint is the last block of a source chain whose CrossChainCallCompleted events were all processed. Settlements
+// received live don't move it as older blocks may not have been caught up yet.
+type Checkpoint struct {
+	SourceChain uint64 `json:"source_chain"`
+	Block       uint64 `json:"block"`
+}
+
+// record is a line of the store file, exactly one of its fields is set
+type record struct {
+	Request    *Request    `json:"request,omitempty"`
+	Settlement *Settlement `json:"settlement,omitempty"`
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+}
+
+// Store keeps the requests and settlements in memory and appends every change to a JSONL file, which is replayed when
+// the store is opened again
+type Store struct {
+	mu          sync.Mutex
+	file        *os.File
+	requests    map[common.Hash]*Request
+	settlements map[common.Hash]*Settlement
+	checkpoints map[uint64]uint64
+}
+
+// OpenStore loads the store file at path, creating it if needed, and appends to it from then on
+func OpenStore(path string) (*Store, error) {
+	s, err := ReadStore(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if s == nil {
+		s = newStore()
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening reconcile store: %w", err)
+	}
+	s.file = file
+
+	return s, nil
+}
+
+// ReadStore loads the store file at path without writing to it
+func ReadStore(path string) (*Store, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening reconcile store: %w", err)
+	}
+	defer file.Close()
+
+	s := newStore()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("decoding reconcile store line %d: %w", line, err)
+		}
+		s.apply(&r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading reconcile store: %w", err)
+	}
+
+	return s, nil
+}
+
+func newStore() *Store {
+	return &Store{
+		requests:    make(map[common.Hash]*Request),
+		settlements: make(map[common.Hash]*Settlement),
+		checkpoints: make(map[uint64]uint64),
+	}
+}
+
+func (s *Store) apply(r *record) {
+	switch {
+	case r.Request != nil:
+		s.requests[r.Request.MessageID] = r.Request
+	case r.Settlement != nil:
+		s.settlements[r.Settlement.MessageID] = r.Settlement
+	case r.Checkpoint != nil:
+		s.checkpoints[r.Checkpoint.SourceChain] = max(s.checkpoints[r.Checkpoint.SourceChain], r.Checkpoint.Block)
+	}
+}
+
+func (s *Store) write(r *record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return errors.New("reconcile store is read only")
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding reconcile record: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing reconcile record: %w", err)
+	}
+
+	s.apply(r)
+	return nil
+}
+
+// AddRequest records the decision taken for a request, replacing any earlier one
+func (s *Store) AddRequest(request *Request) error {
+	return s.write(&record{Request: request})
+}
+
+// AddSettlement records the claim of a request
+func (s *Store) AddSettlement(settlement *Settlement) error {
+	return s.write(&record{Settlement: settlement})
+}
+
+// SetCheckpoint records that every CrossChainCallCompleted event of the chain up to block was processed
+func (s *Store) SetCheckpoint(chainID uint64, block uint64) error {
+	return s.write(&record{Checkpoint: &Checkpoint{SourceChain: chainID, Block: block}})
+}
+
+// Checkpoint returns the last processed block of the chain, false if the chain was never processed
+func (s *Store) Checkpoint(chainID uint64) (uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	block, ok := s.checkpoints[chainID]
+	return block, ok
+}
+
+// Request returns the decision recorded for the message, nil if the request was never seen
+func (s *Store) Request(messageID common.Hash) *Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[messageID]
+}
+
+// Settled reports whether the claim of the message was already recorded
+func (s *Store) Settled(messageID common.Hash) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.settlements[messageID]
+	return ok
+}
+
+// Reports aggregates the store per source chain, sorted by chain ID
+func (s *Store) Reports() []*ChainReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := make(map[uint64]*ChainReport)
+	report := func(chainID uint64) *ChainReport {
+		if _, ok := reports[chainID]; !ok {
+			reports[chainID] = newChainReport(chainID)
+		}
+		return reports[chainID]
+	}
+
+	for id, settlement := range s.settlements {
+		report(settlement.SourceChain).addSettlement(settlement, s.requests[id])
+	}
+	for id, request := range s.requests {
+		if _, ok := s.settlements[id]; !ok {
+			report(request.SourceChain).addUnsettled(request)
+		}
+	}
+
+	sorted := make([]*ChainReport, 0, len(reports))
+	for _, r := range reports {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].SourceChain < sorted[j].SourceChain })
+
+	return sorted
+}
+
+func (s *Store) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,76 @@
+package reconcile
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reconcile.jsonl")
+	competitor := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	eth := common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+	usdc := common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+
+	store, err := OpenStore(path)
+	require.NoError(t, err)
+
+	require.NoError(t, store.AddRequest(&Request{MessageID: common.Hash{1}, SourceChain: 84532, Decision: DecisionFulfill}))
+	require.NoError(t, store.AddRequest(&Request{MessageID: common.Hash{2}, SourceChain: 84532, Decision: DecisionFulfill}))
+	require.NoError(t, store.AddRequest(&Request{MessageID: common.Hash{3}, SourceChain: 84532, Decision: DecisionSkip, Reason: "validating reward"}))
+	require.NoError(t, store.AddRequest(&Request{MessageID: common.Hash{4}, SourceChain: 421614, Decision: DecisionFulfill}))
+	require.NoError(t, store.AddRequest(&Request{MessageID: common.Hash{5}, SourceChain: 84532, Decision: DecisionFulfill}))
+	require.NoError(t, store.AddRequest(&Request{MessageID: common.Hash{6}, SourceChain: 84532, Decision: DecisionFulfill}))
+	require.NoError(t, store.AddSettlement(&Settlement{MessageID: common.Hash{1}, SourceChain: 84532, Block: 10, Ours: true, RewardAsset: &eth, RealizedReward: big.NewInt(1000)}))
+	require.NoError(t, store.AddSettlement(&Settlement{MessageID: common.Hash{5}, SourceChain: 84532, Block: 10, Ours: true, RewardAsset: &eth, RealizedReward: big.NewInt(500)}))
+	require.NoError(t, store.AddSettlement(&Settlement{MessageID: common.Hash{6}, SourceChain: 84532, Block: 10, Ours: true, RewardAsset: &usdc, RealizedReward: big.NewInt(7)}))
+	require.NoError(t, store.AddSettlement(&Settlement{MessageID: common.Hash{2}, SourceChain: 84532, Block: 11, Submitter: competitor}))
+	require.NoError(t, store.AddSettlement(&Settlement{MessageID: common.Hash{3}, SourceChain: 84532, Block: 12, Submitter: competitor}))
+	require.NoError(t, store.SetCheckpoint(84532, 20))
+	require.NoError(t, store.Close())
+
+	store, err = OpenStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.True(t, store.Settled(common.Hash{2}))
+	require.False(t, store.Settled(common.Hash{4}))
+	checkpoint, ok := store.Checkpoint(84532)
+	require.True(t, ok)
+	require.Equal(t, uint64(20), checkpoint)
+	_, ok = store.Checkpoint(421614)
+	require.False(t, ok)
+
+	reports := store.Reports()
+	require.Len(t, reports, 2)
+
+	base := reports[0]
+	require.Equal(t, uint64(84532), base.SourceChain)
+	require.Equal(t, 5, base.Claimed)
+	require.Equal(t, 3, base.Won)
+	require.Equal(t, 1, base.Lost)
+	require.Equal(t, 1, base.Skipped)
+	require.Equal(t, 0.75, base.WinRate())
+	// Rewards in different assets are summed separately
+	require.Equal(t, Revenue{eth: big.NewInt(1500), usdc: big.NewInt(7)}, base.Revenue)
+	require.Equal(t, usdc.Hex()+":7,"+eth.Hex()+":1500", base.Revenue.String())
+	require.Equal(t, map[common.Address]int{competitor: 2}, base.Competitors)
+
+	require.Equal(t, uint64(421614), reports[1].SourceChain)
+	require.Equal(t, 1, reports[1].Pending)
+}
+
+func TestReadStoreIsReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reconcile.jsonl")
+
+	store, err := OpenStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = ReadStore(path)
+	require.NoError(t, err)
+	require.ErrorContains(t, store.SetCheckpoint(1, 1), "read only")
+}
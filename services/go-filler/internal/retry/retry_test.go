@@ -0,0 +1,115 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("transient")
+
+func testPolicy(attempts int) Policy {
+	return Policy{Attempts: attempts, Timeout: time.Second, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    Policy
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			policy:    testPolicy(3),
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "success after retries",
+			policy:    testPolicy(3),
+			errs:      []error{errTransient, errTransient, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "attempts exhausted",
+			policy:    testPolicy(3),
+			errs:      []error{errTransient, errTransient, errTransient},
+			wantCalls: 3,
+			wantErr:   errTransient,
+		},
+		{
+			name:      "permanent error",
+			policy:    testPolicy(3),
+			errs:      []error{Permanent(errTransient)},
+			wantCalls: 1,
+			wantErr:   errTransient,
+		},
+		{
+			name:      "zero attempts still calls once",
+			policy:    Policy{},
+			errs:      []error{errTransient},
+			wantCalls: 1,
+			wantErr:   errTransient,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), tt.policy, func(ctx context.Context) error {
+				calls++
+				return tt.errs[calls-1]
+			})
+
+			require.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDoTimeout(t *testing.T) {
+	policy := testPolicy(2)
+	policy.Timeout = time.Millisecond
+
+	calls := 0
+	err := Do(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.Equal(t, 2, calls)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := testPolicy(5)
+	policy.InitialBackoff = time.Hour
+
+	calls := 0
+	err := Do(ctx, policy, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errTransient
+	})
+
+	require.Equal(t, 1, calls)
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, errTransient)
+}
+
+func TestPermanent(t *testing.T) {
+	require.NoError(t, Permanent(nil))
+	require.False(t, IsPermanent(errTransient))
+	require.True(t, IsPermanent(Permanent(errTransient)))
+	require.Equal(t, errTransient.Error(), Permanent(errTransient).Error())
+}
@@ -0,0 +1,375 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"go.uber.org/zap"
+)
+
+const (
+	completedBufferSize = 10
+
+	// CrossChainCallStatus.Canceled and Completed of RRC7755Outbox.getMessageStatus
+	messageStatusCanceled  uint8 = 2
+	messageStatusCompleted uint8 = 3
+
+	ReleaseClaimed  = "claimed"
+	ReleaseCanceled = "canceled"
+	ReleaseFailed   = "fulfillment failed"
+)
+
+// ErrLimitExceeded is returned for requests that would break a risk limit
+var ErrLimitExceeded = errors.New("risk limit exceeded")
+
+// Outbox is the subset of the RRC7755Outbox binding used to learn when a request is claimed or canceled
+type Outbox interface {
+	GetMessageStatus(opts *bind.CallOpts, messageId [32]byte) (uint8, error)
+	WatchCrossChainCallCompleted(opts *bind.WatchOpts, sink chan<- *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted, messageId [][32]byte) (event.Subscription, error)
+	WatchCrossChainCallCanceled(opts *bind.WatchOpts, sink chan<- *rrc_7755_outbox.RRC7755OutboxCrossChainCallCanceled, messageId [][32]byte) (event.Subscription, error)
+}
+
+// settlement is the end of a request on its outbox, reason being why its exposure is released
+type settlement struct {
+	messageID [32]byte
+	reason    string
+}
+
+// Manager enforces the risk limits. The exposure of a request is reserved when it is fulfilled and released once its
+// reward is claimed or the request is canceled on the source chain, or right away if the fulfillment failed.
+type Manager struct {
+	config    config.RiskConfig
+	logger    *zap.Logger
+	clientMgr *client.Manager
+
+	mu            sync.Mutex
+	store         *store
+	outstanding   map[common.Hash]*Exposure
+	chainExposure map[uint64]*big.Int
+	totalExposure *big.Int
+	// fulfilled holds the recent fulfillment times of every requester
+	fulfilled map[common.Hash][]time.Time
+
+	now        func() time.Time
+	getChain   func(chainID uint64) (*client.ChainClient, error)
+	bindOutbox func(address common.Address, chain *client.ChainClient) (Outbox, error)
+}
+
+func NewManager(clientMgr *client.Manager, cfg *config.Config, logger *zap.Logger) (*Manager, error) {
+	store, outstanding, err := openStore(cfg.Risk.StorePath, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		config:        cfg.Risk,
+		logger:        logger,
+		clientMgr:     clientMgr,
+		store:         store,
+		outstanding:   make(map[common.Hash]*Exposure),
+		chainExposure: make(map[uint64]*big.Int),
+		totalExposure: new(big.Int),
+		fulfilled:     make(map[common.Hash][]time.Time),
+		now:           time.Now,
+		getChain:      clientMgr.GetChainClient,
+		bindOutbox: func(address common.Address, chain *client.ChainClient) (Outbox, error) {
+			return rrc_7755_outbox.NewRRC7755Outbox(address, chain.Client)
+		},
+	}
+	for _, exposure := range outstanding {
+		m.add(exposure)
+		m.fulfilled[exposure.Requester] = append(m.fulfilled[exposure.Requester], exposure.Timestamp)
+	}
+	for _, times := range m.fulfilled {
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	}
+
+	logger.Info("Restored risk exposure",
+		zap.Int("outstanding_requests", len(m.outstanding)),
+		zap.Stringer("total_exposure", m.totalExposure),
+	)
+
+	return m, nil
+}
+
+func (m *Manager) ServiceName() string {
+	return "RiskManager"
+}
+
+// Close releases the exposure store
+func (m *Manager) Close() error {
+	return m.store.Close()
+}
+
+// Check returns an ErrLimitExceeded error when fulfilling the request would break a limit, without reserving anything
+func (m *Manager) Check(exposure *Exposure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.check(exposure)
+}
+
+// Reserve checks the limits and records the exposure of a request about to be fulfilled
+func (m *Manager) Reserve(exposure *Exposure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.outstanding[exposure.MessageID]; ok {
+		return nil
+	}
+
+	if err := m.check(exposure); err != nil {
+		return err
+	}
+
+	exposure.Timestamp = m.now().UTC()
+	if err := m.store.write(&record{Reserve: exposure}); err != nil {
+		return err
+	}
+	m.add(exposure)
+	m.fulfilled[exposure.Requester] = append(m.fulfilled[exposure.Requester], exposure.Timestamp)
+
+	m.logger.Info("Reserved risk exposure",
+		zap.String("message_id", exposure.MessageID.Hex()),
+		zap.Stringer("amount", exposure.Amount),
+		zap.Stringer("chain_exposure", m.chainExposure[exposure.DestinationChain]),
+		zap.Stringer("total_exposure", m.totalExposure),
+	)
+
+	return nil
+}
+
+// Release ends the exposure of a request, it does nothing for requests that were not reserved
+func (m *Manager) Release(messageID [32]byte, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	exposure, ok := m.outstanding[messageID]
+	if !ok {
+		return
+	}
+
+	release := &Release{Timestamp: m.now().UTC(), MessageID: messageID, Reason: reason}
+	if err := m.store.write(&record{Release: release}); err != nil {
+		m.logger.Error("Releasing risk exposure", zap.Error(err))
+		return
+	}
+
+	delete(m.outstanding, exposure.MessageID)
+	m.chainExposure[exposure.DestinationChain].Sub(m.chainExposure[exposure.DestinationChain], exposure.Amount)
+	m.totalExposure.Sub(m.totalExposure, exposure.Amount)
+
+	m.logger.Info("Released risk exposure",
+		zap.String("message_id", exposure.MessageID.Hex()),
+		zap.String("reason", reason),
+		zap.Stringer("amount", exposure.Amount),
+		zap.Stringer("total_exposure", m.totalExposure),
+	)
+}
+
+// Run releases the exposures claimed or canceled while the filler was stopped, then every exposure whose request is
+// claimed or canceled
+func (m *Manager) Run(ctx context.Context) error {
+	settled := make(chan settlement, completedBufferSize)
+
+	for _, chain := range m.clientMgr.GetAllClients() {
+		for _, address := range chain.Config.OutboxAddresses {
+			if err := m.watch(ctx, chain, address, settled); err != nil {
+				m.logger.Error("Watching outbox claims and cancellations",
+					zap.Uint64("chain_id", chain.Config.ChainID),
+					zap.String("outbox_address", address.Hex()),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	m.releaseSettled(ctx)
+
+	for {
+		select {
+		case s := <-settled:
+			m.Release(s.messageID, s.reason)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *Manager) watch(ctx context.Context, chain *client.ChainClient, address common.Address, settled chan<- settlement) error {
+	outbox, err := m.bindOutbox(address, chain)
+	if err != nil {
+		return fmt.Errorf("binding outbox: %w", err)
+	}
+
+	completed := make(chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted, completedBufferSize)
+	completedSub, err := outbox.WatchCrossChainCallCompleted(&bind.WatchOpts{Context: ctx}, completed, nil)
+	if err != nil {
+		return fmt.Errorf("creating WatchCrossChainCallCompleted subscription: %w", err)
+	}
+
+	canceled := make(chan *rrc_7755_outbox.RRC7755OutboxCrossChainCallCanceled, completedBufferSize)
+	canceledSub, err := outbox.WatchCrossChainCallCanceled(&bind.WatchOpts{Context: ctx}, canceled, nil)
+	if err != nil {
+		completedSub.Unsubscribe()
+		return fmt.Errorf("creating WatchCrossChainCallCanceled subscription: %w", err)
+	}
+
+	dropped := func(err error) {
+		if ctx.Err() == nil {
+			m.logger.Error("Outbox settlement subscription dropped",
+				zap.Uint64("chain_id", chain.Config.ChainID),
+				zap.String("outbox_address", address.Hex()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	go func() {
+		defer completedSub.Unsubscribe()
+		defer canceledSub.Unsubscribe()
+		for {
+			var s settlement
+			select {
+			case event := <-completed:
+				s = settlement{messageID: event.MessageId, reason: ReleaseClaimed}
+			case event := <-canceled:
+				s = settlement{messageID: event.MessageId, reason: ReleaseCanceled}
+			case err := <-completedSub.Err():
+				dropped(err)
+				return
+			case err := <-canceledSub.Err():
+				dropped(err)
+				return
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case settled <- s:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// releaseSettled reads the status of every outstanding request on its outbox and releases the completed and canceled
+// ones
+func (m *Manager) releaseSettled(ctx context.Context) {
+	m.mu.Lock()
+	exposures := make([]*Exposure, 0, len(m.outstanding))
+	for _, exposure := range m.outstanding {
+		exposures = append(exposures, exposure)
+	}
+	m.mu.Unlock()
+
+	for _, exposure := range exposures {
+		status, err := m.messageStatus(ctx, exposure)
+		if err != nil {
+			m.logger.Error("Getting status of exposed request",
+				zap.String("message_id", exposure.MessageID.Hex()),
+				zap.Error(err),
+			)
+			continue
+		}
+		switch status {
+		case messageStatusCompleted:
+			m.Release(exposure.MessageID, ReleaseClaimed)
+		case messageStatusCanceled:
+			m.Release(exposure.MessageID, ReleaseCanceled)
+		}
+	}
+}
+
+func (m *Manager) messageStatus(ctx context.Context, exposure *Exposure) (uint8, error) {
+	chain, err := m.getChain(exposure.SourceChain)
+	if err != nil {
+		return 0, err
+	}
+
+	outbox, err := m.bindOutbox(exposure.Outbox, chain)
+	if err != nil {
+		return 0, fmt.Errorf("binding outbox: %w", err)
+	}
+
+	return outbox.GetMessageStatus(&bind.CallOpts{Context: ctx}, exposure.MessageID)
+}
+
+func (m *Manager) add(exposure *Exposure) {
+	m.outstanding[exposure.MessageID] = exposure
+	if _, ok := m.chainExposure[exposure.DestinationChain]; !ok {
+		m.chainExposure[exposure.DestinationChain] = new(big.Int)
+	}
+	m.chainExposure[exposure.DestinationChain].Add(m.chainExposure[exposure.DestinationChain], exposure.Amount)
+	m.totalExposure.Add(m.totalExposure, exposure.Amount)
+}
+
+func (m *Manager) check(exposure *Exposure) error {
+	if limit := m.config.MaxRequestValue; limit != nil && exposure.Amount.Cmp(limit) > 0 {
+		return fmt.Errorf("%w: request value %s is above the per request cap of %s", ErrLimitExceeded, exposure.Amount, limit)
+	}
+
+	if limit := m.config.MaxChainExposure; limit != nil {
+		chainExposure := new(big.Int).Add(exposure.Amount, m.exposureOf(exposure.DestinationChain))
+		if chainExposure.Cmp(limit) > 0 {
+			return fmt.Errorf("%w: chain %d exposure would reach %s, above the cap of %s",
+				ErrLimitExceeded, exposure.DestinationChain, chainExposure, limit)
+		}
+	}
+
+	if limit := m.config.MaxTotalExposure; limit != nil {
+		totalExposure := new(big.Int).Add(exposure.Amount, m.totalExposure)
+		if totalExposure.Cmp(limit) > 0 {
+			return fmt.Errorf("%w: total exposure would reach %s, above the cap of %s", ErrLimitExceeded, totalExposure, limit)
+		}
+	}
+
+	if m.config.RequesterLimit > 0 {
+		if count := m.recentRequests(exposure.Requester); count >= m.config.RequesterLimit {
+			return fmt.Errorf("%w: requester %s already had %d requests fulfilled in the last %s",
+				ErrLimitExceeded, exposure.Requester.Hex(), count, m.config.RequesterWindow)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) exposureOf(chainID uint64) *big.Int {
+	if exposure, ok := m.chainExposure[chainID]; ok {
+		return exposure
+	}
+	return new(big.Int)
+}
+
+// recentRequests counts the fulfillments of the requester within the window, forgetting older ones
+func (m *Manager) recentRequests(requester common.Hash) int {
+	since := m.now().Add(-m.config.RequesterWindow)
+
+	times := m.fulfilled[requester]
+	i := 0
+	for i < len(times) && !times[i].After(since) {
+		i++
+	}
+	times = times[i:]
+
+	if len(times) == 0 {
+		delete(m.fulfilled, requester)
+	} else {
+		m.fulfilled[requester] = times
+	}
+	return len(times)
+}
@@ -0,0 +1,162 @@
+package risk
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/bindings/rrc_7755_outbox"
+	"github.com/base-org/RRC-7755-poc/internal/client"
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+const (
+	testSourceChainID = 84532
+	testDestChainID   = 421614
+	otherDestChainID  = 11155420
+)
+
+// fakeOutbox reports the statuses set in the map, Requested for any other message, and emits the completed and
+// canceled events once watched
+type fakeOutbox struct {
+	statuses  map[common.Hash]uint8
+	completed [][32]byte
+	canceled  [][32]byte
+}
+
+func (f *fakeOutbox) GetMessageStatus(opts *bind.CallOpts, messageId [32]byte) (uint8, error) {
+	if status, ok := f.statuses[messageId]; ok {
+		return status, nil
+	}
+	return 1, nil
+}
+
+func (f *fakeOutbox) WatchCrossChainCallCompleted(opts *bind.WatchOpts, sink chan<- *rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted, messageId [][32]byte) (event.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, id := range f.completed {
+			sink <- &rrc_7755_outbox.RRC7755OutboxCrossChainCallCompleted{MessageId: id}
+		}
+		<-quit
+		return nil
+	}), nil
+}
+
+func (f *fakeOutbox) WatchCrossChainCallCanceled(opts *bind.WatchOpts, sink chan<- *rrc_7755_outbox.RRC7755OutboxCrossChainCallCanceled, messageId [][32]byte) (event.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, id := range f.canceled {
+			sink <- &rrc_7755_outbox.RRC7755OutboxCrossChainCallCanceled{MessageId: id}
+		}
+		<-quit
+		return nil
+	}), nil
+}
+
+func newTestManager(t *testing.T, cfg config.RiskConfig, outbox *fakeOutbox) *Manager {
+	cfg.Enabled = true
+	m, err := NewManager(&client.Manager{}, &config.Config{Risk: cfg}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { m.Close() })
+
+	m.now = func() time.Time { return time.Unix(1700000000, 0) }
+	m.getChain = func(chainID uint64) (*client.ChainClient, error) {
+		return &client.ChainClient{}, nil
+	}
+	m.bindOutbox = func(address common.Address, chain *client.ChainClient) (Outbox, error) {
+		return outbox, nil
+	}
+	return m
+}
+
+func exposure(id byte, destChain uint64, requester byte, amount int64) *Exposure {
+	return &Exposure{
+		MessageID:        common.Hash{id},
+		SourceChain:      testSourceChainID,
+		DestinationChain: destChain,
+		Requester:        common.Hash{requester},
+		Amount:           big.NewInt(amount),
+	}
+}
+
+func TestManagerLimits(t *testing.T) {
+	m := newTestManager(t, config.RiskConfig{
+		StorePath:        filepath.Join(t.TempDir(), "exposure.jsonl"),
+		MaxRequestValue:  big.NewInt(100),
+		MaxChainExposure: big.NewInt(150),
+		MaxTotalExposure: big.NewInt(200),
+		RequesterLimit:   2,
+		RequesterWindow:  time.Hour,
+	}, &fakeOutbox{})
+
+	require.ErrorIs(t, m.Check(exposure(1, testDestChainID, 1, 101)), ErrLimitExceeded)
+
+	require.NoError(t, m.Reserve(exposure(1, testDestChainID, 1, 100)))
+	err := m.Reserve(exposure(2, testDestChainID, 2, 60))
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	require.ErrorContains(t, err, "chain 421614 exposure")
+
+	require.NoError(t, m.Reserve(exposure(3, otherDestChainID, 1, 100)))
+	err = m.Check(exposure(4, otherDestChainID, 2, 1))
+	require.ErrorContains(t, err, "total exposure")
+
+	// Reserving a request twice doesn't count it twice
+	require.NoError(t, m.Reserve(exposure(1, testDestChainID, 1, 100)))
+
+	m.Release([32]byte{1}, ReleaseClaimed)
+	require.NoError(t, m.Check(exposure(4, testDestChainID, 2, 100)))
+
+	// The requester had 2 requests fulfilled within the hour
+	err = m.Check(exposure(4, testDestChainID, 1, 1))
+	require.ErrorContains(t, err, "already had 2 requests fulfilled")
+
+	m.now = func() time.Time { return time.Unix(1700000000, 0).Add(2 * time.Hour) }
+	require.NoError(t, m.Check(exposure(4, testDestChainID, 1, 1)))
+}
+
+func TestManagerRestoresExposure(t *testing.T) {
+	cfg := config.RiskConfig{
+		StorePath:        filepath.Join(t.TempDir(), "exposure.jsonl"),
+		MaxTotalExposure: big.NewInt(200),
+	}
+	outbox := &fakeOutbox{statuses: map[common.Hash]uint8{{2}: messageStatusCompleted, {4}: messageStatusCanceled}}
+
+	m := newTestManager(t, cfg, outbox)
+	require.NoError(t, m.Reserve(exposure(1, testDestChainID, 1, 100)))
+	require.NoError(t, m.Reserve(exposure(2, testDestChainID, 1, 50)))
+	require.NoError(t, m.Reserve(exposure(3, testDestChainID, 1, 50)))
+	m.Release([32]byte{3}, ReleaseFailed)
+	require.NoError(t, m.Reserve(exposure(4, otherDestChainID, 2, 30)))
+	require.NoError(t, m.Close())
+
+	m = newTestManager(t, cfg, outbox)
+	require.Equal(t, big.NewInt(180), m.totalExposure)
+
+	// Request 2 was claimed and request 4 canceled while stopped
+	m.releaseSettled(context.Background())
+	require.Equal(t, big.NewInt(100), m.totalExposure)
+	require.Contains(t, m.outstanding, common.Hash{1})
+}
+
+func TestManagerWatchesSettlements(t *testing.T) {
+	outbox := &fakeOutbox{completed: [][32]byte{{1}}, canceled: [][32]byte{{2}}}
+	m := newTestManager(t, config.RiskConfig{StorePath: filepath.Join(t.TempDir(), "exposure.jsonl")}, outbox)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	settled := make(chan settlement, completedBufferSize)
+	require.NoError(t, m.watch(ctx, &client.ChainClient{Config: config.ChainConfig{ChainID: testSourceChainID}}, common.Address{}, settled))
+
+	got := []settlement{<-settled, <-settled}
+	require.ElementsMatch(t, []settlement{
+		{messageID: [32]byte{1}, reason: ReleaseClaimed},
+		{messageID: [32]byte{2}, reason: ReleaseCanceled},
+	}, got)
+}
@@ -0,0 +1,170 @@
+package risk
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// Exposure is the capital fronted for a request, outstanding until its reward is claimed
+type Exposure struct {
+	Timestamp        time.Time   `json:"timestamp"`
+	MessageID        common.Hash `json:"message_id"`
+	SourceChain      uint64      `json:"source_chain"`
+	DestinationChain uint64      `json:"destination_chain"`
+	// Outbox is the source chain outbox the request was posted to
+	Outbox    common.Address `json:"outbox"`
+	Requester common.Hash    `json:"requester"`
+	Amount    *big.Int       `json:"amount"`
+}
+
+// Release ends an exposure, Reason is why: the reward was claimed or the fulfillment failed
+type Release struct {
+	Timestamp time.Time   `json:"timestamp"`
+	MessageID common.Hash `json:"message_id"`
+	Reason    string      `json:"reason"`
+}
+
+// record is a line of the exposure file, exactly one of its fields is set
+type record struct {
+	Reserve *Exposure `json:"reserve,omitempty"`
+	Release *Release  `json:"release,omitempty"`
+}
+
+// store appends reservations and releases to a JSONL file, replayed on startup to restore the outstanding exposures
+type store struct {
+	file *os.File
+}
+
+// openStore replays the file at path, creating it if needed, and returns the outstanding exposures. The file is then
+// compacted to the reservations still outstanding, as released ones are no longer needed.
+func openStore(path string, logger *zap.Logger) (*store, map[common.Hash]*Exposure, error) {
+	outstanding, err := replay(path, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := compact(path, outstanding); err != nil {
+		return nil, nil, fmt.Errorf("compacting exposure store: %w", err)
+	}
+
+	out, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening exposure store: %w", err)
+	}
+
+	return &store{file: out}, outstanding, nil
+}
+
+// replay reads the records of the file at path. A last line that can't be decoded was torn by a crash in the middle of
+// its write and is dropped, a bad line followed by others is an error.
+func replay(path string, logger *zap.Logger) (map[common.Hash]*Exposure, error) {
+	outstanding := make(map[common.Hash]*Exposure)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return outstanding, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening exposure store: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		tornLine int
+		tornErr  error
+	)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if tornErr != nil {
+			return nil, fmt.Errorf("decoding exposure store line %d: %w", tornLine, tornErr)
+		}
+
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			tornLine, tornErr = line, err
+			continue
+		}
+		switch {
+		case r.Reserve != nil:
+			outstanding[r.Reserve.MessageID] = r.Reserve
+		case r.Release != nil:
+			delete(outstanding, r.Release.MessageID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading exposure store: %w", err)
+	}
+
+	if tornErr != nil {
+		logger.Warn("Dropping torn last line of the exposure store", zap.Int("line", tornLine), zap.Error(tornErr))
+	}
+	return outstanding, nil
+}
+
+// compact replaces the file at path with the reservations of the outstanding exposures, in the order they were made.
+// The new file is renamed over the old one, so a crash leaves either of them whole.
+func compact(path string, outstanding map[common.Hash]*Exposure) error {
+	exposures := make([]*Exposure, 0, len(outstanding))
+	for _, exposure := range outstanding {
+		exposures = append(exposures, exposure)
+	}
+	sort.Slice(exposures, func(i, j int) bool {
+		if !exposures[i].Timestamp.Equal(exposures[j].Timestamp) {
+			return exposures[i].Timestamp.Before(exposures[j].Timestamp)
+		}
+		return exposures[i].MessageID.Cmp(exposures[j].MessageID) < 0
+	})
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	compacted := &store{file: tmp}
+	for _, exposure := range exposures {
+		if err := compacted.write(&record{Reserve: exposure}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *store) write(r *record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding exposure record: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing exposure record: %w", err)
+	}
+	return nil
+}
+
+func (s *store) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,92 @@
+package risk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func storeLines(t *testing.T, records ...*record) string {
+	var lines []string
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		require.NoError(t, err)
+		lines = append(lines, string(line))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestOpenStoreCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exposure.jsonl")
+
+	first, second, third := exposure(1, testDestChainID, 1, 10), exposure(2, testDestChainID, 1, 20), exposure(3, testDestChainID, 2, 30)
+	first.Timestamp = time.Unix(1700000000, 0).UTC()
+	second.Timestamp = first.Timestamp.Add(time.Minute)
+	third.Timestamp = first.Timestamp.Add(2 * time.Minute)
+
+	// The last reservation was torn by a crash while it was written
+	content := storeLines(t,
+		&record{Reserve: third},
+		&record{Reserve: first},
+		&record{Reserve: second},
+		&record{Release: &Release{MessageID: second.MessageID, Reason: ReleaseClaimed}},
+	) + `{"reserve":{"timestamp":"2023-11-14T22:`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	s, outstanding, err := openStore(path, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.Len(t, outstanding, 2)
+	require.Contains(t, outstanding, first.MessageID)
+	require.Contains(t, outstanding, third.MessageID)
+
+	// Only the outstanding reservations are kept, in the order they were made, and records are appended after them
+	fourth := exposure(4, testDestChainID, 2, 40)
+	require.NoError(t, s.write(&record{Reserve: fourth}))
+	require.NoError(t, s.Close())
+
+	compacted, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, storeLines(t, &record{Reserve: first}, &record{Reserve: third}, &record{Reserve: fourth}), string(compacted))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestOpenStoreCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exposure.jsonl")
+
+	// A bad line followed by others is not a torn write
+	content := storeLines(t, &record{Reserve: exposure(1, testDestChainID, 1, 10)}) +
+		"{\"reserve\":\n" +
+		storeLines(t, &record{Reserve: exposure(2, testDestChainID, 1, 20)})
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	_, _, err := openStore(path, zaptest.NewLogger(t))
+	require.ErrorContains(t, err, "decoding exposure store line 2")
+
+	// The file is left untouched
+	kept, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, content, string(kept))
+}
+
+func TestOpenStoreCreates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exposure.jsonl")
+
+	s, outstanding, err := openStore(path, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.Empty(t, outstanding)
+	require.NoError(t, s.Close())
+	require.FileExists(t, path)
+}
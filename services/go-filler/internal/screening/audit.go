@@ -0,0 +1,57 @@
+package screening
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AuditRecord is a line of the audit log, one per screened address of a request
+type AuditRecord struct {
+	Timestamp time.Time   `json:"timestamp"`
+	MessageID common.Hash `json:"message_id"`
+	Screener  string      `json:"screener"`
+	Role      string      `json:"role"`
+	Address   string      `json:"address"`
+	Blocked   bool        `json:"blocked"`
+	Reason    string      `json:"reason,omitempty"`
+	// Error is set when the screener failed, the address is then blocked
+	Error string `json:"error,omitempty"`
+}
+
+// auditLog appends AuditRecords to a JSONL file
+type auditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening screening audit log: %w", err)
+	}
+	return &auditLog{file: file}, nil
+}
+
+func (a *auditLog) write(r *AuditRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding screening audit record: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing screening audit record: %w", err)
+	}
+	return nil
+}
+
+func (a *auditLog) Close() error {
+	return a.file.Close()
+}
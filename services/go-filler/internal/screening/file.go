@@ -0,0 +1,140 @@
+package screening
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// FileList blocks the addresses listed in a file: one address or bytes32 value per line, with blank lines and lines
+// starting with # ignored. The file is reloaded when its modification time changes.
+type FileList struct {
+	path           string
+	reloadInterval time.Duration
+	logger         *zap.Logger
+
+	mu      sync.RWMutex
+	blocked map[common.Hash]struct{}
+	modTime time.Time
+}
+
+func NewFileList(cfg config.FileScreeningConfig, logger *zap.Logger) (*FileList, error) {
+	reloadInterval := cfg.ReloadInterval
+	if reloadInterval == 0 {
+		reloadInterval = defaultReloadInterval
+	}
+
+	l := &FileList{path: cfg.Path, reloadInterval: reloadInterval, logger: logger}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *FileList) Name() string {
+	return config.ScreeningFile
+}
+
+func (l *FileList) Screen(_ context.Context, subjects []Subject) ([]Decision, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	decisions := make([]Decision, len(subjects))
+	for i, subject := range subjects {
+		decisions[i] = Decision{Subject: subject}
+		if _, ok := l.blocked[subject.Address]; ok {
+			decisions[i].Blocked = true
+			decisions[i].Reason = "listed in " + l.path
+		}
+	}
+	return decisions, nil
+}
+
+// Reload reads the file again, the current list is kept when it is invalid
+func (l *FileList) Reload() error {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return fmt.Errorf("reading screening list: %w", err)
+	}
+
+	blocked, err := readList(l.path)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.blocked = blocked
+	l.modTime = info.ModTime()
+	l.mu.Unlock()
+
+	l.logger.Info("Loaded screening list", zap.String("path", l.path), zap.Int("addresses", len(blocked)))
+	return nil
+}
+
+// Run reloads the file every reload interval in which it was modified
+func (l *FileList) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(l.path)
+			if err != nil {
+				l.logger.Error("Checking screening list", zap.Error(err))
+				continue
+			}
+
+			l.mu.RLock()
+			modified := !info.ModTime().Equal(l.modTime)
+			l.mu.RUnlock()
+
+			if modified {
+				if err := l.Reload(); err != nil {
+					l.logger.Error("Reloading screening list", zap.Error(err))
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func readList(path string) (map[common.Hash]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading screening list: %w", err)
+	}
+	defer file.Close()
+
+	blocked := make(map[common.Hash]struct{})
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		value, err := hexutil.Decode(entry)
+		if err != nil || (len(value) != common.AddressLength && len(value) != common.HashLength) {
+			return nil, fmt.Errorf("screening list line %d: invalid entry %q", line, entry)
+		}
+		blocked[common.BytesToHash(value)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading screening list: %w", err)
+	}
+
+	return blocked, nil
+}
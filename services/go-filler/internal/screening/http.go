@@ -0,0 +1,116 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+const (
+	defaultHTTPTimeout = 5 * time.Second
+	maxErrorBodySize   = 512
+)
+
+// HTTPScreener asks a compliance service about the addresses of a request in a single call. The service receives
+//
+//	{"addresses": [{"address": "0x...", "role": "requester"}, ...]}
+//
+// and answers with a result for every address:
+//
+//	{"results": [{"address": "0x...", "blocked": true, "reason": "..."}, ...]}
+type HTTPScreener struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+type screenRequest struct {
+	Addresses []screenAddress `json:"addresses"`
+}
+
+type screenAddress struct {
+	Address string `json:"address"`
+	Role    string `json:"role"`
+}
+
+type screenResponse struct {
+	Results []screenResult `json:"results"`
+}
+
+type screenResult struct {
+	Address string `json:"address"`
+	Blocked bool   `json:"blocked"`
+	Reason  string `json:"reason"`
+}
+
+func NewHTTPScreener(cfg config.HTTPScreeningConfig) *HTTPScreener {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	return &HTTPScreener{url: cfg.URL, apiKey: cfg.APIKey, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPScreener) Name() string {
+	return config.ScreeningHTTP
+}
+
+func (s *HTTPScreener) Screen(ctx context.Context, subjects []Subject) ([]Decision, error) {
+	body := screenRequest{Addresses: make([]screenAddress, len(subjects))}
+	for i, subject := range subjects {
+		body.Addresses[i] = screenAddress{Address: subject.String(), Role: subject.Role}
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding screening request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("creating screening request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending screening request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, fmt.Errorf("screening service returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var decoded screenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decoding screening response: %w", err)
+	}
+
+	results := make(map[string]screenResult, len(decoded.Results))
+	for _, result := range decoded.Results {
+		results[strings.ToLower(result.Address)] = result
+	}
+
+	decisions := make([]Decision, len(subjects))
+	for i, subject := range subjects {
+		result, ok := results[strings.ToLower(subject.String())]
+		if !ok {
+			return nil, fmt.Errorf("screening response has no result for %s", subject)
+		}
+		decisions[i] = Decision{Subject: subject, Blocked: result.Blocked, Reason: result.Reason}
+	}
+	return decisions, nil
+}
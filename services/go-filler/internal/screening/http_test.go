@@ -0,0 +1,79 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+func TestHTTPScreener(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantBlocked []bool
+		wantErr     string
+	}{
+		{
+			name: "blocked target",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+				var req screenRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				require.Equal(t, []screenAddress{
+					{Address: requester.String(), Role: RoleRequester},
+					{Address: target.String(), Role: RoleCallTarget},
+				}, req.Addresses)
+
+				// Results may come in any order and case
+				_ = json.NewEncoder(w).Encode(screenResponse{Results: []screenResult{
+					{Address: strings.ToLower(target.String()), Blocked: true, Reason: "sanctioned"},
+					{Address: requester.String()},
+				}})
+			},
+			wantBlocked: []bool{false, true},
+		},
+		{
+			name: "missing result",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_ = json.NewEncoder(w).Encode(screenResponse{Results: []screenResult{{Address: requester.String()}}})
+			},
+			wantErr: "screening response has no result for " + target.String(),
+		},
+		{
+			name: "service error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "rate limited", http.StatusTooManyRequests)
+			},
+			wantErr: "screening service returned 429 Too Many Requests: rate limited",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			screener := NewHTTPScreener(config.HTTPScreeningConfig{URL: server.URL, APIKey: "secret"})
+			decisions, err := screener.Screen(context.Background(), []Subject{requester, target})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, decisions, len(tt.wantBlocked))
+			for i, blocked := range tt.wantBlocked {
+				require.Equal(t, blocked, decisions[i].Blocked)
+			}
+			require.Equal(t, "sanctioned", decisions[1].Reason)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// Roles of the screened addresses in a request
+const (
+	RoleRequester    = "requester"
+	RoleUserOpSender = "user-op-sender"
+	RoleCallTarget   = "call-target"
+	RoleRewardAsset  = "reward-asset"
+	RoleRewardPayout = "reward-payout"
+)
+
+// ErrBlocked is returned for requests with an address the screener blocked
+var ErrBlocked = errors.New("blocked by screening")
+
+// Subject is an address of a request, left padded to 32 bytes like requesters and call targets
+type Subject struct {
+	Role    string
+	Address common.Hash
+}
+
+// String returns the address as a 20 byte address when it fits in one
+func (s Subject) String() string {
+	if address := common.BytesToAddress(s.Address[:]); common.BytesToHash(address.Bytes()) == s.Address {
+		return address.Hex()
+	}
+	return s.Address.Hex()
+}
+
+// Decision is the verdict of a screener on a subject
+type Decision struct {
+	Subject Subject
+	Blocked bool
+	Reason  string
+}
+
+// Screener decides which addresses are blocked, it returns a decision for every subject
+type Screener interface {
+	Name() string
+	Screen(ctx context.Context, subjects []Subject) ([]Decision, error)
+}
+
+// reloader is implemented by screeners backed by data that can change at runtime
+type reloader interface {
+	Reload() error
+	Run(ctx context.Context) error
+}
+
+// Service screens the addresses of requests and writes every decision to the audit log. Requests are blocked when the
+// screener fails, screening fails closed.
+type Service struct {
+	logger   *zap.Logger
+	screener Screener
+	audit    *auditLog
+
+	now func() time.Time
+}
+
+func NewService(cfg config.ScreeningConfig, logger *zap.Logger) (*Service, error) {
+	var (
+		screener Screener
+		err      error
+	)
+	switch cfg.Type {
+	case config.ScreeningFile:
+		screener, err = NewFileList(cfg.File, logger)
+	case config.ScreeningHTTP:
+		screener = NewHTTPScreener(cfg.HTTP)
+	default:
+		err = fmt.Errorf("unknown screening type %q", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newService(screener, cfg.AuditPath, logger)
+}
+
+func newService(screener Screener, auditPath string, logger *zap.Logger) (*Service, error) {
+	audit, err := openAuditLog(auditPath)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Screening enabled", zap.String("screener", screener.Name()), zap.String("audit_path", auditPath))
+
+	return &Service{logger: logger, screener: screener, audit: audit, now: time.Now}, nil
+}
+
+func (s *Service) ServiceName() string {
+	return "Screening"
+}
+
+// Run keeps the screener data up to date, it returns right away for screeners without data to reload
+func (s *Service) Run(ctx context.Context) error {
+	if r, ok := s.screener.(reloader); ok {
+		return r.Run(ctx)
+	}
+	return nil
+}
+
+// Reload reloads the screener data, if any
+func (s *Service) Reload() error {
+	if r, ok := s.screener.(reloader); ok {
+		return r.Reload()
+	}
+	return nil
+}
+
+// Close releases the audit log
+func (s *Service) Close() error {
+	return s.audit.Close()
+}
+
+// Screen screens the subjects of a request and returns an ErrBlocked error naming the blocked ones
+func (s *Service) Screen(ctx context.Context, messageID [32]byte, subjects []Subject) error {
+	subjects = dedupe(subjects)
+	timestamp := s.now().UTC()
+
+	decisions, err := s.screener.Screen(ctx, subjects)
+	if err == nil && len(decisions) != len(subjects) {
+		err = fmt.Errorf("got %d decisions for %d addresses", len(decisions), len(subjects))
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %s failed: %w", ErrBlocked, s.screener.Name(), err)
+		for _, subject := range subjects {
+			s.write(&AuditRecord{
+				Timestamp: timestamp,
+				MessageID: messageID,
+				Screener:  s.screener.Name(),
+				Role:      subject.Role,
+				Address:   subject.String(),
+				Blocked:   true,
+				Error:     err.Error(),
+			})
+		}
+		return err
+	}
+
+	var blocked []string
+	for _, decision := range decisions {
+		s.write(&AuditRecord{
+			Timestamp: timestamp,
+			MessageID: messageID,
+			Screener:  s.screener.Name(),
+			Role:      decision.Subject.Role,
+			Address:   decision.Subject.String(),
+			Blocked:   decision.Blocked,
+			Reason:    decision.Reason,
+		})
+
+		if decision.Blocked {
+			s.logger.Warn("Screening blocked address",
+				zap.String("message_id", common.Hash(messageID).Hex()),
+				zap.String("role", decision.Subject.Role),
+				zap.Stringer("address", decision.Subject),
+				zap.String("reason", decision.Reason),
+			)
+			blocked = append(blocked, fmt.Sprintf("%s %s", decision.Subject.Role, decision.Subject))
+		}
+	}
+
+	if len(blocked) > 0 {
+		return fmt.Errorf("%w: %s", ErrBlocked, strings.Join(blocked, ", "))
+	}
+	return nil
+}
+
+func (s *Service) write(r *AuditRecord) {
+	if err := s.audit.write(r); err != nil {
+		s.logger.Error("Writing screening audit record", zap.Error(err))
+	}
+}
+
+// dedupe drops repeated subjects, such as a target called several times
+func dedupe(subjects []Subject) []Subject {
+	seen := make(map[Subject]struct{}, len(subjects))
+	out := make([]Subject, 0, len(subjects))
+	for _, subject := range subjects {
+		if _, ok := seen[subject]; ok {
+			continue
+		}
+		seen[subject] = struct{}{}
+		out = append(out, subject)
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package screening
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/base-org/RRC-7755-poc/internal/config"
+)
+
+var (
+	testMessageID = common.HexToHash("0x01")
+	requester     = Subject{Role: RoleRequester, Address: common.BytesToHash(common.HexToAddress("0x8C1a617BdB47342F9C17Ac8750E0b070c372C721").Bytes())}
+	target        = Subject{Role: RoleCallTarget, Address: common.BytesToHash(common.HexToAddress("0x722122dF12D4e14e13Ac3b6895a86e84145b6967").Bytes())}
+)
+
+type failingScreener struct{}
+
+func (failingScreener) Name() string { return "failing" }
+
+func (failingScreener) Screen(context.Context, []Subject) ([]Decision, error) {
+	return nil, errors.New("service unavailable")
+}
+
+func readAudit(t *testing.T, path string) []AuditRecord {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestSubjectString(t *testing.T) {
+	require.Equal(t, "0x8C1a617BdB47342F9C17Ac8750E0b070c372C721", requester.String())
+
+	bytes32 := Subject{Address: common.HexToHash("0x0100000000000000000000008c1a617bdb47342f9c17ac8750e0b070c372c721")}
+	require.Equal(t, "0x0100000000000000000000008c1a617bdb47342f9c17ac8750e0b070c372c721", bytes32.String())
+}
+
+func TestServiceScreen(t *testing.T) {
+	dir := t.TempDir()
+	listPath := filepath.Join(dir, "blocklist.txt")
+	auditPath := filepath.Join(dir, "audit.jsonl")
+	require.NoError(t, os.WriteFile(listPath, []byte("# sanctioned\n\n"+target.String()+"\n"), 0o644))
+
+	service, err := NewService(config.ScreeningConfig{
+		Type:      config.ScreeningFile,
+		AuditPath: auditPath,
+		File:      config.FileScreeningConfig{Path: listPath},
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer service.Close()
+
+	require.NoError(t, service.Screen(context.Background(), testMessageID, []Subject{requester}))
+
+	err = service.Screen(context.Background(), testMessageID, []Subject{requester, target, target})
+	require.ErrorIs(t, err, ErrBlocked)
+	require.ErrorContains(t, err, "call-target "+target.String())
+
+	records := readAudit(t, auditPath)
+	require.Len(t, records, 3)
+	require.False(t, records[0].Blocked)
+	require.Equal(t, RoleRequester, records[1].Role)
+	require.False(t, records[1].Blocked)
+	require.Equal(t, RoleCallTarget, records[2].Role)
+	require.True(t, records[2].Blocked)
+	require.Equal(t, "listed in "+listPath, records[2].Reason)
+	require.Equal(t, testMessageID, records[2].MessageID)
+}
+
+func TestServiceFailsClosed(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	service, err := newService(failingScreener{}, auditPath, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer service.Close()
+
+	err = service.Screen(context.Background(), testMessageID, []Subject{requester, target})
+	require.ErrorIs(t, err, ErrBlocked)
+	require.ErrorContains(t, err, "service unavailable")
+
+	records := readAudit(t, auditPath)
+	require.Len(t, records, 2)
+	for _, r := range records {
+		require.True(t, r.Blocked)
+		require.Contains(t, r.Error, "service unavailable")
+	}
+}
+
+func TestFileListReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+
+	list, err := NewFileList(config.FileScreeningConfig{Path: path, ReloadInterval: 10 * time.Millisecond}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	blocked := func() bool {
+		decisions, err := list.Screen(context.Background(), []Subject{requester})
+		require.NoError(t, err)
+		return decisions[0].Blocked
+	}
+	require.False(t, blocked())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go list.Run(ctx) //nolint:errcheck
+
+	// The modification time is moved forward as the file may be rewritten within the file system time resolution
+	require.NoError(t, os.WriteFile(path, []byte(requester.Address.Hex()+"\n"), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	require.Eventually(t, blocked, time.Second, 10*time.Millisecond)
+
+	// An invalid list is rejected and the current one kept
+	require.NoError(t, os.WriteFile(path, []byte("0x1234\n"), 0o644))
+	require.ErrorContains(t, list.Reload(), `line 1: invalid entry "0x1234"`)
+	require.True(t, blocked())
+}